@@ -5,6 +5,7 @@ import (
 	"flag"
 	"log"
 	"ncogearthchain-api-graphql/cmd/apiserver/build"
+	"ncogearthchain-api-graphql/internal/broker"
 	"ncogearthchain-api-graphql/internal/config"
 	"ncogearthchain-api-graphql/internal/graphql/resolvers"
 	"ncogearthchain-api-graphql/internal/handlers"
@@ -46,6 +47,8 @@ func (app *apiServer) init() {
 	// make sure to pass logger and config to internals
 	repository.SetConfig(app.cfg)
 	repository.SetLogger(app.log)
+	broker.SetConfig(app.cfg)
+	broker.SetLogger(app.log)
 	resolvers.SetConfig(app.cfg)
 	resolvers.SetLogger(app.log)
 	svc.SetConfig(app.cfg)
@@ -109,12 +112,13 @@ func (app *apiServer) setupHandlers(mux *http.ServeMux) {
 	// Unified GraphQL API handler (both HTTP and WebSocket)
 	graphqlApiHandler := handlers.Api(app.cfg, app.log, app.api)
 
-	// HTTP POST GraphQL queries/mutations
-	httpHandler := http.TimeoutHandler(
+	// HTTP POST GraphQL queries/mutations; the server-sent events streams
+	// of subscriptions are exempt from the resolver timeout
+	httpHandler := handlers.ExemptStreams(http.TimeoutHandler(
 		graphqlApiHandler,
 		time.Second*time.Duration(app.cfg.Server.ResolverTimeout),
 		"Service timeout.",
-	)
+	), graphqlApiHandler)
 
 	mux.Handle("/api", httpHandler)
 	mux.Handle("/graphql", httpHandler)
@@ -124,6 +128,10 @@ func (app *apiServer) setupHandlers(mux *http.ServeMux) {
 
 	// setup gas price estimator REST API resolver
 	mux.Handle("/json/gas", handlers.GasPrice(app.log))
+	mux.Handle("/json/gas/stream", handlers.GasPriceStream(app.cfg, app.log))
+
+	// subscriptions broker counters
+	mux.Handle("/json/broker", handlers.BrokerStats(app.log))
 
 	// handle GraphiQL interface
 	mux.Handle("/graphi", handlers.GraphiHandler(app.cfg.Server.DomainAddress, app.log))
@@ -8,8 +8,8 @@ require (
 	github.com/allegro/bigcache v1.2.1
 	github.com/cloudflare/circl v1.5.0
 	github.com/ethereum/go-ethereum v1.10.17
+	github.com/gorilla/websocket v1.5.0
 	github.com/graph-gophers/graphql-go v1.4.0
-	github.com/graph-gophers/graphql-transport-ws v0.0.2
 	github.com/klauspost/compress v1.15.1
 	github.com/mitchellh/mapstructure v1.4.3
 	github.com/onsi/gomega v1.14.0
@@ -36,7 +36,6 @@ require (
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/google/uuid v1.3.0 // indirect
-	github.com/gorilla/websocket v1.5.0 // indirect
 	github.com/hashicorp/golang-lru v0.5.5-0.20210104140557-80c98217689d // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/holiman/bloomfilter/v2 v2.0.3 // indirect
@@ -0,0 +1,283 @@
+/*
+Package broker implements topic based fan-out of events produced by the blockchain
+data processing services to API subscribers.
+
+Each subscriber receives events through its own bounded queue, so publishers are never
+blocked by slow consumers. A subscriber which can not keep up either loses the events
+which do not fit into its queue, or gets disconnected, depending on the configured policy.
+Subscriptions made on behalf of a client connection are limited per connection.
+Delivered and dropped events are counted per topic and in total.
+*/
+package broker
+
+import (
+	"context"
+	"errors"
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/logger"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy represents the way the broker handles subscribers which can not keep up with the events.
+type Policy int
+
+const (
+	// PolicyDrop skips events which do not fit into the subscriber queue.
+	PolicyDrop Policy = iota
+
+	// PolicyDisconnect terminates the subscription of a subscriber whose queue is full.
+	PolicyDisconnect
+)
+
+const (
+	// policyDropName is the configuration name of the drop policy.
+	policyDropName = "drop"
+
+	// policyDisconnectName is the configuration name of the disconnect policy.
+	policyDisconnectName = "disconnect"
+
+	// minQueueSize is the smallest subscriber queue the broker accepts.
+	minQueueSize = 1
+)
+
+// ErrTooManySubscriptions is returned if a connection reached the max number of subscriptions.
+var ErrTooManySubscriptions = errors.New("too many subscriptions on this connection")
+
+// ErrUnknownPolicy is returned if the configured policy is not recognized.
+var ErrUnknownPolicy = errors.New("unknown subscription policy")
+
+// Broker represents the topic keyed events broker.
+type Broker struct {
+	mu     sync.RWMutex
+	seq    uint64
+	topics map[string]*topic
+
+	// subscription defaults
+	queueSize        int
+	policy           Policy
+	maxPerConnection int
+
+	// total counters
+	delivered    atomic.Uint64
+	dropped      atomic.Uint64
+	disconnected atomic.Uint64
+}
+
+// topic represents the subscribers of a single topic and its counters.
+type topic struct {
+	subs      map[uint64]*Subscription
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// bus represents the singleton instance of the broker.
+var bus *Broker
+
+// onceBus is the sync object used to make sure the broker
+// is instantiated only once on the first demand.
+var onceBus sync.Once
+
+// cfg represents the configuration setup used by the broker.
+var cfg *config.Config
+
+// log represents the logger to be used by the broker.
+var log logger.Logger
+
+// SetConfig sets the broker configuration.
+func SetConfig(c *config.Config) {
+	cfg = c
+}
+
+// SetLogger sets the broker logger to be used to collect logging info.
+func SetLogger(l logger.Logger) {
+	log = l
+}
+
+// B provides access to the singleton instance of the broker.
+func B() *Broker {
+	onceBus.Do(func() {
+		bus = newBroker()
+	})
+	return bus
+}
+
+// newBroker creates a new broker instance configured by the API server configuration.
+func newBroker() *Broker {
+	policy, err := ParsePolicy(cfg.Subscriptions.Policy)
+	if err != nil {
+		log.Errorf("subscriptions policy %s not valid, dropping events instead; %s", cfg.Subscriptions.Policy, err.Error())
+	}
+	return New(cfg.Subscriptions.QueueSize, policy, cfg.Subscriptions.MaxPerConnection)
+}
+
+// New creates a new broker with the given subscriber queue size, slow subscriber policy
+// and the max number of subscriptions per connection; zero means no limit.
+func New(queueSize int, policy Policy, maxPerConnection int) *Broker {
+	if queueSize < minQueueSize {
+		queueSize = minQueueSize
+	}
+	return &Broker{
+		topics:           make(map[string]*topic),
+		queueSize:        queueSize,
+		policy:           policy,
+		maxPerConnection: maxPerConnection,
+	}
+}
+
+// Filter represents a subscriber predicate deciding which events of the topic the subscriber receives.
+// The filter is evaluated by the publisher, so it must be cheap and must not block.
+type Filter func(evt interface{}) bool
+
+// ParsePolicy decodes the configuration name of a slow subscriber policy.
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case policyDropName, "":
+		return PolicyDrop, nil
+	case policyDisconnectName:
+		return PolicyDisconnect, nil
+	}
+	return PolicyDrop, ErrUnknownPolicy
+}
+
+// String returns the configuration name of the policy.
+func (p Policy) String() string {
+	if p == PolicyDisconnect {
+		return policyDisconnectName
+	}
+	return policyDropName
+}
+
+// Subscribe adds a new subscriber of the topic with the default policy of the broker.
+// If the context carries a client connection, the subscription is counted against the connection
+// limit and it's terminated once the context is done. The caller is expected to close
+// the subscription when it's not needed anymore.
+func (b *Broker) Subscribe(ctx context.Context, topic string) (*Subscription, error) {
+	return b.SubscribeWithPolicy(ctx, topic, b.policy)
+}
+
+// SubscribeWithPolicy adds a new subscriber of the topic with the given slow subscriber policy.
+func (b *Broker) SubscribeWithPolicy(ctx context.Context, name string, policy Policy) (*Subscription, error) {
+	return b.subscribe(ctx, name, policy, nil)
+}
+
+// SubscribeFiltered adds a new subscriber of the topic with the default policy of the broker.
+// Only the events accepted by the filter are queued for the subscriber; the other events
+// are skipped at publish time and they do not occupy the subscriber queue.
+func (b *Broker) SubscribeFiltered(ctx context.Context, name string, filter Filter) (*Subscription, error) {
+	return b.subscribe(ctx, name, b.policy, filter)
+}
+
+// SubscribeFilteredWithPolicy adds a new subscriber of the topic with the given slow subscriber
+// policy, only the events accepted by the filter are queued for the subscriber.
+func (b *Broker) SubscribeFilteredWithPolicy(ctx context.Context, name string, policy Policy, filter Filter) (*Subscription, error) {
+	return b.subscribe(ctx, name, policy, filter)
+}
+
+// subscribe adds a new subscriber of the topic with the given policy and an optional events filter.
+func (b *Broker) subscribe(ctx context.Context, name string, policy Policy, filter Filter) (*Subscription, error) {
+	conn := connectionOf(ctx)
+	if conn != nil && !conn.acquire(b.maxPerConnection) {
+		return nil, ErrTooManySubscriptions
+	}
+
+	b.mu.Lock()
+	b.seq++
+	sub := &Subscription{
+		id:     b.seq,
+		topic:  name,
+		policy: policy,
+		filter: filter,
+		events: make(chan interface{}, b.queueSize),
+		broker: b,
+		conn:   conn,
+	}
+
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{subs: make(map[uint64]*Subscription)}
+		b.topics[name] = t
+	}
+	t.subs[sub.id] = sub
+	b.mu.Unlock()
+
+	// terminate the subscription with the context
+	if ctx.Done() != nil {
+		go func() {
+			<-ctx.Done()
+			sub.Close()
+		}()
+	}
+	return sub, nil
+}
+
+// HasSubscribers checks if there is any subscriber of the topic,
+// so the publisher can skip preparing events nobody will receive.
+func (b *Broker) HasSubscribers(name string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	t, ok := b.topics[name]
+	return ok && len(t.subs) > 0
+}
+
+// Publish sends the event to all the subscribers of the topic accepting it. The call never blocks;
+// subscribers with full queue lose the event, or get disconnected by the policy.
+func (b *Broker) Publish(name string, evt interface{}) {
+	var slow []*Subscription
+
+	b.mu.RLock()
+	t, ok := b.topics[name]
+	if ok {
+		for _, sub := range t.subs {
+			if sub.filter != nil && !sub.filter(evt) {
+				continue
+			}
+
+			select {
+			case sub.events <- evt:
+				sub.delivered.Add(1)
+				t.delivered.Add(1)
+				b.delivered.Add(1)
+			default:
+				sub.dropped.Add(1)
+				t.dropped.Add(1)
+				b.dropped.Add(1)
+
+				if sub.policy == PolicyDisconnect {
+					slow = append(slow, sub)
+				}
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	// disconnect slow subscribers outside of the read lock
+	for _, sub := range slow {
+		if sub.terminate() {
+			b.disconnected.Add(1)
+			log.Debugf("subscriber #%d of %s can not keep up, disconnected", sub.id, name)
+		}
+	}
+}
+
+// remove detaches the subscriber from its topic and closes its queue.
+// The queue is closed under the write lock, so no publisher can send into it anymore.
+func (b *Broker) remove(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.topics[sub.topic]
+	if !ok {
+		return
+	}
+	if _, ok := t.subs[sub.id]; !ok {
+		return
+	}
+
+	close(sub.events)
+	delete(t.subs, sub.id)
+	if len(t.subs) == 0 {
+		delete(b.topics, sub.topic)
+	}
+}
@@ -0,0 +1,290 @@
+package broker
+
+import (
+	"context"
+	"testing"
+
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/onsi/gomega"
+)
+
+func init() {
+	log = logger.New(&config.Config{Log: config.Log{Level: "CRITICAL", Format: "%{message}"}})
+}
+
+// drain collects the events waiting in the subscription queue.
+func drain(sub *Subscription) []interface{} {
+	list := make([]interface{}, 0)
+	for {
+		select {
+		case evt, ok := <-sub.Events():
+			if !ok {
+				return list
+			}
+			list = append(list, evt)
+		default:
+			return list
+		}
+	}
+}
+
+func TestBrokerFanOut(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	b := New(10, PolicyDrop, 0)
+
+	s1, err := b.Subscribe(context.Background(), "a")
+	g.Expect(err).To(gomega.BeNil())
+	s2, err := b.Subscribe(context.Background(), "a")
+	g.Expect(err).To(gomega.BeNil())
+	s3, err := b.Subscribe(context.Background(), "b")
+	g.Expect(err).To(gomega.BeNil())
+
+	g.Expect(b.HasSubscribers("a")).To(gomega.BeTrue())
+	g.Expect(b.HasSubscribers("c")).To(gomega.BeFalse())
+
+	// every subscriber of the topic receives the events in order
+	b.Publish("a", 1)
+	b.Publish("a", 2)
+	b.Publish("b", 3)
+	b.Publish("c", 4)
+
+	g.Expect(drain(s1)).To(gomega.Equal([]interface{}{1, 2}))
+	g.Expect(drain(s2)).To(gomega.Equal([]interface{}{1, 2}))
+	g.Expect(drain(s3)).To(gomega.Equal([]interface{}{3}))
+
+	// closed subscribers are detached, the topic is removed with the last one
+	s1.Close()
+	s1.Close()
+	b.Publish("a", 5)
+	g.Expect(drain(s2)).To(gomega.Equal([]interface{}{5}))
+
+	_, ok := <-s1.Events()
+	g.Expect(ok).To(gomega.BeFalse())
+
+	s2.Close()
+	g.Expect(b.HasSubscribers("a")).To(gomega.BeFalse())
+	g.Expect(b.Stats().Topics).ToNot(gomega.HaveKey("a"))
+}
+
+func TestBrokerFilter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	b := New(2, PolicyDisconnect, 0)
+
+	even, err := b.SubscribeFiltered(context.Background(), "a", func(evt interface{}) bool {
+		return evt.(int)%2 == 0
+	})
+	g.Expect(err).To(gomega.BeNil())
+
+	// rejected events neither occupy the queue, nor count as dropped
+	for i := 1; i <= 4; i++ {
+		b.Publish("a", i)
+	}
+	g.Expect(drain(even)).To(gomega.Equal([]interface{}{2, 4}))
+	g.Expect(even.Delivered()).To(gomega.Equal(uint64(2)))
+	g.Expect(even.Dropped()).To(gomega.BeZero())
+	g.Expect(even.Disconnected()).To(gomega.BeFalse())
+
+	// the policy applies to the accepted events only
+	odd, err := b.SubscribeFilteredWithPolicy(context.Background(), "b", PolicyDrop, func(evt interface{}) bool {
+		return evt.(int)%2 == 1
+	})
+	g.Expect(err).To(gomega.BeNil())
+
+	for i := 1; i <= 8; i++ {
+		b.Publish("b", i)
+	}
+	g.Expect(drain(odd)).To(gomega.Equal([]interface{}{1, 3}))
+	g.Expect(odd.Dropped()).To(gomega.Equal(uint64(2)))
+	g.Expect(odd.Disconnected()).To(gomega.BeFalse())
+}
+
+func TestBrokerDropPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	b := New(2, PolicyDrop, 0)
+
+	sub, err := b.Subscribe(context.Background(), "a")
+	g.Expect(err).To(gomega.BeNil())
+
+	// events not fitting into the queue are lost, the subscriber stays
+	for i := 1; i <= 5; i++ {
+		b.Publish("a", i)
+	}
+	g.Expect(sub.Disconnected()).To(gomega.BeFalse())
+	g.Expect(drain(sub)).To(gomega.Equal([]interface{}{1, 2}))
+
+	b.Publish("a", 6)
+	g.Expect(drain(sub)).To(gomega.Equal([]interface{}{6}))
+
+	g.Expect(sub.Delivered()).To(gomega.Equal(uint64(3)))
+	g.Expect(sub.Dropped()).To(gomega.Equal(uint64(3)))
+	g.Expect(b.HasSubscribers("a")).To(gomega.BeTrue())
+}
+
+func TestBrokerDisconnectPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	b := New(2, PolicyDrop, 0)
+
+	slow, err := b.SubscribeWithPolicy(context.Background(), "a", PolicyDisconnect)
+	g.Expect(err).To(gomega.BeNil())
+	fast, err := b.Subscribe(context.Background(), "a")
+	g.Expect(err).To(gomega.BeNil())
+
+	// the slow subscriber is terminated on the first event not fitting its queue
+	for i := 1; i <= 3; i++ {
+		b.Publish("a", i)
+	}
+	g.Expect(slow.Disconnected()).To(gomega.BeTrue())
+	g.Expect(drain(slow)).To(gomega.Equal([]interface{}{1, 2}))
+
+	_, ok := <-slow.Events()
+	g.Expect(ok).To(gomega.BeFalse())
+
+	// the other subscribers are not affected
+	g.Expect(fast.Disconnected()).To(gomega.BeFalse())
+	g.Expect(drain(fast)).To(gomega.Equal([]interface{}{1, 2}))
+	b.Publish("a", 4)
+	g.Expect(drain(fast)).To(gomega.Equal([]interface{}{4}))
+	g.Expect(b.Stats().Topics["a"].Subscribers).To(gomega.Equal(1))
+
+	// closing a disconnected subscription is safe
+	slow.Close()
+	g.Expect(b.Stats().Disconnected).To(gomega.Equal(uint64(1)))
+}
+
+func TestBrokerConnectionLimit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	b := New(2, PolicyDrop, 2)
+
+	ctx, cancel := context.WithCancel(WithConnection(context.Background()))
+	s1, err := b.Subscribe(ctx, "a")
+	g.Expect(err).To(gomega.BeNil())
+	_, err = b.Subscribe(ctx, "b")
+	g.Expect(err).To(gomega.BeNil())
+
+	// the connection is full
+	_, err = b.Subscribe(ctx, "c")
+	g.Expect(err).To(gomega.Equal(ErrTooManySubscriptions))
+
+	// other connections and subscriptions without a connection are not limited
+	_, err = b.Subscribe(WithConnection(context.Background()), "c")
+	g.Expect(err).To(gomega.BeNil())
+	for i := 0; i < 5; i++ {
+		_, err = b.Subscribe(context.Background(), "c")
+		g.Expect(err).To(gomega.BeNil())
+	}
+
+	// closing a subscription releases its slot, even if closed repeatedly
+	s1.Close()
+	s1.Close()
+	s3, err := b.Subscribe(ctx, "c")
+	g.Expect(err).To(gomega.BeNil())
+	_, err = b.Subscribe(ctx, "d")
+	g.Expect(err).To(gomega.Equal(ErrTooManySubscriptions))
+
+	// the subscriptions terminate with the connection context
+	cancel()
+	_, ok := <-s3.Events()
+	g.Expect(ok).To(gomega.BeFalse())
+	g.Eventually(func() bool { return b.HasSubscribers("b") }).Should(gomega.BeFalse())
+	g.Expect(s3.Disconnected()).To(gomega.BeFalse())
+}
+
+func TestBrokerStats(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	b := New(1, PolicyDrop, 0)
+
+	s1, _ := b.Subscribe(context.Background(), "a")
+	_, _ = b.Subscribe(context.Background(), "a")
+	_, _ = b.SubscribeWithPolicy(context.Background(), "b", PolicyDisconnect)
+
+	b.Publish("a", 1)
+	b.Publish("a", 2)
+	b.Publish("b", 1)
+	b.Publish("b", 2)
+
+	st := b.Stats()
+	g.Expect(st.Policy).To(gomega.Equal("drop"))
+	g.Expect(st.QueueSize).To(gomega.Equal(1))
+	g.Expect(st.Subscribers).To(gomega.Equal(2))
+	g.Expect(st.Delivered).To(gomega.Equal(uint64(3)))
+	g.Expect(st.Dropped).To(gomega.Equal(uint64(3)))
+	g.Expect(st.Disconnected).To(gomega.Equal(uint64(1)))
+	g.Expect(st.Topics).To(gomega.Equal(map[string]TopicStats{
+		"a": {Topics: 1, Subscribers: 2, Delivered: 2, Dropped: 2},
+	}))
+
+	// the totals are kept, topic counters go with the topic
+	g.Expect(drain(s1)).To(gomega.HaveLen(1))
+	b.Publish("a", 3)
+	st = b.Stats()
+	g.Expect(st.Topics["a"].Delivered).To(gomega.Equal(uint64(3)))
+	g.Expect(st.Topics["a"].Dropped).To(gomega.Equal(uint64(3)))
+	g.Expect(st.Delivered).To(gomega.Equal(uint64(4)))
+	g.Expect(st.Dropped).To(gomega.Equal(uint64(4)))
+	g.Expect(s1.Delivered()).To(gomega.Equal(uint64(2)))
+	g.Expect(s1.Dropped()).To(gomega.Equal(uint64(1)))
+}
+
+func TestBrokerStatsGroups(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	b := New(10, PolicyDrop, 0)
+
+	a1, a2 := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	_, _ = b.Subscribe(context.Background(), TopicAccountActivity(&a1))
+	_, _ = b.Subscribe(context.Background(), TopicAccountActivity(&a2))
+	_, _ = b.Subscribe(context.Background(), TopicAccountActivity(&a2))
+	_, _ = b.Subscribe(context.Background(), TopicTokenTransfer(nil))
+	_, _ = b.Subscribe(context.Background(), TopicBlocks)
+
+	b.Publish(TopicAccountActivity(&a1), 1)
+	b.Publish(TopicAccountActivity(&a2), 2)
+
+	// the topics are aggregated by the prefix, the subscribed addresses are not exposed
+	g.Expect(b.Stats().Topics).To(gomega.Equal(map[string]TopicStats{
+		"acc":    {Topics: 2, Subscribers: 3, Delivered: 3},
+		"tok":    {Topics: 1, Subscribers: 1},
+		"blocks": {Topics: 1, Subscribers: 1},
+	}))
+}
+
+func TestBrokerQueueSize(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// the queue can not be empty
+	g.Expect(New(0, PolicyDrop, 0).Stats().QueueSize).To(gomega.Equal(minQueueSize))
+}
+
+func TestParsePolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	p, err := ParsePolicy("drop")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p).To(gomega.Equal(PolicyDrop))
+
+	p, err = ParsePolicy("")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p).To(gomega.Equal(PolicyDrop))
+
+	p, err = ParsePolicy("disconnect")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(p).To(gomega.Equal(PolicyDisconnect))
+	g.Expect(p.String()).To(gomega.Equal("disconnect"))
+
+	p, err = ParsePolicy("block")
+	g.Expect(err).To(gomega.Equal(ErrUnknownPolicy))
+	g.Expect(p).To(gomega.Equal(PolicyDrop))
+}
+
+func TestTopics(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	adr := common.HexToAddress("0xABCDEF")
+
+	// address topics are case insensitive
+	g.Expect(TopicAccountActivity(&adr)).To(gomega.Equal("acc:0x0000000000000000000000000000000000abcdef"))
+	g.Expect(TopicTokenTransfer(nil)).To(gomega.Equal("tok:*"))
+	g.Expect(TopicTokenTransfer(&adr)).ToNot(gomega.Equal(TopicTokenTransfer(nil)))
+	g.Expect(TopicUniswapSwap(&adr)).ToNot(gomega.Equal(TopicUniswapPairPrice(&adr)))
+}
@@ -0,0 +1,62 @@
+// Package broker implements topic based fan-out of events to API subscribers.
+package broker
+
+import "strings"
+
+// Stats represents a snapshot of the broker counters.
+type Stats struct {
+	Subscribers  int                   `json:"subscribers"`
+	Delivered    uint64                `json:"delivered"`
+	Dropped      uint64                `json:"dropped"`
+	Disconnected uint64                `json:"disconnected"`
+	Policy       string                `json:"policy"`
+	QueueSize    int                   `json:"queueSize"`
+	Topics       map[string]TopicStats `json:"topics"`
+}
+
+// TopicStats represents a snapshot of the counters of a group of topics sharing the same prefix,
+// e.g. all the account activity topics; the topic names carry addresses and hashes the clients
+// subscribed to, so they are not exposed. The topic counters are kept only while the topic
+// has any subscriber.
+type TopicStats struct {
+	Topics      int    `json:"topics"`
+	Subscribers int    `json:"subscribers"`
+	Delivered   uint64 `json:"delivered"`
+	Dropped     uint64 `json:"dropped"`
+}
+
+// Stats provides a snapshot of the broker counters.
+func (b *Broker) Stats() Stats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	st := Stats{
+		Delivered:    b.delivered.Load(),
+		Dropped:      b.dropped.Load(),
+		Disconnected: b.disconnected.Load(),
+		Policy:       b.policy.String(),
+		QueueSize:    b.queueSize,
+		Topics:       make(map[string]TopicStats, len(b.topics)),
+	}
+	for name, t := range b.topics {
+		st.Subscribers += len(t.subs)
+
+		group := topicGroup(name)
+		ts := st.Topics[group]
+		ts.Topics++
+		ts.Subscribers += len(t.subs)
+		ts.Delivered += t.delivered.Load()
+		ts.Dropped += t.dropped.Load()
+		st.Topics[group] = ts
+	}
+	return st
+}
+
+// topicGroup provides the name of the stats group of the topic; the prefix
+// of a parametrized topic, or the whole name of a plain topic.
+func topicGroup(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
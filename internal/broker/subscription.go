@@ -0,0 +1,120 @@
+// Package broker implements topic based fan-out of events to API subscribers.
+package broker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Subscription represents a single subscriber of a broker topic.
+type Subscription struct {
+	id     uint64
+	topic  string
+	policy Policy
+	filter Filter
+	events chan interface{}
+	broker *Broker
+	conn   *Connection
+	once   sync.Once
+
+	// disconnected marks the subscription terminated by the slow subscriber policy
+	disconnected atomic.Bool
+
+	// subscription counters
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// Connection represents a client connection holding subscriptions,
+// e.g. a single websocket connection of an API client.
+type Connection struct {
+	active atomic.Int32
+}
+
+// connectionKey represents the context key of the client connection.
+type connectionKey struct{}
+
+// WithConnection provides a context carrying a new client connection.
+// Subscriptions made with the context are counted against the connection limit.
+func WithConnection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, connectionKey{}, &Connection{})
+}
+
+// connectionOf provides the client connection carried by the context, if any.
+func connectionOf(ctx context.Context) *Connection {
+	conn, _ := ctx.Value(connectionKey{}).(*Connection)
+	return conn
+}
+
+// acquire reserves a subscription slot of the connection. Zero limit means no limit.
+func (c *Connection) acquire(limit int) bool {
+	if c.active.Add(1) > int32(limit) && limit > 0 {
+		c.active.Add(-1)
+		return false
+	}
+	return true
+}
+
+// release returns a subscription slot of the connection.
+func (c *Connection) release() {
+	c.active.Add(-1)
+}
+
+// Id returns the identifier of the subscription.
+func (sub *Subscription) Id() uint64 {
+	return sub.id
+}
+
+// Topic returns the topic of the subscription.
+func (sub *Subscription) Topic() string {
+	return sub.topic
+}
+
+// Events provides the channel of the subscription events.
+// The channel is closed when the subscription terminates.
+func (sub *Subscription) Events() <-chan interface{} {
+	return sub.events
+}
+
+// Disconnected checks if the subscription was terminated by the broker
+// because the subscriber could not keep up with the events.
+func (sub *Subscription) Disconnected() bool {
+	return sub.disconnected.Load()
+}
+
+// Delivered returns the number of events queued for the subscriber.
+func (sub *Subscription) Delivered() uint64 {
+	return sub.delivered.Load()
+}
+
+// Dropped returns the number of events the subscriber lost.
+func (sub *Subscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// Close terminates the subscription and closes its events channel.
+// It's safe to call Close multiple times.
+func (sub *Subscription) Close() {
+	sub.once.Do(sub.detach)
+}
+
+// terminate closes the subscription on behalf of the slow subscriber policy.
+// It returns true if the subscription was not closed before.
+func (sub *Subscription) terminate() bool {
+	done := false
+	sub.once.Do(func() {
+		sub.disconnected.Store(true)
+		sub.detach()
+		done = true
+	})
+	return done
+}
+
+// detach removes the subscription from the broker and releases its connection slot.
+func (sub *Subscription) detach() {
+	sub.broker.remove(sub)
+	if sub.conn != nil {
+		sub.conn.release()
+	}
+}
@@ -0,0 +1,75 @@
+// Package broker implements topic based fan-out of events to API subscribers.
+package broker
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// topicAccountActivityPrefix is the prefix of the account activity topics.
+	topicAccountActivityPrefix = "acc:"
+
+	// topicTokenTransferPrefix is the prefix of the token transfer topics.
+	topicTokenTransferPrefix = "tok:"
+
+	// topicTokenTransferAll is the topic of transfers of any token.
+	topicTokenTransferAll = "tok:*"
+
+	// topicUniswapSwapPrefix is the prefix of the Uniswap pair swap topics.
+	topicUniswapSwapPrefix = "swap:"
+
+	// topicUniswapPricePrefix is the prefix of the Uniswap pair price topics.
+	topicUniswapPricePrefix = "price:"
+
+	// topicTransactionStatusPrefix is the prefix of the transaction lifecycle topics.
+	topicTransactionStatusPrefix = "status:"
+
+	// TopicBlocks is the topic of new blocks processed by the block dispatcher.
+	TopicBlocks = "blocks"
+
+	// TopicTransactions is the topic of new transactions processed by the transaction dispatcher.
+	TopicTransactions = "trx"
+
+	// TopicPendingTransactions is the topic of new transactions entering the node pool.
+	TopicPendingTransactions = "pending"
+
+	// TopicLogs is the topic of all the processed event logs.
+	TopicLogs = "logs"
+
+	// TopicGasPrice is the topic of changes of the extended gas price estimation.
+	TopicGasPrice = "gas"
+
+	// TopicSealedEpoch is the topic of newly sealed epochs.
+	TopicSealedEpoch = "epoch"
+)
+
+// TopicAccountActivity provides the broker topic of the activity of the given account.
+func TopicAccountActivity(addr *common.Address) string {
+	return topicAccountActivityPrefix + strings.ToLower(addr.String())
+}
+
+// TopicTokenTransfer provides the broker topic of transfers of the given token.
+// Transfers of all the tokens are published to a common topic if no token is given.
+func TopicTokenTransfer(token *common.Address) string {
+	if token == nil {
+		return topicTokenTransferAll
+	}
+	return topicTokenTransferPrefix + strings.ToLower(token.String())
+}
+
+// TopicUniswapSwap provides the broker topic of swaps on the given Uniswap pair.
+func TopicUniswapSwap(pair *common.Address) string {
+	return topicUniswapSwapPrefix + strings.ToLower(pair.String())
+}
+
+// TopicUniswapPairPrice provides the broker topic of reserves changes of the given Uniswap pair.
+func TopicUniswapPairPrice(pair *common.Address) string {
+	return topicUniswapPricePrefix + strings.ToLower(pair.String())
+}
+
+// TopicTransactionStatus provides the broker topic of lifecycle changes of the given tracked transaction.
+func TopicTransactionStatus(hash *common.Hash) string {
+	return topicTransactionStatusPrefix + strings.ToLower(hash.String())
+}
@@ -46,6 +46,15 @@ type Config struct {
 	// Governance configuration
 	Governance Governance `mapstructure:"governance"`
 
+	// Webhooks configuration
+	Webhooks Webhooks `mapstructure:"webhooks"`
+
+	// Subscriptions broker configuration
+	Subscriptions Subscriptions `mapstructure:"subscriptions"`
+
+	// Transactions submission configuration
+	Transactions Transactions `mapstructure:"transactions"`
+
 	// TokenLogoFilePath contains the path to JSON file with the map
 	// of known ERC20 tokens to their logo URLs.
 	// The file will be loaded on configuration loading.
@@ -63,6 +72,7 @@ type Config struct {
 type RepoCmd struct {
 	BlockScanReScan uint64
 	RestoreStake    string
+	RebuildCandles  bool
 }
 
 // Server represents the GraphQL server configuration
@@ -134,6 +144,7 @@ type DeFi struct {
 	Uniswap      DeFiUniswap `mapstructure:"uniswap"`
 	FLend        DeFiFLend   `mapstructure:"flend"`
 	PriceSymbols []string    `mapstructure:"symbols"`
+	Prices       PriceOracle `mapstructure:"prices"`
 }
 
 // DeFiFMint represents the fMint DeFi module configuration.
@@ -142,10 +153,14 @@ type DeFiFMint struct {
 }
 
 // DeFiUniswap represents the Uniswap protocol DeFi module configuration.
+// The factory block is the block of the factory deployment; the pairs discovery
+// starts there on the first run. If not set, the pairs created before the first run
+// are taken from the factory list only.
 type DeFiUniswap struct {
 	Core           common.Address   `mapstructure:"core"`
 	Router         common.Address   `mapstructure:"router"`
 	PairsWhiteList []common.Address `mapstructure:"whitelist"`
+	FactoryBlock   uint64           `mapstructure:"factory_block"`
 }
 
 // Governance represents the governance module configuration.
@@ -162,7 +177,76 @@ type GovernanceContract struct {
 	Type       string         `mapstructure:"type"`
 }
 
+// Webhooks represents the webhook notification service configuration.
+// Webhooks are disabled by default, the registration is open to any API client.
+// Private targets (loopback, private and link-local networks) are refused, both on
+// the registration and on each delivery, unless explicitly allowed, e.g. for testing
+// against a local receiver. Redirects of the targets are never followed.
+// Finished deliveries, both delivered and dead, are removed after the retention period;
+// zero retention keeps them forever.
+type Webhooks struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	AllowPrivate bool          `mapstructure:"allow_private"`
+	MaxHooks     int           `mapstructure:"max_hooks"`
+	Retention    time.Duration `mapstructure:"retention"`
+}
+
+// Subscriptions represents the configuration of the subscriptions broker.
+// The policy decides what happens to a subscriber which can not keep up
+// with the events; "drop" skips the events which do not fit into its queue,
+// "disconnect" terminates the subscription. If any auth tokens are set, websocket
+// clients must present one of them in the connection_init payload.
+type Subscriptions struct {
+	QueueSize        int      `mapstructure:"queue_size"`
+	Policy           string   `mapstructure:"policy"`
+	MaxPerConnection int      `mapstructure:"max_per_connection"`
+	AuthTokens       []string `mapstructure:"auth_tokens"`
+}
+
+// Transactions represents the configuration of the transactions submission.
+// If the pre-flight validation is enabled, submitted transactions are decoded
+// and checked against the chain state before being sent to the node.
+// Transactions priced below the min gas price (in WEI) are refused; the gas price
+// is not checked if no min gas price is set, the node applies its own floor.
+// Simulated transactions are limited to the max gas, the gas of the caller is capped
+// to it, and to the max number of simulations running at once.
+type Transactions struct {
+	Preflight               bool   `mapstructure:"preflight"`
+	MinGasPrice             uint64 `mapstructure:"min_gas_price"`
+	SimulationMaxGas        uint64 `mapstructure:"simulation_max_gas"`
+	SimulationMaxConcurrent int    `mapstructure:"simulation_max_concurrent"`
+}
+
 // DeFiFLend represents the fLend DeFi module configuration.
 type DeFiFLend struct {
 	LendingPool common.Address `mapstructure:"lending_pool"`
 }
+
+// PriceOracle represents the native token price sources configuration.
+type PriceOracle struct {
+	MaxAge  time.Duration `mapstructure:"max_age"`
+	Sources []PriceSource `mapstructure:"sources"`
+}
+
+// PriceSource represents a single native token price source configuration.
+// Sources of the same priority are aggregated by their weighted median,
+// sources of a higher priority value are used as a fallback only.
+type PriceSource struct {
+	// Symbol is the target price symbol; an empty symbol applies the source to all symbols.
+	Symbol   string        `mapstructure:"symbol"`
+	Type     string        `mapstructure:"type"`
+	Weight   float64       `mapstructure:"weight"`
+	Priority int           `mapstructure:"priority"`
+	MaxAge   time.Duration `mapstructure:"max_age"`
+
+	// Url and Path are used by REST sources; the URL may contain {from} and {to} placeholders.
+	Url      string `mapstructure:"url"`
+	Path     string `mapstructure:"path"`
+	TimePath string `mapstructure:"time_path"`
+
+	// Pair and Token are used by on-chain sources; the Token is the native token
+	// representation on the Uniswap pair, or the token priced by the fMint oracle.
+	Pair   common.Address `mapstructure:"pair"`
+	Token  common.Address `mapstructure:"token"`
+	Window time.Duration  `mapstructure:"window"`
+}
@@ -81,8 +81,32 @@ const (
 	// defTokenLogoFilePath represents the default path to the tokens map file
 	defTokenLogoFilePath = "tokens.json"
 
+	// defPriceMaxAge represents the default max age of a price quote to be considered valid
+	defPriceMaxAge = 30 * time.Minute
+
 	// defBlockScanRescanDepth represents the amount of blocks re-scanned on server start
 	defBlockScanRescanDepth = 200
+
+	// defWebhooksMaxHooks represents the default max number of registered webhooks
+	defWebhooksMaxHooks = 1000
+
+	// defWebhooksRetention represents the default period finished webhook deliveries are kept for
+	defWebhooksRetention = 7 * 24 * time.Hour
+
+	// defSubscriptionsQueueSize represents the default number of events queued for a single subscriber
+	defSubscriptionsQueueSize = 500
+
+	// defSubscriptionsPolicy represents the default policy applied to subscribers which can not keep up
+	defSubscriptionsPolicy = "drop"
+
+	// defSubscriptionsMaxPerConnection represents the default max number of subscriptions of a single connection
+	defSubscriptionsMaxPerConnection = 50
+
+	// defTransactionsSimulationMaxGas represents the default max gas of a simulated transaction
+	defTransactionsSimulationMaxGas = 25000000
+
+	// defTransactionsSimulationMaxConcurrent represents the default max number of simulations running at once
+	defTransactionsSimulationMaxConcurrent = 8
 )
 
 // default list of API peers
@@ -91,6 +115,9 @@ var defApiPeers = []string{"https://localhost:3000/api"}
 // defCorsAllowOrigins holds CORS default allowed origins.
 var defCorsAllowOrigins = []string{"*"}
 
+// defSubscriptionsAuthTokens holds the default list of subscription auth tokens; no auth is required.
+var defSubscriptionsAuthTokens = make([]string, 0)
+
 // default list of API peers
 var defVotingSources = make([]string, 0)
 
@@ -142,7 +169,27 @@ func applyDefaults(cfg *viper.Viper) {
 	//cfg.SetDefault(keyStakingERC20Token, EmptyAddress)
 
 	// DeFi configuration
+	cfg.SetDefault(keyDefiPricesMaxAge, defPriceMaxAge)
+	cfg.SetDefault(keyDefiUniswapFactoryBlock, 0)
 	//cfg.SetDefault(keyDefiFMintAddressProvider, defDefiFMintAddressProvider)
 	//cfg.SetDefault(keyDefiUniswapCore, defDefiUniswapCore)
 	//cfg.SetDefault(keyDefiUniswapRouter, defDefiUniswapRouter)
+
+	// webhooks configuration; the webhook mutations are not authenticated, so they are opt-in
+	cfg.SetDefault(keyWebhooksEnabled, false)
+	cfg.SetDefault(keyWebhooksAllowPrivate, false)
+	cfg.SetDefault(keyWebhooksMaxHooks, defWebhooksMaxHooks)
+	cfg.SetDefault(keyWebhooksRetention, defWebhooksRetention)
+
+	// subscriptions broker configuration
+	cfg.SetDefault(keySubscriptionsQueueSize, defSubscriptionsQueueSize)
+	cfg.SetDefault(keySubscriptionsPolicy, defSubscriptionsPolicy)
+	cfg.SetDefault(keySubscriptionsMaxPerConnection, defSubscriptionsMaxPerConnection)
+	cfg.SetDefault(keySubscriptionsAuthTokens, defSubscriptionsAuthTokens)
+
+	// transactions submission configuration
+	cfg.SetDefault(keyTransactionsPreflight, true)
+	cfg.SetDefault(keyTransactionsMinGasPrice, 0)
+	cfg.SetDefault(keyTransactionsSimulationMaxGas, defTransactionsSimulationMaxGas)
+	cfg.SetDefault(keyTransactionsSimulationMaxConcurrent, defTransactionsSimulationMaxConcurrent)
 }
@@ -12,6 +12,7 @@ const (
 	keyConfigCmdBlockScanEnd    = "cmd.blk_to"
 	keyConfigCmdBlockScanReScan = "cmd.rescan"
 	keyConfigCmdRestoreStake    = "cmd.fix_stake"
+	keyConfigCmdRebuildCandles  = "cmd.rebuild_candles"
 
 	// server related keys
 	keyBindAddress      = "server.bind"
@@ -61,6 +62,27 @@ const (
 	//keyStakingERC20Token        = "staking.token"
 
 	// defi related configs
+	keyDefiPricesMaxAge        = "defi.prices.max_age"
+	keyDefiUniswapFactoryBlock = "defi.uniswap.factory_block"
+
+	// webhooks related configs
+	keyWebhooksEnabled      = "webhooks.enabled"
+	keyWebhooksAllowPrivate = "webhooks.allow_private"
+	keyWebhooksMaxHooks     = "webhooks.max_hooks"
+	keyWebhooksRetention    = "webhooks.retention"
+
+	// subscriptions broker related configs
+	keySubscriptionsQueueSize        = "subscriptions.queue_size"
+	keySubscriptionsPolicy           = "subscriptions.policy"
+	keySubscriptionsMaxPerConnection = "subscriptions.max_per_connection"
+	keySubscriptionsAuthTokens       = "subscriptions.auth_tokens"
+
+	// transactions submission related configs
+	keyTransactionsPreflight               = "transactions.preflight"
+	keyTransactionsMinGasPrice             = "transactions.min_gas_price"
+	keyTransactionsSimulationMaxGas        = "transactions.simulation_max_gas"
+	keyTransactionsSimulationMaxConcurrent = "transactions.simulation_max_concurrent"
+
 	//keyDefiFMintAddressProvider = "defi.fmint.address_provider"
 	//keyDefiUniswapCore          = "defi.uniswap.core"
 	//keyDefiUniswapRouter        = "defi.uniswap.router"
@@ -46,6 +46,7 @@ func Load() (*Config, error) {
 func attachCliFlags(cfg *Config) {
 	flag.Uint64Var(&cfg.RepoCommand.BlockScanReScan, keyConfigCmdBlockScanReScan, defBlockScanRescanDepth, "How many blocks are re-scanned on the server start.")
 	flag.StringVar(&cfg.RepoCommand.RestoreStake, keyConfigCmdRestoreStake, "", "Owner of the stake to be restored.")
+	flag.BoolVar(&cfg.RepoCommand.RebuildCandles, keyConfigCmdRebuildCandles, false, "Rebuild Uniswap candle rollups from stored swaps.")
 }
 
 // readConfigFile reads the config file and provides instance
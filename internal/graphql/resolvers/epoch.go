@@ -42,3 +42,12 @@ func (ep Epoch) Duration() hexutil.Uint64 {
 	}
 	return ep.EndTime - prev.EndTime
 }
+
+// ValidatorRewards resolves the summary of rewards distributed to validators in the epoch.
+func (ep Epoch) ValidatorRewards() (*[]types.EpochValidatorRewards, error) {
+	list, err := repository.R().EpochValidatorRewards(ep.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
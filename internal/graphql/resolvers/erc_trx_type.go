@@ -10,6 +10,8 @@ const (
 	ErcTrxTypeNameBurn           = "BURN"
 	ErcTrxTypeNameApproval       = "APPROVAL"
 	ErcTrxTypeNameApprovalForAll = "APPROVAL_FOR_ALL"
+	ErcTrxTypeNameWrap           = "WRAP"
+	ErcTrxTypeNameUnwrap         = "UNWRAP"
 )
 
 func ercTrxTypeToName(trxType int32) string {
@@ -24,6 +26,10 @@ func ercTrxTypeToName(trxType int32) string {
 		return ErcTrxTypeNameBurn
 	case types.TokenTrxTypeApprovalForAll:
 		return ErcTrxTypeNameApprovalForAll
+	case types.TokenTrxTypeWrap:
+		return ErcTrxTypeNameWrap
+	case types.TokenTrxTypeUnwrap:
+		return ErcTrxTypeNameUnwrap
 	default:
 		return "OTHER"
 	}
@@ -46,6 +52,10 @@ func ercTrxTypesFromNames(names *[]string) []int32 {
 			vals = append(vals, types.TokenTrxTypeBurn)
 		case ErcTrxTypeNameApprovalForAll:
 			vals = append(vals, types.TokenTrxTypeApprovalForAll)
+		case ErcTrxTypeNameWrap:
+			vals = append(vals, types.TokenTrxTypeWrap)
+		case ErcTrxTypeNameUnwrap:
+			vals = append(vals, types.TokenTrxTypeUnwrap)
 		}
 	}
 	return vals
@@ -16,6 +16,9 @@ const maxGasPriceListRange = 90 * 24 * time.Hour
 // GasPriceTick represents a tick of the gas price.
 type GasPriceTick types.GasPricePeriod
 
+// GasPriceEstimate represents an extended gas price estimation.
+type GasPriceEstimate types.GasPrice
+
 // GasPriceList resolves a list of gas price ticks for the given time period.
 func (rs *rootResolver) GasPriceList(args struct {
 	From time.Time
@@ -41,6 +41,25 @@ type ApiResolver interface {
 	// to notify them about the change.
 	ValidateContract(*struct{ Contract ContractValidationInput }) (*Contract, error)
 
+	// RegisterWebhook resolves registration of a new webhook receiving events matching the filter.
+	RegisterWebhook(struct {
+		Url    string
+		Filter WebhookFilterInput
+	}) (*Webhook, error)
+
+	// UnregisterWebhook resolves removal of the webhook of the given id.
+	UnregisterWebhook(struct{ Id string }) (bool, error)
+
+	// Webhook resolves the webhook registration of the given id.
+	Webhook(struct{ Id string }) (*Webhook, error)
+
+	// WebhookDeliveries resolves the delivery log of the webhook of the given id.
+	WebhookDeliveries(struct {
+		Id     string
+		Status *string
+		Count  *int32
+	}) ([]*WebhookDelivery, error)
+
 	// Block resolves blockchain block by number or by hash. If neither is provided, the most recent block is given.
 	Block(*struct {
 		Number *hexutil.Uint64
@@ -56,6 +75,21 @@ type ApiResolver interface {
 	// Transaction resolves blockchain transaction by hash.
 	Transaction(*struct{ Hash common.Hash }) (*Transaction, error)
 
+	// TransactionStatus resolves the lifecycle state of a transaction submitted through the API.
+	TransactionStatus(struct{ Hash common.Hash }) *TransactionStatus
+
+	// SimulateTransaction resolves the outcome of the described transaction executed
+	// against the given block state with the state overrides applied.
+	SimulateTransaction(args struct {
+		From           common.Address
+		To             *common.Address
+		Value          *hexutil.Big
+		Data           *hexutil.Bytes
+		Gas            *hexutil.Uint64
+		BlockNumber    *hexutil.Uint64
+		StateOverrides *[]StateOverrideInput
+	}) (*TransactionSimulation, error)
+
 	// Transactions resolves list of blockchain transactions encapsulated in a listable structure.
 	Transactions(*struct {
 		Cursor *Cursor
@@ -63,10 +97,56 @@ type ApiResolver interface {
 	}) (*TransactionList, error)
 
 	// OnBlock resolves subscription to new blocks' event broadcast.
-	OnBlock(ctx context.Context) <-chan *Block
+	OnBlock(ctx context.Context, args struct{ FromBlock *hexutil.Uint64 }) (<-chan *Block, error)
 
 	// OnTransaction resolves subscription to new transactions' event broadcast.
-	OnTransaction(ctx context.Context) <-chan *Transaction
+	OnTransaction(ctx context.Context, args struct {
+		FromBlock  *hexutil.Uint64
+		FromCursor *Cursor
+	}) (<-chan *Transaction, error)
+
+	// OnTransactionStatus resolves subscription to lifecycle changes of a transaction submitted through the API.
+	OnTransactionStatus(ctx context.Context, args struct{ Hash common.Hash }) (<-chan *TransactionStatus, error)
+
+	// OnLogs resolves subscription to new event logs' broadcast filtered by addresses and topics.
+	OnLogs(ctx context.Context, args struct {
+		Addresses *[]common.Address
+		Topics    *[]*[]common.Hash
+	}) (<-chan *Log, error)
+
+	// OnAccountActivity resolves subscription to events involving the given account.
+	OnAccountActivity(ctx context.Context, args struct{ Address common.Address }) (<-chan *AccountActivity, error)
+
+	// OnTokenTransfer resolves subscription to token transfers filtered by token, account and token type.
+	OnTokenTransfer(ctx context.Context, args struct {
+		Token     *common.Address
+		Account   *common.Address
+		TokenType *string
+	}) (<-chan *TokenTransaction, error)
+
+	// OnPendingTransaction resolves subscription to new transactions entering the node pool.
+	OnPendingTransaction(ctx context.Context, args struct {
+		From *common.Address
+		To   *common.Address
+	}) (<-chan *Transaction, error)
+
+	// OnEpochSealed resolves subscription to epochs as they are sealed.
+	OnEpochSealed(ctx context.Context) (<-chan *Epoch, error)
+
+	// OnGasPrice resolves subscription to changes of the extended gas price estimation.
+	OnGasPrice(ctx context.Context) (<-chan *GasPriceEstimate, error)
+
+	// OnUniswapSwap resolves subscription to swaps on the given Uniswap pair.
+	OnUniswapSwap(ctx context.Context, args struct{ Pair common.Address }) (<-chan *UniswapAction, error)
+
+	// OnPairPrice resolves subscription to price changes of the given Uniswap pair.
+	OnPairPrice(ctx context.Context, args struct{ Pair common.Address }) (<-chan *UniswapPairPrice, error)
+
+	// PendingTransactions resolves the list of transactions waiting in the node pool for the given address.
+	PendingTransactions(args struct{ Address common.Address }) ([]*Transaction, error)
+
+	// TxPoolStatus resolves the number of transactions waiting in the node pool.
+	TxPoolStatus() (types.TxPoolStatus, error)
 
 	// CurrentEpoch resolves id of the current epoch.
 	CurrentEpoch() (hexutil.Uint64, error)
@@ -140,7 +220,10 @@ type ApiResolver interface {
 	}) (hexutil.Big, error)
 
 	// SendTransaction sends raw signed and RLP encoded transaction to the blockchain.
-	SendTransaction(*struct{ Tx hexutil.Bytes }) (*Transaction, error)
+	SendTransaction(*struct {
+		Tx     hexutil.Bytes
+		DryRun *bool
+	}) (*Transaction, error)
 
 	// DefiConfiguration resolves the current DeFi contract settings.
 	DefiConfiguration() (*DefiConfiguration, error)
@@ -149,7 +232,7 @@ type ApiResolver interface {
 	DefiTokens() ([]*DefiToken, error)
 
 	// DefiUniswapPairs resolves a list of all pairs managed by the Uniswap core.
-	DefiUniswapPairs() []*UniswapPair
+	DefiUniswapPairs(*struct{ FeaturedOnly bool }) []*UniswapPair
 
 	// DefiUniswapAmountsOut resolves a list of output amounts for the given
 	// input amount and a list of tokens to be used to make the swap operation.
@@ -172,6 +255,47 @@ type ApiResolver interface {
 		AmountsIn []hexutil.Big
 	}) ([]hexutil.Big, error)
 
+	// DefiUniswapBestRoute resolves a list of the best routes for swapping tokenIn for tokenOut.
+	DefiUniswapBestRoute(*struct {
+		TokenIn   common.Address
+		TokenOut  common.Address
+		AmountIn  *hexutil.Big
+		AmountOut *hexutil.Big
+		MaxHops   int32
+		Count     int32
+		Slippage  float64
+	}) ([]*UniswapRoute, error)
+
+	// DefiUniswapQuoteOut resolves the swap quote for the given input amount
+	// and a list of tokens to be used to make the swap operation.
+	DefiUniswapQuoteOut(*struct {
+		AmountIn hexutil.Big
+		Tokens   []common.Address
+		Slippage float64
+	}) (*UniswapRoute, error)
+
+	// DefiUniswapQuoteIn resolves the swap quote for the given output amount
+	// and a list of tokens to be used to make the swap operation.
+	DefiUniswapQuoteIn(*struct {
+		AmountOut hexutil.Big
+		Tokens    []common.Address
+		Slippage  float64
+	}) (*UniswapRoute, error)
+
+	// DefiUniswapQuoteAddLiquidity resolves the preview of adding liquidity to an Uniswap pair.
+	DefiUniswapQuoteAddLiquidity(*struct {
+		Tokens    []common.Address
+		AmountsIn []hexutil.Big
+		Slippage  float64
+	}) (*UniswapLiquidityQuote, error)
+
+	// DefiUniswapQuoteRemoveLiquidity resolves the preview of removing liquidity from an Uniswap pair.
+	DefiUniswapQuoteRemoveLiquidity(*struct {
+		Tokens    []common.Address
+		Liquidity hexutil.Big
+		Slippage  float64
+	}) (*UniswapLiquidityQuote, error)
+
 	// FMintAccount resolves details of a specified DeFi account.
 	FMintAccount(*struct{ Owner common.Address }) (*FMintAccount, error)
 
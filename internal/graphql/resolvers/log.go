@@ -0,0 +1,67 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	retypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Log represents resolvable event log emitted by a contract.
+type Log struct {
+	retypes.Log
+	blk *types.Block
+	trx *types.Transaction
+}
+
+// NewLog builds new resolvable event log structure.
+func NewLog(lr *types.LogRecord) *Log {
+	return &Log{
+		Log: lr.Log,
+		blk: lr.Block,
+		trx: lr.Trx,
+	}
+}
+
+// Data resolves the non-indexed data of the log.
+func (l *Log) Data() hexutil.Bytes {
+	return l.Log.Data
+}
+
+// BlockNumber resolves the number of the block the log belongs to.
+func (l *Log) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(l.Log.BlockNumber)
+}
+
+// TransactionHash resolves the hash of the transaction emitting the log.
+func (l *Log) TransactionHash() common.Hash {
+	return l.Log.TxHash
+}
+
+// TransactionIndex resolves the index of the transaction in the block.
+func (l *Log) TransactionIndex() hexutil.Uint64 {
+	return hexutil.Uint64(l.Log.TxIndex)
+}
+
+// Index resolves the index of the log in the block.
+func (l *Log) Index() hexutil.Uint64 {
+	return hexutil.Uint64(l.Log.Index)
+}
+
+// Block resolves the block the log belongs to.
+func (l *Log) Block() *Block {
+	if l.blk == nil {
+		return nil
+	}
+	return NewBlock(l.blk)
+}
+
+// Transaction resolves the transaction emitting the log.
+func (l *Log) Transaction() *Transaction {
+	if l.trx == nil {
+		return nil
+	}
+	return NewTransaction(l.trx)
+}
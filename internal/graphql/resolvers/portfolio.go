@@ -0,0 +1,35 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+)
+
+// Portfolio represents resolvable valuation of an account holdings.
+type Portfolio struct {
+	types.Portfolio
+}
+
+// PortfolioItem represents resolvable single holding of an account portfolio.
+type PortfolioItem struct {
+	types.PortfolioItem
+}
+
+// Portfolio resolves the valuation of all the holdings of the account in the given currency.
+func (acc *Account) Portfolio(args struct{ Currency string }) (*Portfolio, error) {
+	pf, err := repository.R().Portfolio(&acc.Address, args.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return &Portfolio{Portfolio: *pf}, nil
+}
+
+// Items resolves the list of the portfolio line items.
+func (pf *Portfolio) Items() []PortfolioItem {
+	list := make([]PortfolioItem, len(pf.Portfolio.Items))
+	for i, it := range pf.Portfolio.Items {
+		list[i] = PortfolioItem{PortfolioItem: it}
+	}
+	return list
+}
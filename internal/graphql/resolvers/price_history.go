@@ -0,0 +1,132 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// maxPriceSamplesRange represents the max time span of the price history made of price samples.
+const maxPriceSamplesRange = 31 * 24 * time.Hour
+
+// PriceTick represents a resolvable price of the native token over a period of time.
+type PriceTick struct {
+	types.PriceTick
+}
+
+// PriceHistory resolves the price history of the native token in the given symbol.
+// The resolution is "sample", "hour" or "day"; it's derived from the time span if not given.
+func (rs *rootResolver) PriceHistory(args struct {
+	To         string
+	From       time.Time
+	Until      *time.Time
+	Resolution *string
+}) ([]*PriceTick, error) {
+	// is the requested denomination even reasonable
+	if !reExpectedPriceSymbol.Match([]byte(args.To)) {
+		return nil, fmt.Errorf("invalid denomination received")
+	}
+
+	// make sure to set the end time
+	if args.Until == nil {
+		now := time.Now()
+		args.Until = &now
+	}
+
+	diff := args.Until.Sub(args.From)
+	if diff < 0 {
+		return nil, fmt.Errorf("invalid time span")
+	}
+
+	res := priceHistoryResolution(diff)
+	if args.Resolution != nil {
+		res = *args.Resolution
+	}
+
+	// samples are available for a limited time span only
+	if res != "day" && diff > maxPriceSamplesRange {
+		return nil, fmt.Errorf("time span too long for %s resolution", res)
+	}
+
+	var tickLen time.Duration
+	switch res {
+	case "day", "sample":
+	case "hour":
+		tickLen = time.Hour
+	default:
+		return nil, fmt.Errorf("unknown resolution %s", res)
+	}
+
+	ticks, err := repository.R().PriceTicks(args.To, &args.From, args.Until, res == "day")
+	if err != nil {
+		return nil, err
+	}
+	return aggregatePriceTicks(ticks, tickLen), nil
+}
+
+// PriceAt resolves the price of the native token in the given symbol at the given time.
+func (rs *rootResolver) PriceAt(args struct {
+	To   string
+	Time time.Time
+}) (*PriceTick, error) {
+	// is the requested denomination even reasonable
+	if !reExpectedPriceSymbol.Match([]byte(args.To)) {
+		return nil, fmt.Errorf("invalid denomination received")
+	}
+
+	pt, err := repository.R().PriceAt(args.To, args.Time)
+	if err != nil || pt == nil {
+		return nil, err
+	}
+	return &PriceTick{PriceTick: *pt}, nil
+}
+
+// priceHistoryResolution derives the price history resolution based on the time span.
+// * 2 days or less => samples
+// * 2 days to 31 days => 1 hour
+// * more than 31 days => 1 day
+func priceHistoryResolution(diff time.Duration) string {
+	if diff <= 48*time.Hour {
+		return "sample"
+	}
+	if diff <= maxPriceSamplesRange {
+		return "hour"
+	}
+	return "day"
+}
+
+// aggregatePriceTicks collects the price ticks into ticks of the given length.
+// The ticks are kept as they are if the length is not given.
+func aggregatePriceTicks(ticks []types.PriceTick, tickLen time.Duration) []*PriceTick {
+	list := make([]*PriceTick, 0, len(ticks))
+
+	var this *PriceTick
+	for _, next := range ticks {
+		if tickLen > 0 && this != nil && this.PriceTick.Time.Equal(next.Time.Truncate(tickLen)) {
+			this.Close = next.Close
+			if this.High < next.High {
+				this.High = next.High
+			}
+			if this.Low > next.Low {
+				this.Low = next.Low
+			}
+			continue
+		}
+
+		this = &PriceTick{PriceTick: next}
+		if tickLen > 0 {
+			this.PriceTick.Time = next.Time.Truncate(tickLen)
+		}
+		list = append(list, this)
+	}
+	return list
+}
+
+// Time resolves the starting time of the price tick.
+func (pt *PriceTick) Time() graphql.Time {
+	return graphql.Time{Time: pt.PriceTick.Time}
+}
@@ -6,42 +6,21 @@ import (
 	"ncogearthchain-api-graphql/cmd/apiserver/build"
 	"ncogearthchain-api-graphql/internal/config"
 	"ncogearthchain-api-graphql/internal/logger"
-	"ncogearthchain-api-graphql/internal/svc"
-	"ncogearthchain-api-graphql/internal/types"
-	"sync"
 
 	"golang.org/x/sync/singleflight"
 )
 
 const (
-	// subscriptionQueueCapacity is the amount of subscriptions we buffer for processing.
-	subscriptionQueueCapacity = 100
-
-	// subscriptionInitialCapacity is the initial length of the subscription queue.
-	subscriptionInitialCapacity = 100
-
 	// listMaxEdgesPerRequest maximal number of edges end-client can request in one query.
 	listMaxEdgesPerRequest uint32 = 250
 )
 
 // rootResolver represents the ApiResolver implementation.
 type rootResolver struct {
-	// service terminator
-	wg      sync.WaitGroup
-	cg      singleflight.Group
-	sigStop chan bool
-
-	// blocks subscriptions management
-	subscribeOnBlock   chan *subscriptOnBlock
-	unsubscribeOnBlock chan string
-	blockSubscribers   map[string]*subscriptOnBlock
-	onBlockEvents      chan *types.Block
-
-	// transaction subscriptions management
-	subscribeOnTrx   chan *subscriptOnTrx
-	unsubscribeOnTrx chan string
-	trxSubscribers   map[string]*subscriptOnTrx
-	onTrxEvents      chan *types.Transaction
+	cg singleflight.Group
+
+	// simulations holds a slot of each transaction simulation in progress
+	simulations chan struct{}
 }
 
 // log represents the logger to be used by the repository.
@@ -72,84 +51,16 @@ func New() ApiResolver {
 		panic(fmt.Errorf("missing logger"))
 	}
 
-	// create new resolver
-	rs := rootResolver{
-		// create terminator
-		sigStop: make(chan bool, 1),
-
-		// block events subscription basics
-		subscribeOnBlock:   make(chan *subscriptOnBlock, subscriptionQueueCapacity),
-		unsubscribeOnBlock: make(chan string, subscriptionQueueCapacity),
-		blockSubscribers:   make(map[string]*subscriptOnBlock, subscriptionInitialCapacity),
-		onBlockEvents:      make(chan *types.Block, onBlockChannelCapacity),
-
-		// block events subscription basics
-		subscribeOnTrx:   make(chan *subscriptOnTrx, subscriptionQueueCapacity),
-		unsubscribeOnTrx: make(chan string, subscriptionQueueCapacity),
-		trxSubscribers:   make(map[string]*subscriptOnTrx, subscriptionInitialCapacity),
-		onTrxEvents:      make(chan *types.Transaction, onBlockChannelCapacity),
+	// subscriptions are served by the events broker
+	log.Notice("GraphQL resolver started")
+	return &rootResolver{
+		simulations: make(chan struct{}, simulationSlots(cfg.Transactions.SimulationMaxConcurrent)),
 	}
-
-	// pass subscription data source channels to the service manager
-	// to get them filled with relevant data
-	sm := svc.Manager()
-	sm.SetBlockChannel(rs.onBlockEvents)
-	sm.SetTrxChannel(rs.onTrxEvents)
-
-	// handle broadcast and subscriptions in a separate routine
-	rs.wg.Add(1)
-	go rs.run()
-
-	return &rs
 }
 
-// Close terminates resolver's broadcast service.
+// Close terminates the resolver. Subscriptions are terminated with their connections.
 func (rs *rootResolver) Close() {
-	// log
 	log.Notice("GraphQL resolver is closing")
-
-	// send the signal
-	rs.sigStop <- true
-	rs.wg.Wait()
-}
-
-// run monitors and handles subscriptions and broadcasts incoming events to their subscribers.
-func (rs *rootResolver) run() {
-	// sign off on leaving
-	defer func() {
-		// terminate
-		log.Notice("GraphQL resolver done")
-		rs.wg.Done()
-	}()
-
-	// log action
-	log.Notice("GraphQL resolver started")
-
-	// main loop waits for data on any channel and act upon it
-	for {
-		select {
-		case <-rs.sigStop:
-			return
-
-		case id := <-rs.unsubscribeOnBlock:
-			delete(rs.blockSubscribers, id)
-
-		case id := <-rs.unsubscribeOnTrx:
-			delete(rs.trxSubscribers, id)
-
-		case sub := <-rs.subscribeOnBlock:
-			rs.addBlockSubscriber(sub)
-
-		case sub := <-rs.subscribeOnTrx:
-			rs.addTrxSubscriber(sub)
-
-		case evt := <-rs.onBlockEvents:
-			rs.dispatchOnBlock(evt)
-
-		case evt := <-rs.onTrxEvents:
-			rs.dispatchOnTransaction(evt)
-		}
-	}
 }
 
 // listLimitCount enforces maximum size of a requested list to given limit
@@ -0,0 +1,230 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// simulationMaxOverrides represents the max number of accounts with the state overridden in a single simulation.
+const simulationMaxOverrides = 50
+
+// errSimulationsBusy is returned if the max number of simulations is already running.
+var errSimulationsBusy = fmt.Errorf("too many transaction simulations in progress, try again later")
+
+// TransactionSimulation represents resolvable outcome of a simulated transaction.
+type TransactionSimulation struct {
+	types.TransactionSimulation
+}
+
+// SimulatedLog represents resolvable event log emitted by a simulated transaction.
+type SimulatedLog struct {
+	types.SimulationLog
+}
+
+// BalanceChange represents resolvable balance change caused by a simulated transaction.
+type BalanceChange struct {
+	types.BalanceChange
+}
+
+// DecodedParam represents resolvable value decoded by a contract ABI.
+type DecodedParam struct {
+	types.DecodedParam
+}
+
+// StateOverrideInput represents the input of an account state override.
+type StateOverrideInput struct {
+	Address   common.Address
+	Balance   *hexutil.Big
+	Nonce     *hexutil.Uint64
+	Code      *hexutil.Bytes
+	State     *[]StorageSlotInput
+	StateDiff *[]StorageSlotInput
+}
+
+// StorageSlotInput represents the input of a storage slot value.
+type StorageSlotInput struct {
+	Key   common.Hash
+	Value common.Hash
+}
+
+// SimulateTransaction resolves the outcome of the described transaction executed against
+// the state of the given block, or the latest block, with the state overrides applied.
+// Nothing is broadcast; the outcome includes the decoded return data and logs and the net
+// balance changes of the involved accounts, so a wallet can preview the transaction effects.
+func (rs *rootResolver) SimulateTransaction(args struct {
+	From           common.Address
+	To             *common.Address
+	Value          *hexutil.Big
+	Data           *hexutil.Bytes
+	Gas            *hexutil.Uint64
+	BlockNumber    *hexutil.Uint64
+	StateOverrides *[]StateOverrideInput
+}) (*TransactionSimulation, error) {
+	// the simulations are expensive for the node, limit the number of them running at once
+	select {
+	case rs.simulations <- struct{}{}:
+		defer func() { <-rs.simulations }()
+	default:
+		return nil, errSimulationsBusy
+	}
+
+	call := types.SimulationCall{
+		From:  args.From,
+		To:    args.To,
+		Value: args.Value,
+		Gas:   simulationGas(args.Gas, cfg.Transactions.SimulationMaxGas),
+	}
+	if args.Data != nil {
+		call.Data = *args.Data
+	}
+
+	overrides, err := stateOverrides(args.StateOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	sim, err := repository.R().SimulateTransaction(&call, args.BlockNumber, overrides)
+	if err != nil {
+		log.Warningf("can not simulate transaction from %s; %s", args.From.String(), err.Error())
+		return nil, err
+	}
+	return &TransactionSimulation{TransactionSimulation: *sim}, nil
+}
+
+// simulationGas caps the gas of the simulated call to the configured max gas;
+// the max gas is used if the caller did not set any. Zero max gas means no cap.
+func simulationGas(gas *hexutil.Uint64, max uint64) *hexutil.Uint64 {
+	if max == 0 || (gas != nil && uint64(*gas) <= max) {
+		return gas
+	}
+	capped := hexutil.Uint64(max)
+	return &capped
+}
+
+// simulationSlots provides the number of simulations allowed to run at once.
+func simulationSlots(max int) int {
+	if max < 1 {
+		return 1
+	}
+	return max
+}
+
+// stateOverrides converts the state overrides input into the per account overrides.
+func stateOverrides(in *[]StateOverrideInput) (map[common.Address]types.StateOverride, error) {
+	if in == nil || len(*in) == 0 {
+		return nil, nil
+	}
+	if len(*in) > simulationMaxOverrides {
+		return nil, fmt.Errorf("too many state overrides, max %d accounts allowed", simulationMaxOverrides)
+	}
+
+	list := make(map[common.Address]types.StateOverride, len(*in))
+	for _, so := range *in {
+		if _, ok := list[so.Address]; ok {
+			return nil, fmt.Errorf("duplicate state override of %s", so.Address.String())
+		}
+		if so.State != nil && so.StateDiff != nil {
+			return nil, fmt.Errorf("state and state diff of %s can not be overridden both", so.Address.String())
+		}
+
+		list[so.Address] = types.StateOverride{
+			Balance:   so.Balance,
+			Nonce:     so.Nonce,
+			Code:      so.Code,
+			State:     storageSlots(so.State),
+			StateDiff: storageSlots(so.StateDiff),
+		}
+	}
+	return list, nil
+}
+
+// storageSlots converts the storage slots input into the slots map.
+func storageSlots(in *[]StorageSlotInput) map[common.Hash]common.Hash {
+	if in == nil {
+		return nil
+	}
+
+	slots := make(map[common.Hash]common.Hash, len(*in))
+	for _, sl := range *in {
+		slots[sl.Key] = sl.Value
+	}
+	return slots
+}
+
+// Success resolves the flag of the transaction executed without failure.
+func (ts *TransactionSimulation) Success() bool {
+	return !ts.Failed
+}
+
+// Error resolves the failure of the transaction, if any.
+func (ts *TransactionSimulation) Error() *string {
+	if !ts.Failed {
+		return nil
+	}
+	return &ts.TransactionSimulation.Error
+}
+
+// RevertReason resolves the decoded revert reason of a reverted transaction.
+func (ts *TransactionSimulation) RevertReason() *string {
+	if ts.TransactionSimulation.RevertReason == "" {
+		return nil
+	}
+	return &ts.TransactionSimulation.RevertReason
+}
+
+// Method resolves the name of the called contract function, if known.
+func (ts *TransactionSimulation) Method() *string {
+	if ts.TransactionSimulation.Method == "" {
+		return nil
+	}
+	return &ts.TransactionSimulation.Method
+}
+
+// ReturnValues resolves the return data decoded by the contract ABI.
+func (ts *TransactionSimulation) ReturnValues() []*DecodedParam {
+	list := make([]*DecodedParam, len(ts.TransactionSimulation.ReturnValues))
+	for i, dp := range ts.TransactionSimulation.ReturnValues {
+		list[i] = &DecodedParam{DecodedParam: dp}
+	}
+	return list
+}
+
+// Logs resolves the event logs emitted by the transaction.
+func (ts *TransactionSimulation) Logs() []*SimulatedLog {
+	list := make([]*SimulatedLog, len(ts.TransactionSimulation.Logs))
+	for i, lg := range ts.TransactionSimulation.Logs {
+		list[i] = &SimulatedLog{SimulationLog: lg}
+	}
+	return list
+}
+
+// BalanceChanges resolves the net balance changes of the accounts involved in the transaction.
+func (ts *TransactionSimulation) BalanceChanges() []*BalanceChange {
+	list := make([]*BalanceChange, len(ts.TransactionSimulation.BalanceChanges))
+	for i, bc := range ts.TransactionSimulation.BalanceChanges {
+		list[i] = &BalanceChange{BalanceChange: bc}
+	}
+	return list
+}
+
+// Event resolves the name of the event decoded by the contract ABI, if known.
+func (sl *SimulatedLog) Event() *string {
+	if sl.SimulationLog.Event == "" {
+		return nil
+	}
+	return &sl.SimulationLog.Event
+}
+
+// Params resolves the event values decoded by the contract ABI.
+func (sl *SimulatedLog) Params() []*DecodedParam {
+	list := make([]*DecodedParam, len(sl.SimulationLog.Params))
+	for i, dp := range sl.SimulationLog.Params {
+		list[i] = &DecodedParam{DecodedParam: dp}
+	}
+	return list
+}
@@ -0,0 +1,66 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// onAccountActivityChannelCapacity is the number of account activity events held in memory for a subscriber.
+const onAccountActivityChannelCapacity = 100
+
+// AccountActivity represents resolvable activity event of an account.
+type AccountActivity struct {
+	types.AccountActivity
+}
+
+// OnAccountActivity resolves subscription to events involving the given account;
+// native transactions, token transfers, staking events and reward claims.
+func (rs *rootResolver) OnAccountActivity(ctx context.Context, args struct{ Address common.Address }) (<-chan *AccountActivity, error) {
+	c := make(chan *AccountActivity, onAccountActivityChannelCapacity)
+
+	// subscribe to the account topic of the broker
+	err := subscribeTopic(ctx, broker.TopicAccountActivity(&args.Address), func(evt interface{}) bool {
+		act, ok := evt.(*types.AccountActivity)
+		if !ok {
+			return true
+		}
+
+		select {
+		case c <- &AccountActivity{AccountActivity: *act}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Transaction resolves the transaction of the activity.
+func (aa *AccountActivity) Transaction() (*Transaction, error) {
+	trx, err := repository.R().Transaction(&aa.AccountActivity.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransaction(trx), nil
+}
+
+// TrxHash resolves the hash of the transaction of the activity.
+func (aa *AccountActivity) TrxHash() common.Hash {
+	return aa.AccountActivity.Transaction
+}
+
+// TokenTransaction resolves the token transfer of the activity, if any.
+func (aa *AccountActivity) TokenTransaction() *TokenTransaction {
+	if aa.AccountActivity.TokenTransaction == nil {
+		return nil
+	}
+	return NewTokenTransaction(aa.AccountActivity.TokenTransaction)
+}
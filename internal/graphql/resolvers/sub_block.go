@@ -3,77 +3,143 @@ package resolvers
 
 import (
 	"context"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/repository"
 	"ncogearthchain-api-graphql/internal/types"
-	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // onBlockChannelCapacity is the number of new block events held in memory for being broadcast to subscriber.
 const onBlockChannelCapacity = 500
 
-// subscriptOnBlock represents reference to a subscriber to onBlock events broadcast.
-type subscriptOnBlock struct {
-	stop   <-chan struct{}
-	events chan<- *Block
-}
+// subscriptionReplayMaxBlocks is the max number of blocks a subscription can replay before switching to live events.
+// The live events are buffered while the replay runs, so the replay must finish before the buffer fills up.
+const subscriptionReplayMaxBlocks = 1000
+
+// errReplayTooDeep is returned if a subscription asks to replay more blocks than allowed.
+var errReplayTooDeep = fmt.Errorf("replay is limited to the most recent %d blocks", subscriptionReplayMaxBlocks)
 
 // OnBlock resolves subscription to new blocks event broadcast.
-func (rs *rootResolver) OnBlock(ctx context.Context) <-chan *Block {
+// If the starting block is given, the blocks since the starting block are replayed
+// first and the subscription switches to live events once the replay reaches the head.
+func (rs *rootResolver) OnBlock(ctx context.Context, args struct{ FromBlock *hexutil.Uint64 }) (<-chan *Block, error) {
+	// check the replay depth before subscribing
+	var top uint64
+	if args.FromBlock != nil {
+		var err error
+		if top, err = replayTop(uint64(*args.FromBlock)); err != nil {
+			return nil, err
+		}
+	}
+
 	// make the stream
 	c := make(chan *Block, onBlockChannelCapacity)
 
-	// subscribe to event dispatch
-	rs.subscribeOnBlock <- &subscriptOnBlock{
-		stop:   ctx.Done(),
-		events: c,
+	// live events go directly to the stream if there is nothing to replay
+	events := c
+	if args.FromBlock != nil {
+		events = make(chan *Block, onBlockChannelCapacity)
+	}
+
+	// subscribe to the blocks topic; we subscribe before the replay starts so no live event is lost
+	err := subscribeTopic(ctx, broker.TopicBlocks, func(evt interface{}) bool {
+		blk, ok := evt.(*types.Block)
+		if !ok {
+			return true
+		}
+
+		select {
+		case events <- NewBlock(blk):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(events) })
+	if err != nil {
+		return nil, err
 	}
-	return c
-}
 
-// addBlockSubscriber adds a new subscription to onBlock events.
-func (rs *rootResolver) addBlockSubscriber(sub *subscriptOnBlock) {
-	id, err := uuid()
-	if err == nil {
-		// add the subscriber to the map
-		rs.blockSubscribers[id] = sub
-	} else {
-		// log critical issue
-		log.Critical("can not generate UUID for new onBlock subscriber")
-		log.Critical(err)
+	if args.FromBlock != nil {
+		go replayBlocks(ctx, uint64(*args.FromBlock), top, events, c)
 	}
+	return c, nil
 }
 
-// dispatchOnBlock dispatches onBlock event to registered subscribers.
-func (rs *rootResolver) dispatchOnBlock(blk *types.Block) {
-	// prep the block
-	block := NewBlock(blk)
+// replayTop provides the head block of the chain a replay from the given block runs up to.
+// Replays starting deeper than the max replay depth are refused.
+func replayTop(from uint64) (uint64, error) {
+	head, err := repository.R().BlockHeight()
+	if err != nil {
+		return 0, err
+	}
 
-	// broadcast the event in separate go routines so we don't block here
-	for id, sub := range rs.blockSubscribers {
-		go rs.notifyOnBlock(block, sub, id)
+	top := head.ToInt().Uint64()
+	if top > subscriptionReplayMaxBlocks && from < top-subscriptionReplayMaxBlocks {
+		return 0, errReplayTooDeep
 	}
+	return top, nil
 }
 
-// notifyOnBlock broadcasts onBlock event to given subscriber.
-func (rs *rootResolver) notifyOnBlock(block *Block, sub *subscriptOnBlock, id string) {
-	// check if the context isn't already closed in which case we just unsub and leave
-	select {
-	case <-sub.stop:
-		rs.unsubscribeOnBlock <- id
+// replayBlocks pushes blocks since the given starting block up to the given head to the output stream
+// and continues with the live blocks once the replay reaches the head of the chain.
+// Live blocks already replayed are skipped, missing blocks between the replay and
+// the first live block are loaded, so the subscriber receives a continuous sequence.
+func replayBlocks(ctx context.Context, next uint64, top uint64, live <-chan *Block, out chan<- *Block) {
+	// the replay owns the output stream
+	defer close(out)
+
+	// replay stored blocks up to the head
+	if !pushBlocksRange(ctx, &next, top+1, out) {
 		return
-	default:
 	}
 
-	// broadcast
-	select {
-	case <-sub.stop:
-		// just unsub on broken context
-		rs.unsubscribeOnBlock <- id
+	// switch to live blocks
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case blk, ok := <-live:
+			if !ok {
+				return
+			}
+
+			num := uint64(blk.Number)
+			if num < next {
+				continue
+			}
+
+			// fill the gap between the replay and the live event, if any
+			if !pushBlocksRange(ctx, &next, num, out) {
+				return
+			}
+
+			select {
+			case out <- blk:
+				next = num + 1
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
 
-	case sub.events <- block:
-		// push the block to subscriber
+// pushBlocksRange loads blocks from the next one up to, but not including, the given end
+// and pushes them to the output stream. The next block number is advanced as the blocks are sent.
+func pushBlocksRange(ctx context.Context, next *uint64, end uint64, out chan<- *Block) bool {
+	for ; *next < end; *next++ {
+		blk, err := repository.R().BlockByNumber((*hexutil.Uint64)(next))
+		if err != nil {
+			log.Errorf("can not replay block #%d; %s", *next, err.Error())
+			return false
+		}
 
-	case <-time.After(time.Second):
-		// timeout reached without response? just remove the subscriber
-		rs.unsubscribeOnBlock <- id
+		select {
+		case out <- NewBlock(blk):
+		case <-ctx.Done():
+			return false
+		}
 	}
+	return true
 }
@@ -0,0 +1,35 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/types"
+)
+
+// onEpochSealedChannelCapacity is the number of sealed epochs held in memory for a subscriber.
+const onEpochSealedChannelCapacity = 5
+
+// OnEpochSealed resolves subscription to epochs as they are sealed and processed.
+func (rs *rootResolver) OnEpochSealed(ctx context.Context) (<-chan *Epoch, error) {
+	c := make(chan *Epoch, onEpochSealedChannelCapacity)
+
+	// subscribe to the sealed epoch topic of the broker
+	err := subscribeTopic(ctx, broker.TopicSealedEpoch, func(evt interface{}) bool {
+		ep, ok := evt.(*types.Epoch)
+		if !ok {
+			return true
+		}
+
+		select {
+		case c <- &Epoch{*ep}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
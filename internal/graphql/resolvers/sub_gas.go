@@ -0,0 +1,47 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+)
+
+// onGasPriceChannelCapacity is the number of gas price estimations held in memory for a subscriber.
+const onGasPriceChannelCapacity = 10
+
+// OnGasPrice resolves subscription to changes of the extended gas price estimation.
+// The current estimation is pushed right away so the subscriber does not need to poll for it.
+func (rs *rootResolver) OnGasPrice(ctx context.Context) (<-chan *GasPriceEstimate, error) {
+	c := make(chan *GasPriceEstimate, onGasPriceChannelCapacity)
+
+	// subscribe first so we don't miss a change made while loading the current value
+	sub, err := broker.B().Subscribe(ctx, broker.TopicGasPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	// push the current estimation
+	gp, err := repository.R().GasPriceExtended()
+	if err != nil {
+		log.Errorf("can not get gas price; %s", err.Error())
+	} else {
+		c <- (*GasPriceEstimate)(gp)
+	}
+
+	go forwardEvents(ctx, sub, func(evt interface{}) bool {
+		gp, ok := evt.(*types.GasPrice)
+		if !ok {
+			return true
+		}
+
+		select {
+		case c <- (*GasPriceEstimate)(gp):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	return c, nil
+}
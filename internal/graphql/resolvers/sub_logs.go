@@ -0,0 +1,122 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// onLogsChannelCapacity is the number of new log events held in memory for being broadcast to subscriber.
+const onLogsChannelCapacity = 500
+
+// onLogsMaxFilterSize is the max number of addresses or topics of a single position in the log filter.
+const onLogsMaxFilterSize = 100
+
+// logsFilter represents the filter of a subscriber to onLogs events broadcast.
+type logsFilter struct {
+	addresses []common.Address
+	topics    [][]common.Hash
+}
+
+// OnLogs resolves subscription to new event logs broadcast. The logs are filtered
+// by the emitting contract addresses and the topics the same way eth_subscribe does.
+// An empty list of addresses matches any contract; topics are matched by position,
+// an empty position matches any topic, multiple topics on a position match any of them.
+func (rs *rootResolver) OnLogs(ctx context.Context, args struct {
+	Addresses *[]common.Address
+	Topics    *[]*[]common.Hash
+}) (<-chan *Log, error) {
+	// collect the filter
+	sub, err := newLogsFilter(args.Addresses, args.Topics)
+	if err != nil {
+		return nil, err
+	}
+
+	// make the stream
+	c := make(chan *Log, onLogsChannelCapacity)
+
+	// subscribe to the logs topic; the broker skips logs not passing the filter
+	err = subscribeTopicFiltered(ctx, broker.TopicLogs, func(evt interface{}) bool {
+		lr, ok := evt.(*types.LogRecord)
+		return ok && sub.matches(lr)
+	}, func(evt interface{}) bool {
+		select {
+		case c <- NewLog(evt.(*types.LogRecord)):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newLogsFilter builds the subscriber logs filter from the subscription arguments.
+// Filters exceeding the max number of addresses, or topics on a position, are refused.
+func newLogsFilter(addresses *[]common.Address, topics *[]*[]common.Hash) (*logsFilter, error) {
+	var sub logsFilter
+	if addresses != nil {
+		if len(*addresses) > onLogsMaxFilterSize {
+			return nil, fmt.Errorf("too many addresses in the filter, max %d allowed", onLogsMaxFilterSize)
+		}
+		sub.addresses = *addresses
+	}
+	if topics != nil {
+		sub.topics = make([][]common.Hash, len(*topics))
+		for i, pos := range *topics {
+			if pos == nil {
+				continue
+			}
+			if len(*pos) > onLogsMaxFilterSize {
+				return nil, fmt.Errorf("too many topics on position %d of the filter, max %d allowed", i, onLogsMaxFilterSize)
+			}
+			sub.topics[i] = *pos
+		}
+	}
+	return &sub, nil
+}
+
+// matches checks if the given log record passes the subscriber filter.
+func (sub *logsFilter) matches(lr *types.LogRecord) bool {
+	if len(sub.addresses) > 0 {
+		found := false
+		for _, adr := range sub.addresses {
+			if bytes.Equal(adr.Bytes(), lr.Address.Bytes()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	// the log must have at least as many topics as the filter
+	if len(sub.topics) > len(lr.Topics) {
+		return false
+	}
+	for i, pos := range sub.topics {
+		if len(pos) == 0 {
+			continue
+		}
+
+		found := false
+		for _, t := range pos {
+			if t == lr.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
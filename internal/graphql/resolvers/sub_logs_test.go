@@ -0,0 +1,103 @@
+package resolvers
+
+import (
+	"testing"
+
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	retypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/onsi/gomega"
+)
+
+var (
+	testLogsContract = common.HexToAddress("0x01")
+	testLogsOther    = common.HexToAddress("0x02")
+	testLogsTopicA   = common.HexToHash("0x0a")
+	testLogsTopicB   = common.HexToHash("0x0b")
+	testLogsTopicC   = common.HexToHash("0x0c")
+)
+
+// testLogRecord builds a log record of the given contract with the given topics.
+func testLogRecord(adr common.Address, topics ...common.Hash) *types.LogRecord {
+	return &types.LogRecord{Log: retypes.Log{Address: adr, Topics: topics}}
+}
+
+func TestLogsFilterMatchesAddress(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// empty filter matches anything
+	all := logsFilter{}
+	g.Expect(all.matches(testLogRecord(testLogsContract))).To(gomega.BeTrue())
+	g.Expect(all.matches(testLogRecord(testLogsOther, testLogsTopicA))).To(gomega.BeTrue())
+
+	// listed addresses only
+	f := logsFilter{addresses: []common.Address{testLogsContract}}
+	g.Expect(f.matches(testLogRecord(testLogsContract, testLogsTopicA))).To(gomega.BeTrue())
+	g.Expect(f.matches(testLogRecord(testLogsOther, testLogsTopicA))).To(gomega.BeFalse())
+}
+
+func TestLogsFilterMatchesTopics(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// topics are matched by position
+	f := logsFilter{topics: [][]common.Hash{{testLogsTopicA}, {testLogsTopicB}}}
+	g.Expect(f.matches(testLogRecord(testLogsContract, testLogsTopicA, testLogsTopicB))).To(gomega.BeTrue())
+	g.Expect(f.matches(testLogRecord(testLogsContract, testLogsTopicA, testLogsTopicB, testLogsTopicC))).To(gomega.BeTrue())
+	g.Expect(f.matches(testLogRecord(testLogsContract, testLogsTopicB, testLogsTopicA))).To(gomega.BeFalse())
+
+	// empty position is a wildcard
+	w := logsFilter{topics: [][]common.Hash{nil, {testLogsTopicB}}}
+	g.Expect(w.matches(testLogRecord(testLogsContract, testLogsTopicC, testLogsTopicB))).To(gomega.BeTrue())
+	g.Expect(w.matches(testLogRecord(testLogsContract, testLogsTopicC, testLogsTopicA))).To(gomega.BeFalse())
+
+	// multiple topics on a position match any of them
+	m := logsFilter{topics: [][]common.Hash{{testLogsTopicA, testLogsTopicC}}}
+	g.Expect(m.matches(testLogRecord(testLogsContract, testLogsTopicC))).To(gomega.BeTrue())
+	g.Expect(m.matches(testLogRecord(testLogsContract, testLogsTopicB))).To(gomega.BeFalse())
+
+	// log with fewer topics than the filter does not match, even on wildcard positions
+	g.Expect(w.matches(testLogRecord(testLogsContract, testLogsTopicC))).To(gomega.BeFalse())
+	g.Expect(m.matches(testLogRecord(testLogsContract))).To(gomega.BeFalse())
+}
+
+func TestLogsFilterMatchesAddressAndTopics(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	f := logsFilter{addresses: []common.Address{testLogsContract}, topics: [][]common.Hash{{testLogsTopicA}}}
+	g.Expect(f.matches(testLogRecord(testLogsContract, testLogsTopicA))).To(gomega.BeTrue())
+	g.Expect(f.matches(testLogRecord(testLogsOther, testLogsTopicA))).To(gomega.BeFalse())
+	g.Expect(f.matches(testLogRecord(testLogsContract, testLogsTopicB))).To(gomega.BeFalse())
+}
+
+func TestNewLogsFilter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// no arguments make an empty filter
+	f, err := newLogsFilter(nil, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(f.addresses).To(gomega.BeEmpty())
+	g.Expect(f.topics).To(gomega.BeEmpty())
+
+	// nil positions are kept as wildcards
+	pos := []common.Hash{testLogsTopicB}
+	f, err = newLogsFilter(nil, &[]*[]common.Hash{nil, &pos})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(f.topics).To(gomega.HaveLen(2))
+	g.Expect(f.topics[0]).To(gomega.BeEmpty())
+	g.Expect(f.topics[1]).To(gomega.Equal(pos))
+
+	// oversized filters are refused instead of truncated
+	adr := make([]common.Address, onLogsMaxFilterSize+1)
+	_, err = newLogsFilter(&adr, nil)
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	tps := make([]common.Hash, onLogsMaxFilterSize+1)
+	_, err = newLogsFilter(nil, &[]*[]common.Hash{nil, &tps})
+	g.Expect(err).ToNot(gomega.BeNil())
+
+	// the limit itself is fine
+	adr = adr[:onLogsMaxFilterSize]
+	_, err = newLogsFilter(&adr, nil)
+	g.Expect(err).To(gomega.BeNil())
+}
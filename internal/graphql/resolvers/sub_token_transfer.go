@@ -0,0 +1,52 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// onTokenTransferChannelCapacity is the number of token transfer events held in memory for a subscriber.
+const onTokenTransferChannelCapacity = 250
+
+// OnTokenTransfer resolves subscription to token transfers as they are processed.
+// The transfers can be filtered by the token contract, by the account sending
+// or receiving the tokens and by the type of the token (ERC20/ERC721/ERC1155).
+func (rs *rootResolver) OnTokenTransfer(ctx context.Context, args struct {
+	Token     *common.Address
+	Account   *common.Address
+	TokenType *string
+}) (<-chan *TokenTransaction, error) {
+	c := make(chan *TokenTransaction, onTokenTransferChannelCapacity)
+
+	// subscribe to the token topic; the broker skips transfers not passing the filter
+	err := subscribeTopicFiltered(ctx, broker.TopicTokenTransfer(args.Token), func(evt interface{}) bool {
+		trx, ok := evt.(*types.TokenTransaction)
+		return ok && tokenTransferMatches(trx, args.Account, args.TokenType)
+	}, func(evt interface{}) bool {
+		select {
+		case c <- NewTokenTransaction(evt.(*types.TokenTransaction)):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// tokenTransferMatches checks if the token transfer passes the account and token type filter.
+func tokenTransferMatches(trx *types.TokenTransaction, acc *common.Address, tokenType *string) bool {
+	if tokenType != nil && trx.TokenType != *tokenType {
+		return false
+	}
+	if acc != nil && trx.Sender != *acc && trx.Recipient != *acc {
+		return false
+	}
+	return true
+}
@@ -3,78 +3,168 @@ package resolvers
 
 import (
 	"context"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/repository"
 	"ncogearthchain-api-graphql/internal/types"
-	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // onTrxChannelCapacity is the number of new transaction events held in memory for being broadcast to subscriber.
 const onTrxChannelCapacity = 500
 
-// subscriptOnTrx represents reference to a subscriber to onTransaction events broadcast.
-type subscriptOnTrx struct {
-	stop   <-chan struct{}
-	events chan<- *Transaction
-}
+// onTrxReplayBatchSize is the number of stored transactions loaded at once during the replay.
+const onTrxReplayBatchSize = 100
+
+// OnTransaction resolves subscription to new transactions event broadcast.
+// If the starting block, or the cursor of the last received transaction is given,
+// the stored transactions since the starting point are replayed first and the subscription
+// switches to live events once the replay reaches the head.
+func (rs *rootResolver) OnTransaction(ctx context.Context, args struct {
+	FromBlock  *hexutil.Uint64
+	FromCursor *Cursor
+}) (<-chan *Transaction, error) {
+	// find the replay start before subscribing; too deep replays are refused
+	var start uint64
+	replay := args.FromBlock != nil || args.FromCursor != nil
+	if replay {
+		var err error
+		if start, err = replayStartOrdinal(args.FromBlock, args.FromCursor); err != nil {
+			return nil, err
+		}
+	}
 
-// OnBlock resolves subscription to new blocks event broadcast.
-func (rs *rootResolver) OnTransaction(ctx context.Context) <-chan *Transaction {
 	// make the stream
 	c := make(chan *Transaction, onTrxChannelCapacity)
 
-	// subscribe to event dispatch
-	rs.subscribeOnTrx <- &subscriptOnTrx{
-		stop:   ctx.Done(),
-		events: c,
+	// live events go directly to the stream if there is nothing to replay
+	events := c
+	if replay {
+		events = make(chan *Transaction, onTrxChannelCapacity)
 	}
 
-	return c
-}
+	// subscribe to the transactions topic; we subscribe before the replay starts so no live event is lost
+	err := subscribeTopic(ctx, broker.TopicTransactions, func(evt interface{}) bool {
+		trx, ok := evt.(*types.Transaction)
+		if !ok {
+			return true
+		}
 
-// addTrxSubscriber adds a new subscription to onTransaction events.
-func (rs *rootResolver) addTrxSubscriber(sub *subscriptOnTrx) {
-	id, err := uuid()
-	if err == nil {
-		// add the subscriber to the map
-		rs.trxSubscribers[id] = sub
-	} else {
-		// log critical issue
-		log.Critical("can not generate UUID for new onTransaction subscriber")
-		log.Critical(err)
+		select {
+		case events <- NewTransaction(trx):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(events) })
+	if err != nil {
+		return nil, err
+	}
+
+	if replay {
+		go replayTransactions(ctx, start, events, c)
 	}
+	return c, nil
 }
 
-// dispatchOnTransaction dispatches onTransaction event to registered subscribers.
-func (rs *rootResolver) dispatchOnTransaction(trx *types.Transaction) {
-	// prep the block
-	transaction := NewTransaction(trx)
+// replayStartOrdinal finds the ordinal index of the first transaction to be replayed.
+// The cursor takes precedence over the starting block; the replay continues right after
+// the transaction of the cursor. Starting points deeper than the max replay depth are refused.
+func replayStartOrdinal(fromBlock *hexutil.Uint64, fromCursor *Cursor) (uint64, error) {
+	var start uint64
+	if fromCursor != nil {
+		hash := common.HexToHash(string(*fromCursor))
+		trx, err := repository.R().Transaction(&hash)
+		if err != nil {
+			return 0, err
+		}
+		if trx.BlockNumber == nil {
+			return 0, repository.ErrTransactionNotFound
+		}
+		start = trx.Uid() + 1
+	} else {
+		start = uint64(*fromBlock) << 14
+	}
 
-	// broadcast the event in separate go routines so we don't block here
-	for id, sub := range rs.trxSubscribers {
-		go rs.notifyOnTransaction(transaction, sub, id)
+	// check the replay depth
+	if _, err := replayTop(start >> 14); err != nil {
+		return 0, err
 	}
+	return start, nil
 }
 
-// notifyOnTransaction broadcasts onBlock event to given subscriber.
-func (rs *rootResolver) notifyOnTransaction(trx *Transaction, sub *subscriptOnTrx, id string) {
-	// check if the context isn't already closed in which case we just unsub and leave
-	select {
-	case <-sub.stop:
-		rs.unsubscribeOnTrx <- id
+// replayTransactions pushes stored transactions since the given starting ordinal index to the output
+// stream and continues with the live transactions once the replay reaches the stored head.
+// Live transactions already replayed are skipped, transactions stored between the replay
+// and the first live transaction are loaded, so the subscriber receives a continuous sequence.
+func replayTransactions(ctx context.Context, next uint64, live <-chan *Transaction, out chan<- *Transaction) {
+	// the replay owns the output stream
+	defer close(out)
+
+	// replay stored transactions
+	if !pushTransactionsRange(ctx, &next, nil, out) {
 		return
-	default:
 	}
 
-	// broadcast
-	select {
-	case <-sub.stop:
-		// just unsub on broken context
-		rs.unsubscribeOnTrx <- id
+	// switch to live transactions
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case trx, ok := <-live:
+			if !ok {
+				return
+			}
+
+			uid := trx.Uid()
+			if uid < next {
+				continue
+			}
+
+			// fill the gap between the replay and the live event, if any
+			if !pushTransactionsRange(ctx, &next, &uid, out) {
+				return
+			}
+
+			select {
+			case out <- trx:
+				next = uid + 1
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// pushTransactionsRange loads stored transactions from the next ordinal index up to, but not including,
+// the given end and pushes them to the output stream. All the stored transactions are pushed
+// if the end is not given. The next ordinal index is advanced as the transactions are sent.
+func pushTransactionsRange(ctx context.Context, next *uint64, end *uint64, out chan<- *Transaction) bool {
+	for {
+		list, err := repository.R().TransactionsFrom(*next, onTrxReplayBatchSize)
+		if err != nil {
+			log.Errorf("can not replay transactions from #%d; %s", *next, err.Error())
+			return false
+		}
+
+		for _, trx := range list {
+			uid := trx.Uid()
+			if end != nil && uid >= *end {
+				return true
+			}
 
-	case sub.events <- trx:
-		// push the block to subscriber
+			select {
+			case out <- NewTransaction(trx):
+				*next = uid + 1
+			case <-ctx.Done():
+				return false
+			}
+		}
 
-	case <-time.After(time.Second):
-		// timeout reached without response? just remove the subscriber
-		rs.unsubscribeOnTrx <- id
+		// the last batch reached the stored head
+		if len(list) < onTrxReplayBatchSize {
+			return true
+		}
 	}
 }
@@ -0,0 +1,111 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"context"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// onUniswapChannelCapacity is the number of Uniswap pair events held in memory for a subscriber.
+const onUniswapChannelCapacity = 100
+
+// UniswapPairPrice represents resolvable Uniswap pair price change.
+type UniswapPairPrice struct {
+	types.UniswapPairPrice
+}
+
+// OnUniswapSwap resolves subscription to swaps on the given Uniswap pair as they are processed.
+func (rs *rootResolver) OnUniswapSwap(ctx context.Context, args struct{ Pair common.Address }) (<-chan *UniswapAction, error) {
+	c := make(chan *UniswapAction, onUniswapChannelCapacity)
+	pair := NewUniswapPair(&args.Pair)
+
+	err := subscribeTopic(ctx, broker.TopicUniswapSwap(&args.Pair), func(evt interface{}) bool {
+		swap, ok := evt.(*types.Swap)
+		if !ok {
+			return true
+		}
+
+		select {
+		case c <- NewUniswapAction(uniswapSwapAction(swap), pair):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// OnPairPrice resolves subscription to the price changes of the given Uniswap pair
+// derived from the pair reserves as they are updated.
+func (rs *rootResolver) OnPairPrice(ctx context.Context, args struct{ Pair common.Address }) (<-chan *UniswapPairPrice, error) {
+	c := make(chan *UniswapPairPrice, onUniswapChannelCapacity)
+
+	err := subscribeTopic(ctx, broker.TopicUniswapPairPrice(&args.Pair), func(evt interface{}) bool {
+		pp, ok := evt.(*types.UniswapPairPrice)
+		if !ok {
+			return true
+		}
+
+		select {
+		case c <- &UniswapPairPrice{*pp}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// uniswapSwapAction converts the processed swap into the Uniswap action structure.
+func uniswapSwapAction(swap *types.Swap) *types.UniswapAction {
+	return &types.UniswapAction{
+		ID:              swap.Id(),
+		OrdIndex:        swap.OrdIndex,
+		BlockNr:         *swap.BlockNumber,
+		Type:            int32(swap.Type),
+		PairAddress:     swap.Pair,
+		Sender:          swap.Sender,
+		TransactionHash: swap.Hash,
+		Time:            *swap.TimeStamp,
+		Amount0in:       hexutil.Big(*swap.Amount0In),
+		Amount0out:      hexutil.Big(*swap.Amount0Out),
+		Amount1in:       hexutil.Big(*swap.Amount1In),
+		Amount1out:      hexutil.Big(*swap.Amount1Out),
+	}
+}
+
+// UniswapPair resolves the Uniswap pair of the price change.
+func (pp *UniswapPairPrice) UniswapPair() *UniswapPair {
+	return NewUniswapPair(&pp.PairAddress)
+}
+
+// Price resolves the price of the pair given by the reserves ratio. The direction
+// follows the defiTimePrices convention; 0 means the first token reserve
+// divided by the second one, any other value means the opposite.
+func (pp *UniswapPairPrice) Price(args struct{ Direction int32 }) float64 {
+	if len(pp.Reserves) != 2 {
+		return 0
+	}
+
+	num, den := pp.Reserves[0].ToInt(), pp.Reserves[1].ToInt()
+	if args.Direction != 0 {
+		num, den = den, num
+	}
+	if den.Sign() == 0 {
+		return 0
+	}
+
+	val, _ := new(big.Float).Quo(new(big.Float).SetInt(num), new(big.Float).SetInt(den)).Float64()
+	return val
+}
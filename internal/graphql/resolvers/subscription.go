@@ -0,0 +1,58 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/broker"
+)
+
+// subscribeTopic subscribes to the given broker topic on behalf of the subscription context
+// and feeds the received events to the push callback until the context is closed, the broker
+// terminates the subscription, or the push callback refuses to continue. The done callback
+// is called on exit so the caller can close its output stream.
+func subscribeTopic(ctx context.Context, topic string, push func(interface{}) bool, done func()) error {
+	sub, err := broker.B().Subscribe(ctx, topic)
+	if err != nil {
+		log.Debugf("subscription to %s refused; %s", topic, err.Error())
+		return err
+	}
+
+	go forwardEvents(ctx, sub, push, done)
+	return nil
+}
+
+// subscribeTopicFiltered subscribes to the broker topic receiving only the events accepted by the filter.
+// The filter is applied by the broker on publishing, so the skipped events never reach the subscriber queue.
+func subscribeTopicFiltered(ctx context.Context, topic string, filter broker.Filter, push func(interface{}) bool, done func()) error {
+	sub, err := broker.B().SubscribeFiltered(ctx, topic, filter)
+	if err != nil {
+		log.Debugf("subscription to %s refused; %s", topic, err.Error())
+		return err
+	}
+
+	go forwardEvents(ctx, sub, push, done)
+	return nil
+}
+
+// forwardEvents feeds the events of the broker subscription to the push callback.
+// The subscription is closed on exit and the done callback is called.
+func forwardEvents(ctx context.Context, sub *broker.Subscription, push func(interface{}) bool, done func()) {
+	defer func() {
+		sub.Close()
+		if sub.Disconnected() {
+			log.Debugf("subscriber of %s disconnected after %d dropped events", sub.Topic(), sub.Dropped())
+		}
+		done()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-sub.Events():
+			if !ok || !push(evt) {
+				return
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@ package resolvers
 import (
 	"fmt"
 	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/svc"
 	"ncogearthchain-api-graphql/internal/types"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -51,14 +52,32 @@ func (rs *rootResolver) Transaction(args *struct{ Hash common.Hash }) (tx *Trans
 }
 
 // SendTransaction sends raw signed and RLP encoded transaction to the blockchain.
-func (rs *rootResolver) SendTransaction(args *struct{ Tx hexutil.Bytes }) (*Transaction, error) {
+// The transaction is validated against the chain state first, if the pre-flight validation
+// is enabled, or the dry run is requested.
+func (rs *rootResolver) SendTransaction(args *struct {
+	Tx     hexutil.Bytes
+	DryRun *bool
+}) (*Transaction, error) {
+	dryRun := args.DryRun != nil && *args.DryRun
+	if cfg.Transactions.Preflight || dryRun {
+		if err := preflight(args.Tx, dryRun); err != nil {
+			log.Warningf("transaction refused; %s", err.Error())
+			return nil, err
+		}
+	}
+
 	// get the transaction from repository
 	trx, err := repository.R().SendTransaction(args.Tx)
 	if err != nil {
 		log.Warningf("can not send transaction; %s", err.Error())
-		return nil, err
+		if err == repository.ErrTransactionNotFound {
+			return nil, err
+		}
+		return nil, newTransactionError(TrxErrRejected, nil, "%s", err.Error())
 	}
 
+	// follow the transaction lifecycle
+	svc.Manager().TrackTransaction(trx)
 	return NewTransaction(trx), nil
 }
 
@@ -0,0 +1,257 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"fmt"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	etc "github.com/ethereum/go-ethereum/core/types"
+)
+
+// transaction pre-flight validation error codes
+const (
+	TrxErrInvalidEncoding        = "INVALID_ENCODING"
+	TrxErrWrongChainId           = "WRONG_CHAIN_ID"
+	TrxErrInvalidSignature       = "INVALID_SIGNATURE"
+	TrxErrIntrinsicGasTooLow     = "INTRINSIC_GAS_TOO_LOW"
+	TrxErrGasPriceTooLow         = "GAS_PRICE_TOO_LOW"
+	TrxErrNonceTooLow            = "NONCE_TOO_LOW"
+	TrxErrAlreadyKnown           = "ALREADY_KNOWN"
+	TrxErrReplacementUnderpriced = "REPLACEMENT_UNDERPRICED"
+	TrxErrInsufficientFunds      = "INSUFFICIENT_FUNDS"
+	TrxErrExecutionReverted      = "EXECUTION_REVERTED"
+	TrxErrExecutionFailed        = "EXECUTION_FAILED"
+	TrxErrRejected               = "REJECTED"
+)
+
+// trxReplacementPriceBump represents the min gas price increase in percents required
+// by the node pool to replace a pending transaction of the same sender and nonce.
+const trxReplacementPriceBump = 10
+
+// TransactionError represents a transaction refused by the pre-flight validation, or by the node.
+// The code and the details of the error are provided in the GraphQL error extensions.
+type TransactionError struct {
+	Code    string
+	Message string
+	Details map[string]interface{}
+}
+
+// Error returns the message of the transaction error.
+func (e *TransactionError) Error() string {
+	return e.Message
+}
+
+// Extensions provides the code and the details of the error to the GraphQL response.
+func (e *TransactionError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.Code}
+	for k, v := range e.Details {
+		ext[k] = v
+	}
+	return ext
+}
+
+// newTransactionError creates a new transaction error of the given code.
+func newTransactionError(code string, details map[string]interface{}, format string, args ...interface{}) *TransactionError {
+	return &TransactionError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Details: details,
+	}
+}
+
+// preflight decodes the raw signed transaction and validates it against the current chain state
+// before it's sent to the node. If the dry run is requested, the transaction is also executed
+// against the pending state to find out if it reverts. A check is skipped if the chain state
+// it needs is not available; the node validates the transaction anyway.
+func preflight(raw hexutil.Bytes, dryRun bool) error {
+	tx, err := preflightDecode(raw)
+	if err != nil {
+		return err
+	}
+
+	from, err := preflightSender(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, check := range []func(*etc.Transaction, *common.Address) error{
+		preflightIntrinsicGas,
+		preflightGasPrice,
+		preflightNonce,
+		preflightBalance,
+	} {
+		if err := check(tx, from); err != nil {
+			return err
+		}
+	}
+
+	if dryRun {
+		return preflightDryRun(tx, from)
+	}
+	return nil
+}
+
+// preflightDecode decodes the raw signed transaction.
+func preflightDecode(raw hexutil.Bytes) (*etc.Transaction, error) {
+	var tx etc.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, newTransactionError(TrxErrInvalidEncoding, nil, "transaction can not be decoded; %s", err.Error())
+	}
+	return &tx, nil
+}
+
+// preflightSender checks the chain id of the transaction and recovers the sender from the signature.
+func preflightSender(tx *etc.Transaction) (*common.Address, error) {
+	chainID, err := repository.R().ChainID()
+	if err != nil {
+		log.Warningf("chain id check skipped; %s", err.Error())
+		chainID = tx.ChainId()
+	}
+	return trxSender(tx, chainID)
+}
+
+// trxSender checks the transaction against the given chain id and recovers the sender from the signature.
+func trxSender(tx *etc.Transaction, chainID *big.Int) (*common.Address, error) {
+	if tx.Protected() && tx.ChainId().Cmp(chainID) != 0 {
+		return nil, newTransactionError(TrxErrWrongChainId, map[string]interface{}{
+			"expected": (*hexutil.Big)(chainID),
+			"received": (*hexutil.Big)(tx.ChainId()),
+		}, "transaction chain id %s does not match the chain id %s", tx.ChainId().String(), chainID.String())
+	}
+
+	from, err := etc.Sender(etc.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return nil, newTransactionError(TrxErrInvalidSignature, nil, "transaction sender can not be recovered; %s", err.Error())
+	}
+	return &from, nil
+}
+
+// preflightIntrinsicGas checks the transaction gas covers the intrinsic gas of the transaction,
+// i.e. the gas paid for the transaction itself, its data, and its access list.
+func preflightIntrinsicGas(tx *etc.Transaction, _ *common.Address) error {
+	gas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, true)
+	if err != nil {
+		return newTransactionError(TrxErrIntrinsicGasTooLow, nil, "intrinsic gas can not be calculated; %s", err.Error())
+	}
+
+	if tx.Gas() < gas {
+		return newTransactionError(TrxErrIntrinsicGasTooLow, map[string]interface{}{
+			"required": hexutil.Uint64(gas),
+			"provided": hexutil.Uint64(tx.Gas()),
+		}, "transaction gas %d is below the intrinsic gas %d", tx.Gas(), gas)
+	}
+	return nil
+}
+
+// preflightGasPrice checks the transaction gas price against the configured min gas price.
+// The check is skipped if no min gas price is configured; the suggested gas price is not a floor,
+// transactions priced below it are still accepted by the node.
+func preflightGasPrice(tx *etc.Transaction, _ *common.Address) error {
+	if cfg.Transactions.MinGasPrice == 0 {
+		return nil
+	}
+	floor := new(big.Int).SetUint64(cfg.Transactions.MinGasPrice)
+
+	// the fee cap is the gas price of legacy transactions
+	if tx.GasFeeCap().Cmp(floor) < 0 {
+		return newTransactionError(TrxErrGasPriceTooLow, map[string]interface{}{
+			"required": (*hexutil.Big)(floor),
+			"provided": (*hexutil.Big)(tx.GasFeeCap()),
+		}, "transaction gas price %s is below the min gas price %s", tx.GasFeeCap().String(), floor.String())
+	}
+	return nil
+}
+
+// preflightNonce checks the transaction nonce against the account nonce and the transactions
+// of the sender waiting in the node pool. A pending transaction of the same nonce can be
+// replaced only by a transaction of a sufficiently higher gas price. The pool is read
+// directly from the node, a transaction sent a moment ago must be known to the check.
+func preflightNonce(tx *etc.Transaction, from *common.Address) error {
+	an, err := repository.R().AccountNonce(from)
+	if err != nil {
+		log.Warningf("nonce check skipped; %s", err.Error())
+		return nil
+	}
+
+	pool, err := repository.R().PoolTransactionsFrom(from)
+	if err != nil {
+		log.Warningf("pending nonce check skipped; %s", err.Error())
+		pool = nil
+	}
+	return trxNonceCheck(tx, from, uint64(*an), pool)
+}
+
+// trxNonceCheck checks the transaction nonce against the account nonce and the given transactions
+// waiting in the node pool; transactions of other senders in the list are ignored.
+// The pool checks are skipped if the pool content is not known. A nonce leaving a gap
+// is accepted, the node keeps such a transaction queued until the gap is filled.
+func trxNonceCheck(tx *etc.Transaction, from *common.Address, an uint64, pool []*types.Transaction) error {
+	if tx.Nonce() < an {
+		return newTransactionError(TrxErrNonceTooLow, map[string]interface{}{
+			"expected": hexutil.Uint64(an),
+			"provided": hexutil.Uint64(tx.Nonce()),
+		}, "transaction nonce %d is too low, the account nonce is %d", tx.Nonce(), an)
+	}
+	if pool == nil {
+		return nil
+	}
+
+	for _, pt := range pool {
+		if pt.From != *from || uint64(pt.Nonce) != tx.Nonce() {
+			continue
+		}
+
+		if pt.Hash == tx.Hash() {
+			return newTransactionError(TrxErrAlreadyKnown, nil, "transaction %s is already pending", tx.Hash().String())
+		}
+
+		bump := new(big.Int).Mul(pt.GasPrice.ToInt(), big.NewInt(100+trxReplacementPriceBump))
+		bump.Div(bump, big.NewInt(100))
+		if tx.GasFeeCap().Cmp(bump) < 0 {
+			return newTransactionError(TrxErrReplacementUnderpriced, map[string]interface{}{
+				"pending":  pt.Hash,
+				"required": (*hexutil.Big)(bump),
+				"provided": (*hexutil.Big)(tx.GasFeeCap()),
+			}, "replacement of pending transaction %s requires gas price of at least %s", pt.Hash.String(), bump.String())
+		}
+	}
+	return nil
+}
+
+// preflightBalance checks the sender balance covers the transaction value and the max gas fee.
+func preflightBalance(tx *etc.Transaction, from *common.Address) error {
+	bal, err := repository.R().AccountBalance(from)
+	if err != nil {
+		log.Warningf("balance check skipped; %s", err.Error())
+		return nil
+	}
+
+	if bal.ToInt().Cmp(tx.Cost()) < 0 {
+		return newTransactionError(TrxErrInsufficientFunds, map[string]interface{}{
+			"balance":  bal,
+			"required": (*hexutil.Big)(tx.Cost()),
+		}, "balance %s of %s does not cover the transaction cost %s", bal.ToInt().String(), from.String(), tx.Cost().String())
+	}
+	return nil
+}
+
+// preflightDryRun executes the transaction against the pending state to find out if it reverts.
+func preflightDryRun(tx *etc.Transaction, from *common.Address) error {
+	err := repository.R().DryRunTransaction(tx, from)
+	if err == nil {
+		return nil
+	}
+
+	if rev, ok := err.(*repository.TransactionRevert); ok {
+		return newTransactionError(TrxErrExecutionReverted, map[string]interface{}{
+			"reason": rev.Reason,
+			"data":   rev.Data,
+		}, "%s", rev.Error())
+	}
+	return newTransactionError(TrxErrExecutionFailed, nil, "transaction execution failed; %s", err.Error())
+}
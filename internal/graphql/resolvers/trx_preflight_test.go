@@ -0,0 +1,172 @@
+package resolvers
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	etc "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/onsi/gomega"
+)
+
+var testChainID = big.NewInt(4002)
+
+// testSignedTrx creates a legacy transfer of the given nonce and gas price signed by the key for the chain.
+func testSignedTrx(t *testing.T, key *ecdsa.PrivateKey, chainID *big.Int, nonce uint64, gasPrice int64) *etc.Transaction {
+	to := common.HexToAddress("0x01")
+	tx, err := etc.SignTx(etc.NewTx(&etc.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: big.NewInt(gasPrice),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(1),
+	}), etc.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		t.Fatalf("can not sign transaction; %s", err.Error())
+	}
+	return tx
+}
+
+// testPendingTrx creates a transaction waiting in the node pool.
+func testPendingTrx(from common.Address, nonce uint64, gasPrice int64, hash common.Hash) *types.Transaction {
+	return &types.Transaction{From: from, Nonce: hexutil.Uint64(nonce), GasPrice: hexutil.Big(*big.NewInt(gasPrice)), Hash: hash}
+}
+
+// testTrxErrorCode extracts the code of the transaction error.
+func testTrxErrorCode(err error) string {
+	if te, ok := err.(*TransactionError); ok {
+		return te.Code
+	}
+	return ""
+}
+
+func TestPreflightDecode(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	key, err := crypto.GenerateKey()
+	g.Expect(err).To(gomega.BeNil())
+
+	tx := testSignedTrx(t, key, testChainID, 5, 1000)
+	raw, err := tx.MarshalBinary()
+	g.Expect(err).To(gomega.BeNil())
+
+	dec, err := preflightDecode(raw)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(dec.Hash()).To(gomega.Equal(tx.Hash()))
+	g.Expect(dec.Nonce()).To(gomega.Equal(uint64(5)))
+
+	// broken encoding is refused
+	_, err = preflightDecode(raw[:len(raw)-3])
+	g.Expect(testTrxErrorCode(err)).To(gomega.Equal(TrxErrInvalidEncoding))
+	_, err = preflightDecode(hexutil.Bytes{})
+	g.Expect(testTrxErrorCode(err)).To(gomega.Equal(TrxErrInvalidEncoding))
+}
+
+func TestTrxSender(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	key, err := crypto.GenerateKey()
+	g.Expect(err).To(gomega.BeNil())
+
+	// the sender is recovered from the signature
+	from, err := trxSender(testSignedTrx(t, key, testChainID, 0, 1000), testChainID)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(*from).To(gomega.Equal(crypto.PubkeyToAddress(key.PublicKey)))
+
+	// transactions of other chains are refused
+	_, err = trxSender(testSignedTrx(t, key, big.NewInt(1), 0, 1000), testChainID)
+	g.Expect(testTrxErrorCode(err)).To(gomega.Equal(TrxErrWrongChainId))
+}
+
+func TestPreflightIntrinsicGas(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	to := common.HexToAddress("0x01")
+
+	// a plain transfer costs the base transaction gas
+	tx := etc.NewTx(&etc.LegacyTx{Gas: 21000, To: &to})
+	g.Expect(preflightIntrinsicGas(tx, nil)).To(gomega.BeNil())
+
+	// the data is paid per byte, zero bytes are cheaper
+	tx = etc.NewTx(&etc.LegacyTx{Gas: 21000, To: &to, Data: []byte{1, 0}})
+	err := preflightIntrinsicGas(tx, nil)
+	g.Expect(testTrxErrorCode(err)).To(gomega.Equal(TrxErrIntrinsicGasTooLow))
+	g.Expect(err.(*TransactionError).Details["required"]).To(gomega.Equal(hexutil.Uint64(21000 + 16 + 4)))
+
+	// a contract creation costs more
+	tx = etc.NewTx(&etc.LegacyTx{Gas: 21000})
+	err = preflightIntrinsicGas(tx, nil)
+	g.Expect(testTrxErrorCode(err)).To(gomega.Equal(TrxErrIntrinsicGasTooLow))
+	g.Expect(err.(*TransactionError).Details["required"]).To(gomega.Equal(hexutil.Uint64(53000)))
+}
+
+func TestPreflightGasPrice(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	key, err := crypto.GenerateKey()
+	g.Expect(err).To(gomega.BeNil())
+
+	old := cfg
+	defer func() { cfg = old }()
+
+	// no floor is applied unless configured
+	cfg = &config.Config{}
+	g.Expect(preflightGasPrice(testSignedTrx(t, key, testChainID, 0, 1), nil)).To(gomega.BeNil())
+
+	cfg = &config.Config{Transactions: config.Transactions{MinGasPrice: 1000}}
+	g.Expect(preflightGasPrice(testSignedTrx(t, key, testChainID, 0, 1000), nil)).To(gomega.BeNil())
+	g.Expect(testTrxErrorCode(preflightGasPrice(testSignedTrx(t, key, testChainID, 0, 999), nil))).To(gomega.Equal(TrxErrGasPriceTooLow))
+}
+
+func TestTrxNonceCheck(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	key, err := crypto.GenerateKey()
+	g.Expect(err).To(gomega.BeNil())
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	other := common.HexToAddress("0x02")
+
+	// nonce below the account nonce
+	err = trxNonceCheck(testSignedTrx(t, key, testChainID, 4, 1000), &from, 5, nil)
+	g.Expect(testTrxErrorCode(err)).To(gomega.Equal(TrxErrNonceTooLow))
+
+	// the account nonce is fine, any higher nonce is fine if the pool is not known
+	g.Expect(trxNonceCheck(testSignedTrx(t, key, testChainID, 5, 1000), &from, 5, nil)).To(gomega.BeNil())
+	g.Expect(trxNonceCheck(testSignedTrx(t, key, testChainID, 9, 1000), &from, 5, nil)).To(gomega.BeNil())
+
+	// nonces following the pending transactions are fine, other senders are ignored
+	pool := []*types.Transaction{
+		testPendingTrx(from, 6, 1000, common.HexToHash("0x06")),
+		testPendingTrx(from, 5, 1000, common.HexToHash("0x05")),
+		testPendingTrx(other, 7, 1000, common.HexToHash("0x07")),
+	}
+	g.Expect(trxNonceCheck(testSignedTrx(t, key, testChainID, 7, 1000), &from, 5, pool)).To(gomega.BeNil())
+
+	// a nonce leaving a gap is accepted, the node queues the transaction
+	g.Expect(trxNonceCheck(testSignedTrx(t, key, testChainID, 9, 1000), &from, 5, pool)).To(gomega.BeNil())
+	gapped := []*types.Transaction{testPendingTrx(from, 7, 1000, common.HexToHash("0x07"))}
+	g.Expect(trxNonceCheck(testSignedTrx(t, key, testChainID, 6, 1000), &from, 5, gapped)).To(gomega.BeNil())
+	g.Expect(trxNonceCheck(testSignedTrx(t, key, testChainID, 5, 1000), &from, 5, gapped)).To(gomega.BeNil())
+}
+
+func TestTrxNonceCheckReplacement(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	key, err := crypto.GenerateKey()
+	g.Expect(err).To(gomega.BeNil())
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	tx := testSignedTrx(t, key, testChainID, 5, 1000)
+	pool := []*types.Transaction{testPendingTrx(from, 5, 1000, tx.Hash())}
+
+	// the same transaction is already pending
+	err = trxNonceCheck(tx, &from, 5, pool)
+	g.Expect(testTrxErrorCode(err)).To(gomega.Equal(TrxErrAlreadyKnown))
+
+	// a replacement must bump the gas price
+	err = trxNonceCheck(testSignedTrx(t, key, testChainID, 5, 1099), &from, 5, pool)
+	g.Expect(testTrxErrorCode(err)).To(gomega.Equal(TrxErrReplacementUnderpriced))
+	g.Expect(err.(*TransactionError).Details["required"]).To(gomega.Equal((*hexutil.Big)(big.NewInt(1100))))
+
+	g.Expect(trxNonceCheck(testSignedTrx(t, key, testChainID, 5, 1100), &from, 5, pool)).To(gomega.BeNil())
+}
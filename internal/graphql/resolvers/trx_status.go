@@ -0,0 +1,86 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/svc"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// onTrxStatusChannelCapacity is the number of transaction state changes held in memory for a subscriber.
+const onTrxStatusChannelCapacity = 25
+
+// TransactionStatus represents resolvable lifecycle state of a submitted transaction.
+type TransactionStatus struct {
+	types.TransactionStatus
+}
+
+// TransactionStatus resolves the lifecycle state of a transaction submitted through the API.
+// Transactions submitted elsewhere, or no longer tracked, resolve to nil.
+func (rs *rootResolver) TransactionStatus(args struct{ Hash common.Hash }) *TransactionStatus {
+	ts := svc.Manager().TransactionStatus(&args.Hash)
+	if ts == nil {
+		return nil
+	}
+	return &TransactionStatus{TransactionStatus: *ts}
+}
+
+// OnTransactionStatus resolves subscription to lifecycle changes of a transaction submitted
+// through the API. The current state is sent right after the subscription is made,
+// the stream is closed once the transaction reaches its final state.
+func (rs *rootResolver) OnTransactionStatus(ctx context.Context, args struct{ Hash common.Hash }) (<-chan *TransactionStatus, error) {
+	// subscribe first so we don't miss a change made while loading the current state
+	sub, err := broker.B().Subscribe(ctx, broker.TopicTransactionStatus(&args.Hash))
+	if err != nil {
+		return nil, err
+	}
+
+	ts := svc.Manager().TransactionStatus(&args.Hash)
+	if ts == nil {
+		sub.Close()
+		return nil, fmt.Errorf("transaction %s is not tracked", args.Hash.String())
+	}
+
+	c := make(chan *TransactionStatus, onTrxStatusChannelCapacity)
+	c <- &TransactionStatus{TransactionStatus: *ts}
+	if ts.Final {
+		sub.Close()
+		close(c)
+		return c, nil
+	}
+
+	go forwardEvents(ctx, sub, func(evt interface{}) bool {
+		ts, ok := evt.(*types.TransactionStatus)
+		if !ok {
+			return true
+		}
+
+		select {
+		case c <- &TransactionStatus{TransactionStatus: *ts}:
+			return !ts.Final
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	return c, nil
+}
+
+// Confirmations resolves the number of blocks since the transaction was mined.
+func (ts *TransactionStatus) Confirmations() int32 {
+	return int32(ts.TransactionStatus.Confirmations)
+}
+
+// Submitted resolves the time the transaction was submitted.
+func (ts *TransactionStatus) Submitted() graphql.Time {
+	return graphql.Time{Time: ts.TransactionStatus.Submitted}
+}
+
+// Updated resolves the time of the last change of the transaction state.
+func (ts *TransactionStatus) Updated() graphql.Time {
+	return graphql.Time{Time: ts.TransactionStatus.Updated}
+}
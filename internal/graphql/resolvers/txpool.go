@@ -0,0 +1,64 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// onPendingTrxChannelCapacity is the number of pending transaction events held in memory for a subscriber.
+const onPendingTrxChannelCapacity = 250
+
+// TxPoolStatus resolves the number of transactions waiting in the pool of the connected node.
+func (rs *rootResolver) TxPoolStatus() (types.TxPoolStatus, error) {
+	st, err := repository.R().TxPoolStatus()
+	if err != nil {
+		return types.TxPoolStatus{}, err
+	}
+	return *st, nil
+}
+
+// PendingTransactions resolves the list of transactions waiting in the pool
+// of the connected node sent from, or to the given address.
+func (rs *rootResolver) PendingTransactions(args struct{ Address common.Address }) ([]*Transaction, error) {
+	tl, err := repository.R().PendingTransactions(&args.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Transaction, len(tl))
+	for i, trx := range tl {
+		list[i] = NewTransaction(trx)
+	}
+	return list, nil
+}
+
+// OnPendingTransaction resolves subscription to new transactions entering the pool
+// of the connected node, optionally filtered by the sender and the recipient.
+func (rs *rootResolver) OnPendingTransaction(ctx context.Context, args struct {
+	From *common.Address
+	To   *common.Address
+}) (<-chan *Transaction, error) {
+	c := make(chan *Transaction, onPendingTrxChannelCapacity)
+
+	// subscribe to the pending topic; the broker skips transactions not passing the filter
+	err := subscribeTopicFiltered(ctx, broker.TopicPendingTransactions, func(evt interface{}) bool {
+		trx, ok := evt.(*types.Transaction)
+		return ok && (args.From == nil || trx.From == *args.From) && (args.To == nil || (trx.To != nil && *trx.To == *args.To))
+	}, func(evt interface{}) bool {
+		select {
+		case c <- NewTransaction(evt.(*types.Transaction)):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -46,19 +46,28 @@ func NewUniswapPair(adr *common.Address) *UniswapPair {
 }
 
 // defiUniswapPairs load list of Uniswap pairs once in concurrent threads.
-func (rs *rootResolver) defiUniswapPairs() []*UniswapPair {
+// The list can be limited to pairs featured by the configured white list.
+func (rs *rootResolver) defiUniswapPairs(featuredOnly bool) []*UniswapPair {
+	key := "uniswap-pairs"
+	if featuredOnly {
+		key = "uniswap-pairs-featured"
+	}
+
 	// make sure to do this only once
-	list, err, _ := rs.cg.Do("uniswap-pairs", func() (interface{}, error) {
+	list, err, _ := rs.cg.Do(key, func() (interface{}, error) {
 		// get the list of pair addresses
-		pairs, err := repository.R().UniswapKnownPairs()
+		pairs, err := repository.R().UniswapPairs()
 		if err != nil || pairs == nil {
 			return make([]*UniswapPair, 0), nil
 		}
 
 		// build the output list
-		list := make([]*UniswapPair, len(pairs))
-		for i, adr := range pairs {
-			list[i] = NewUniswapPair(&adr)
+		list := make([]*UniswapPair, 0, len(pairs))
+		for i := range pairs {
+			if featuredOnly && !repository.R().UniswapIsFeaturedPair(&pairs[i]) {
+				continue
+			}
+			list = append(list, NewUniswapPair(&pairs[i]))
 		}
 		return list, nil
 	})
@@ -68,9 +77,9 @@ func (rs *rootResolver) defiUniswapPairs() []*UniswapPair {
 	return list.([]*UniswapPair)
 }
 
-// DefiUniswapPairs resolves list of
-func (rs *rootResolver) DefiUniswapPairs() []*UniswapPair {
-	return rs.defiUniswapPairs()
+// DefiUniswapPairs resolves list of Uniswap pairs, optionally only the featured ones.
+func (rs *rootResolver) DefiUniswapPairs(args *struct{ FeaturedOnly bool }) []*UniswapPair {
+	return rs.defiUniswapPairs(args.FeaturedOnly)
 }
 
 // DefiUniswapAmountsOut resolves a list of output amounts for the given
@@ -207,7 +216,7 @@ func (up *UniswapPair) Tokens() ([]*ERC20Token, error) {
 // DefiUniswapVolumes returns all swap pairs and their information for swap volumes
 func (rs *rootResolver) DefiUniswapVolumes() []*UniswapPairVolume {
 	// get all the pairs
-	pairs := rs.defiUniswapPairs()
+	pairs := rs.defiUniswapPairs(false)
 
 	// create empty list as a result object
 	list := make([]*UniswapPairVolume, len(pairs))
@@ -389,6 +398,29 @@ func (up *UniswapPair) LastKValue() (hexutil.Big, error) {
 	return repository.R().UniswapLastKValue(&up.PairAddress)
 }
 
+// Featured resolves the flag of the pair being featured by the API configuration.
+func (up *UniswapPair) Featured() bool {
+	return repository.R().UniswapIsFeaturedPair(&up.PairAddress)
+}
+
+// CreatedBlock resolves the number of the block where the pair was created, if known.
+func (up *UniswapPair) CreatedBlock() (*hexutil.Uint64, error) {
+	pi, err := repository.R().UniswapPairInfo(&up.PairAddress)
+	if err != nil || pi == nil {
+		return nil, err
+	}
+	return &pi.Block, nil
+}
+
+// CreatedTimeStamp resolves the time stamp of the pair creation, if known.
+func (up *UniswapPair) CreatedTimeStamp() (*hexutil.Uint64, error) {
+	pi, err := repository.R().UniswapPairInfo(&up.PairAddress)
+	if err != nil || pi == nil {
+		return nil, err
+	}
+	return &pi.TimeStamp, nil
+}
+
 func checkDate(td *int32) int64 {
 	if td != nil {
 		return (int64)(*td)
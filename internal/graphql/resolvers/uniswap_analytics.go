@@ -0,0 +1,26 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+)
+
+// UniswapPairAnalytics represents resolvable statistics of an Uniswap pair.
+type UniswapPairAnalytics struct {
+	types.UniswapPairAnalytics
+}
+
+// Analytics resolves the pre-calculated statistics of the Uniswap pair.
+func (up *UniswapPair) Analytics() (*UniswapPairAnalytics, error) {
+	upa, err := repository.R().UniswapPairAnalytics(&up.PairAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &UniswapPairAnalytics{UniswapPairAnalytics: *upa}, nil
+}
+
+// Pair resolves the Uniswap pair of the statistics.
+func (upa *UniswapPairAnalytics) Pair() *UniswapPair {
+	return NewUniswapPair(&upa.UniswapPairAnalytics.Pair)
+}
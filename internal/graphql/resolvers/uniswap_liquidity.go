@@ -0,0 +1,122 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// UniswapLiquidityQuote represents a resolvable preview of an Uniswap pair liquidity change.
+// Tokens related values are kept in the order of the tokens given by the caller.
+type UniswapLiquidityQuote struct {
+	types.UniswapLiquidityQuote
+	TokensList []common.Address
+}
+
+// DefiUniswapQuoteAddLiquidity resolves the preview of adding liquidity to an Uniswap pair.
+// The amounts are adjusted to the optimal ratio the same way addLiquidity call does.
+func (rs *rootResolver) DefiUniswapQuoteAddLiquidity(args *struct {
+	Tokens    []common.Address
+	AmountsIn []hexutil.Big
+	Slippage  float64
+}) (*UniswapLiquidityQuote, error) {
+	// get the optimal amounts in the order of the given tokens
+	amounts, err := rs.DefiUniswapQuoteLiquidity(&struct {
+		Tokens    []common.Address
+		AmountsIn []hexutil.Big
+	}{Tokens: args.Tokens, AmountsIn: args.AmountsIn})
+	if err != nil {
+		return nil, err
+	}
+
+	pair, reversed, err := uniswapLiquidityPair(args.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	if reversed {
+		amounts = []hexutil.Big{amounts[1], amounts[0]}
+	}
+
+	lq, err := repository.R().UniswapAddLiquidityQuote(pair, amounts, args.Slippage)
+	if err != nil {
+		return nil, err
+	}
+	return newUniswapLiquidityQuote(lq, args.Tokens, reversed), nil
+}
+
+// DefiUniswapQuoteRemoveLiquidity resolves the preview of removing liquidity from an Uniswap pair.
+func (rs *rootResolver) DefiUniswapQuoteRemoveLiquidity(args *struct {
+	Tokens    []common.Address
+	Liquidity hexutil.Big
+	Slippage  float64
+}) (*UniswapLiquidityQuote, error) {
+	pair, reversed, err := uniswapLiquidityPair(args.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	lq, err := repository.R().UniswapRemoveLiquidityQuote(pair, args.Liquidity.ToInt(), args.Slippage)
+	if err != nil {
+		return nil, err
+	}
+	return newUniswapLiquidityQuote(lq, args.Tokens, reversed), nil
+}
+
+// uniswapLiquidityPair finds the pair of the given tokens and checks if the tokens
+// are given in the reversed order compared to the pair.
+func uniswapLiquidityPair(tokens []common.Address) (*common.Address, bool, error) {
+	// make sure the number of tokens make sense
+	if tokens == nil || len(tokens) != 2 {
+		return nil, false, fmt.Errorf("invalid tokens pair given")
+	}
+
+	pair, err := repository.R().UniswapPair(&tokens[0], &tokens[1])
+	if err != nil {
+		return nil, false, err
+	}
+
+	tl, err := repository.R().UniswapTokens(pair)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(tl) != 2 {
+		return nil, false, fmt.Errorf("invalid pair %s", pair.String())
+	}
+
+	switch {
+	case tl[0] == tokens[0]:
+		return pair, false, nil
+	case tl[0] == tokens[1]:
+		return pair, true, nil
+	}
+	return nil, false, fmt.Errorf("the pair tokens don't match with input tokens")
+}
+
+// newUniswapLiquidityQuote creates a new resolvable liquidity quote in the order of the given tokens.
+func newUniswapLiquidityQuote(lq *types.UniswapLiquidityQuote, tokens []common.Address, reversed bool) *UniswapLiquidityQuote {
+	if reversed {
+		lq.Amounts = []hexutil.Big{lq.Amounts[1], lq.Amounts[0]}
+		lq.MinimumAmounts = []hexutil.Big{lq.MinimumAmounts[1], lq.MinimumAmounts[0]}
+		lq.Prices = []float64{lq.Prices[1], lq.Prices[0]}
+	}
+	return &UniswapLiquidityQuote{UniswapLiquidityQuote: *lq, TokensList: tokens}
+}
+
+// Pair resolves the Uniswap pair of the liquidity change.
+func (lq *UniswapLiquidityQuote) Pair() *UniswapPair {
+	return NewUniswapPair(&lq.UniswapLiquidityQuote.Pair)
+}
+
+// Tokens resolves the list of tokens of the liquidity change in the order given by the caller.
+func (lq *UniswapLiquidityQuote) Tokens() []*ERC20Token {
+	list := make([]*ERC20Token, len(lq.TokensList))
+	for i := range lq.TokensList {
+		list[i] = NewErc20Token(&lq.TokensList[i])
+	}
+	return list
+}
@@ -0,0 +1,151 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"golang.org/x/sync/singleflight"
+)
+
+// UniswapPosition represents a resolvable liquidity position of an account on an Uniswap pair.
+type UniswapPosition struct {
+	types.UniswapPosition
+	cg singleflight.Group
+}
+
+// UniswapPositionList represents resolvable list of liquidity positions edges structure.
+type UniswapPositionList struct {
+	types.UniswapPositionList
+}
+
+// UniswapPositionListEdge represents a single edge of a liquidity positions list structure.
+type UniswapPositionListEdge struct {
+	Position *UniswapPosition
+}
+
+// NewUniswapPosition builds new resolvable liquidity position.
+func NewUniswapPosition(pos *types.UniswapPosition) *UniswapPosition {
+	return &UniswapPosition{UniswapPosition: *pos}
+}
+
+// LiquidityPositions resolves the list of open liquidity positions of the account on Uniswap pairs.
+func (acc *Account) LiquidityPositions() ([]*UniswapPosition, error) {
+	pl, err := repository.R().UniswapPositionsByOwner(&acc.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*UniswapPosition, len(pl))
+	for i, pos := range pl {
+		list[i] = NewUniswapPosition(pos)
+	}
+	return list, nil
+}
+
+// LiquidityProviders resolves the list of open liquidity positions on the Uniswap pair.
+func (up *UniswapPair) LiquidityProviders(args *struct {
+	Cursor *Cursor
+	Count  int32
+}) (*UniswapPositionList, error) {
+	// limit query size; the count can be either positive or negative
+	// this controls the loading direction
+	args.Count = listLimitCount(args.Count, listMaxEdgesPerRequest)
+
+	pl, err := repository.R().UniswapPositionsByPair(&up.PairAddress, (*string)(args.Cursor), args.Count)
+	if err != nil {
+		return nil, err
+	}
+	return &UniswapPositionList{*pl}, nil
+}
+
+// value loads the current value of the position once.
+func (pos *UniswapPosition) value() (*types.UniswapPositionValue, error) {
+	val, err, _ := pos.cg.Do("value", func() (interface{}, error) {
+		return repository.R().UniswapPositionValue(&pos.UniswapPosition)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.(*types.UniswapPositionValue), nil
+}
+
+// Pair resolves the Uniswap pair of the position.
+func (pos *UniswapPosition) Pair() *UniswapPair {
+	return NewUniswapPair(&pos.UniswapPosition.Pair)
+}
+
+// CurrentAmounts resolves the current amounts of the pair tokens owned by the position.
+func (pos *UniswapPosition) CurrentAmounts() ([]hexutil.Big, error) {
+	val, err := pos.value()
+	if err != nil {
+		return nil, err
+	}
+	return val.Amounts, nil
+}
+
+// FeesEarned resolves the estimated amounts of the pair tokens earned on fees by the position.
+func (pos *UniswapPosition) FeesEarned() ([]hexutil.Big, error) {
+	val, err := pos.value()
+	if err != nil {
+		return nil, err
+	}
+	return val.FeesEarned, nil
+}
+
+// ShareOfPool resolves the percentage of the pool owned by the position.
+func (pos *UniswapPosition) ShareOfPool() (float64, error) {
+	val, err := pos.value()
+	if err != nil {
+		return 0, err
+	}
+	return val.ShareOfPool, nil
+}
+
+// ImpermanentLoss resolves the percentage of the value lost by the position compared to holding the deposited tokens.
+func (pos *UniswapPosition) ImpermanentLoss() (float64, error) {
+	val, err := pos.value()
+	if err != nil {
+		return 0, err
+	}
+	return val.ImpermanentLoss, nil
+}
+
+// TotalCount resolves the total number of positions in the list.
+func (pl *UniswapPositionList) TotalCount() hexutil.Uint64 {
+	return hexutil.Uint64(pl.Total)
+}
+
+// PageInfo resolves the current page information for the positions list.
+func (pl *UniswapPositionList) PageInfo() (*ListPageInfo, error) {
+	// do we have any items?
+	if pl.Collection == nil || len(pl.Collection) == 0 {
+		return NewListPageInfo(nil, nil, false, false)
+	}
+
+	// get the first and last elements
+	first := Cursor(pl.Collection[0].Pk())
+	last := Cursor(pl.Collection[len(pl.Collection)-1].Pk())
+	return NewListPageInfo(&first, &last, !pl.IsEnd, !pl.IsStart)
+}
+
+// Edges resolves list of positions list edges.
+func (pl *UniswapPositionList) Edges() []*UniswapPositionListEdge {
+	// do we have any items? return empty list if not
+	if pl.Collection == nil || len(pl.Collection) == 0 {
+		return make([]*UniswapPositionListEdge, 0)
+	}
+
+	// make the list
+	edges := make([]*UniswapPositionListEdge, len(pl.Collection))
+	for i, pos := range pl.Collection {
+		edges[i] = &UniswapPositionListEdge{Position: NewUniswapPosition(pos)}
+	}
+	return edges
+}
+
+// Cursor generates the cursor for the current position list edge.
+func (ple *UniswapPositionListEdge) Cursor() Cursor {
+	return Cursor(ple.Position.Pk())
+}
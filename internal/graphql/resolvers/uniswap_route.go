@@ -0,0 +1,143 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// UniswapRoute represents a resolvable path of swaps between two tokens.
+type UniswapRoute struct {
+	types.UniswapRoute
+}
+
+// UniswapRouteHop represents a resolvable single swap step of a route.
+type UniswapRouteHop struct {
+	types.UniswapRouteHop
+}
+
+// DefiUniswapBestRoute resolves a list of the best routes for swapping tokenIn for tokenOut.
+// Exactly one of the input and the output amount must be given.
+func (rs *rootResolver) DefiUniswapBestRoute(args *struct {
+	TokenIn   common.Address
+	TokenOut  common.Address
+	AmountIn  *hexutil.Big
+	AmountOut *hexutil.Big
+	MaxHops   int32
+	Count     int32
+	Slippage  float64
+}) ([]*UniswapRoute, error) {
+	// we need exactly one of the amounts to know which way to go
+	if (args.AmountIn == nil) == (args.AmountOut == nil) {
+		return nil, fmt.Errorf("either amountIn, or amountOut must be given")
+	}
+
+	amount, exactOut := args.AmountIn, false
+	if args.AmountOut != nil {
+		amount, exactOut = args.AmountOut, true
+	}
+
+	// find the routes
+	rl, err := repository.R().UniswapBestRoutes(&args.TokenIn, &args.TokenOut, amount.ToInt(), exactOut, int(args.MaxHops), int(args.Count), args.Slippage)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*UniswapRoute, len(rl))
+	for i, r := range rl {
+		list[i] = &UniswapRoute{UniswapRoute: *r}
+	}
+	return list, nil
+}
+
+// DefiUniswapQuoteOut resolves the swap quote for the given input amount
+// and a list of tokens to be used to make the swap operation.
+func (rs *rootResolver) DefiUniswapQuoteOut(args *struct {
+	AmountIn hexutil.Big
+	Tokens   []common.Address
+	Slippage float64
+}) (*UniswapRoute, error) {
+	r, err := repository.R().UniswapQuote(args.Tokens, args.AmountIn.ToInt(), false, args.Slippage)
+	if err != nil {
+		return nil, err
+	}
+	return &UniswapRoute{UniswapRoute: *r}, nil
+}
+
+// DefiUniswapQuoteIn resolves the swap quote for the given output amount
+// and a list of tokens to be used to make the swap operation.
+func (rs *rootResolver) DefiUniswapQuoteIn(args *struct {
+	AmountOut hexutil.Big
+	Tokens    []common.Address
+	Slippage  float64
+}) (*UniswapRoute, error) {
+	r, err := repository.R().UniswapQuote(args.Tokens, args.AmountOut.ToInt(), true, args.Slippage)
+	if err != nil {
+		return nil, err
+	}
+	return &UniswapRoute{UniswapRoute: *r}, nil
+}
+
+// Tokens resolves the list of tokens on the route in the order of the swaps.
+func (ur *UniswapRoute) Tokens() []*ERC20Token {
+	list := make([]*ERC20Token, 0, len(ur.UniswapRoute.Hops)+1)
+	for i := range ur.UniswapRoute.Hops {
+		if i == 0 {
+			list = append(list, NewErc20Token(&ur.UniswapRoute.Hops[i].TokenIn))
+		}
+		list = append(list, NewErc20Token(&ur.UniswapRoute.Hops[i].TokenOut))
+	}
+	return list
+}
+
+// Path resolves the list of token addresses on the route, usable as the router swap path.
+func (ur *UniswapRoute) Path() []common.Address {
+	list := make([]common.Address, 0, len(ur.UniswapRoute.Hops)+1)
+	for i := range ur.UniswapRoute.Hops {
+		if i == 0 {
+			list = append(list, ur.UniswapRoute.Hops[i].TokenIn)
+		}
+		list = append(list, ur.UniswapRoute.Hops[i].TokenOut)
+	}
+	return list
+}
+
+// Amounts resolves the list of amounts swapped on the route, the same way the router reports them.
+func (ur *UniswapRoute) Amounts() []hexutil.Big {
+	list := make([]hexutil.Big, 0, len(ur.UniswapRoute.Hops)+1)
+	for i := range ur.UniswapRoute.Hops {
+		if i == 0 {
+			list = append(list, ur.UniswapRoute.Hops[i].AmountIn)
+		}
+		list = append(list, ur.UniswapRoute.Hops[i].AmountOut)
+	}
+	return list
+}
+
+// Hops resolves the list of swap steps of the route.
+func (ur *UniswapRoute) Hops() []*UniswapRouteHop {
+	list := make([]*UniswapRouteHop, len(ur.UniswapRoute.Hops))
+	for i := range ur.UniswapRoute.Hops {
+		list[i] = &UniswapRouteHop{UniswapRouteHop: ur.UniswapRoute.Hops[i]}
+	}
+	return list
+}
+
+// Pair resolves the Uniswap pair used by the swap step.
+func (uh *UniswapRouteHop) Pair() *UniswapPair {
+	return NewUniswapPair(&uh.UniswapRouteHop.Pair)
+}
+
+// TokenIn resolves the token sent into the pair.
+func (uh *UniswapRouteHop) TokenIn() *ERC20Token {
+	return NewErc20Token(&uh.UniswapRouteHop.TokenIn)
+}
+
+// TokenOut resolves the token received from the pair.
+func (uh *UniswapRouteHop) TokenOut() *ERC20Token {
+	return NewErc20Token(&uh.UniswapRouteHop.TokenOut)
+}
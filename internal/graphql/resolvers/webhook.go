@@ -0,0 +1,263 @@
+// Package resolvers implements GraphQL resolvers to incoming API requests.
+package resolvers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/svc"
+	"ncogearthchain-api-graphql/internal/types"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	// webhookMaxTopics is the max number of log topics in a webhook filter.
+	webhookMaxTopics = 20
+
+	// webhookDeliveriesDefaultCount is the default number of deliveries in the delivery log.
+	webhookDeliveriesDefaultCount = 25
+
+	// webhookDeliveriesMaxCount is the max number of deliveries in the delivery log.
+	webhookDeliveriesMaxCount = 250
+)
+
+// Webhook represents resolvable webhook registration.
+type Webhook struct {
+	types.Webhook
+
+	// withSecret marks the secret to be disclosed; it's only done on the registration.
+	withSecret bool
+}
+
+// WebhookDelivery represents resolvable webhook delivery.
+type WebhookDelivery struct {
+	types.WebhookDelivery
+}
+
+// WebhookFilterInput represents the input of a webhook filter.
+type WebhookFilterInput struct {
+	Type      string
+	Address   *common.Address
+	Token     *common.Address
+	TokenType *string
+	Topics    *[]common.Hash
+}
+
+// RegisterWebhook resolves registration of a new webhook receiving events matching the filter.
+// The secret used to sign the deliveries is disclosed in the response only.
+func (rs *rootResolver) RegisterWebhook(args struct {
+	Url    string
+	Filter WebhookFilterInput
+}) (*Webhook, error) {
+	if !cfg.Webhooks.Enabled {
+		return nil, fmt.Errorf("webhooks are not enabled")
+	}
+
+	// validate the input
+	filter, err := webhookFilter(&args.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := isValidWebhookUrl(args.Url); err != nil {
+		return nil, err
+	}
+
+	// check the registrations limit
+	list, err := repository.R().Webhooks()
+	if err != nil {
+		return nil, err
+	}
+	if len(list) >= cfg.Webhooks.MaxHooks {
+		return nil, fmt.Errorf("webhooks limit reached")
+	}
+
+	// make the webhook
+	id, err := uuid()
+	if err != nil {
+		log.Errorf("can not generate webhook id; %s", err.Error())
+		return nil, err
+	}
+	secret, err := webhookSecret()
+	if err != nil {
+		log.Errorf("can not generate webhook secret; %s", err.Error())
+		return nil, err
+	}
+	wh := types.Webhook{
+		Id:      id,
+		Url:     args.Url,
+		Secret:  secret,
+		Filter:  *filter,
+		Created: time.Now().UTC(),
+	}
+
+	// store and subscribe
+	if err := repository.R().AddWebhook(&wh); err != nil {
+		return nil, err
+	}
+	svc.Manager().RegisterWebhook(&wh)
+	return &Webhook{Webhook: wh, withSecret: true}, nil
+}
+
+// UnregisterWebhook resolves removal of the webhook of the given id.
+// The delivery log of the webhook remains available.
+func (rs *rootResolver) UnregisterWebhook(args struct{ Id string }) (bool, error) {
+	ok, err := repository.R().RemoveWebhook(args.Id)
+	if err != nil {
+		return false, err
+	}
+	svc.Manager().UnregisterWebhook(args.Id)
+	return ok, nil
+}
+
+// Webhook resolves the webhook registration of the given id.
+func (rs *rootResolver) Webhook(args struct{ Id string }) (*Webhook, error) {
+	wh, err := repository.R().Webhook(args.Id)
+	if err != nil || wh == nil {
+		return nil, err
+	}
+	return &Webhook{Webhook: *wh}, nil
+}
+
+// WebhookDeliveries resolves the delivery log of the webhook of the given id,
+// the most recent deliveries first. Use the DEAD status to get the dead letter list.
+func (rs *rootResolver) WebhookDeliveries(args struct {
+	Id     string
+	Status *string
+	Count  *int32
+}) ([]*WebhookDelivery, error) {
+	count := int64(webhookDeliveriesDefaultCount)
+	if args.Count != nil && *args.Count > 0 {
+		count = int64(*args.Count)
+	}
+	if count > webhookDeliveriesMaxCount {
+		count = webhookDeliveriesMaxCount
+	}
+
+	list, err := repository.R().WebhookDeliveries(args.Id, args.Status, count)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*WebhookDelivery, len(list))
+	for i, wd := range list {
+		res[i] = &WebhookDelivery{*wd}
+	}
+	return res, nil
+}
+
+// Secret resolves the secret of the webhook; it's disclosed on the registration only.
+func (wh *Webhook) Secret() *string {
+	if !wh.withSecret {
+		return nil
+	}
+	return &wh.Webhook.Secret
+}
+
+// Created resolves the time of the webhook registration.
+func (wh *Webhook) Created() graphql.Time {
+	return graphql.Time{Time: wh.Webhook.Created}
+}
+
+// LastError resolves the failure of the last delivery attempt, if any.
+func (wd *WebhookDelivery) LastError() *string {
+	if wd.WebhookDelivery.LastError == "" {
+		return nil
+	}
+	return &wd.WebhookDelivery.LastError
+}
+
+// Created resolves the time the delivery was created.
+func (wd *WebhookDelivery) Created() graphql.Time {
+	return graphql.Time{Time: wd.WebhookDelivery.Created}
+}
+
+// LastAttempt resolves the time of the last delivery attempt, if any.
+func (wd *WebhookDelivery) LastAttempt() *graphql.Time {
+	if wd.Attempts == 0 {
+		return nil
+	}
+	return &graphql.Time{Time: wd.WebhookDelivery.LastAttempt}
+}
+
+// NextAttempt resolves the time of the next delivery attempt of a pending delivery.
+func (wd *WebhookDelivery) NextAttempt() *graphql.Time {
+	if wd.Status != types.WebhookDeliveryPending {
+		return nil
+	}
+	return &graphql.Time{Time: wd.WebhookDelivery.NextAttempt}
+}
+
+// webhookFilter validates the filter input and builds the webhook filter from it.
+func webhookFilter(in *WebhookFilterInput) (*types.WebhookFilter, error) {
+	f := types.WebhookFilter{
+		Type:      in.Type,
+		Address:   in.Address,
+		Token:     in.Token,
+		TokenType: in.TokenType,
+	}
+	if in.Topics != nil {
+		f.Topics = *in.Topics
+	}
+
+	switch f.Type {
+	case types.WebhookFilterAccountActivity:
+		if f.Address == nil {
+			return nil, fmt.Errorf("account address is required for %s webhooks", f.Type)
+		}
+	case types.WebhookFilterTokenTransfer:
+		if f.TokenType != nil && *f.TokenType != types.AccountTypeERC20Token &&
+			*f.TokenType != types.AccountTypeERC721Contract && *f.TokenType != types.AccountTypeERC1155Contract {
+			return nil, fmt.Errorf("unknown token type %s", *f.TokenType)
+		}
+	case types.WebhookFilterLogs:
+		if len(f.Topics) > webhookMaxTopics {
+			return nil, fmt.Errorf("too many topics, %d allowed", webhookMaxTopics)
+		}
+	case types.WebhookFilterEpoch:
+	default:
+		return nil, fmt.Errorf("unknown webhook filter type %s", f.Type)
+	}
+	return &f, nil
+}
+
+// isValidWebhookUrl checks the webhook target URL. Only HTTP(S) targets are accepted,
+// targets on private networks are refused unless explicitly allowed by the configuration.
+// The check is repeated on each delivery, since the target host may resolve differently later.
+func isValidWebhookUrl(target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL; %s", err.Error())
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("webhook URL must be an absolute HTTP(S) address")
+	}
+	if cfg.Webhooks.AllowPrivate {
+		return nil
+	}
+
+	ips, err := net.LookupIP(u.Hostname())
+	if err != nil {
+		return fmt.Errorf("webhook host can not be resolved; %s", err.Error())
+	}
+	for _, ip := range ips {
+		if !svc.IsPublicWebhookIP(ip) {
+			return fmt.Errorf("webhook URL must be a public address")
+		}
+	}
+	return nil
+}
+
+// webhookSecret generates a new random webhook signing secret.
+func webhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -135,6 +135,8 @@ enum TokenTransactionType {
     BURN
     APPROVAL
     APPROVAL_FOR_ALL
+    WRAP
+    UNWRAP
     OTHER
 }
 
@@ -317,6 +319,21 @@ type GasPriceTick {
     avgPrice: Long!
 }
 
+# GasPriceEstimate represents an extended gas price estimation in Gwei.
+type GasPriceEstimate {
+    # safeLow is the lowest gas price expected to get the transaction processed.
+    safeLow: Float!
+
+    # average is the gas price of an average transaction.
+    average: Float!
+
+    # fast is the gas price expected to get the transaction processed fast.
+    fast: Float!
+
+    # fastest is the gas price expected to get the transaction processed as soon as possible.
+    fastest: Float!
+}
+
 # ListPageInfo contains information about a sequential access list page.
 type ListPageInfo {
     # First is the cursor of the first edge of the edges list. null for empty list.
@@ -701,6 +718,11 @@ type Epoch {
 
     # Total supply amount.
     totalSupply: BigInt!
+
+    # Summary of rewards distributed to validators in the epoch.
+    # The summary is not available if the node does not provide
+    # the epoch snapshot data.
+    validatorRewards: [EpochValidatorRewards!]
 }
 
 # ERC721TransactionList is a list of ERC721 transaction edges provided by sequential access request.
@@ -956,6 +978,33 @@ type UniswapAction {
     amount1out: BigInt!
 }
 
+# UniswapPairPrice represents the state of an Uniswap pair reserves
+# after a change, with the price derived from the reserves ratio.
+type UniswapPairPrice {
+    # pairAddress is the address of the Uniswap pair.
+    pairAddress: Address!
+
+    # uniswapPair represents the details of the Uniswap pair.
+    uniswapPair: UniswapPair!
+
+    # blockNr is the number of the block of the change.
+    blockNr: Long!
+
+    # time is the time stamp of the block of the change.
+    time: Long!
+
+    # transactionHash is the hash of the transaction causing the change.
+    transactionHash: Bytes32!
+
+    # reserves are the new reserves of the pair tokens.
+    reserves: [BigInt!]!
+
+    # price is the ratio of the pair reserves. The direction follows
+    # the defiTimePrices convention; 0 means the first token reserve
+    # divided by the second one, any other value means the opposite.
+    price(direction: Int = 0): Float!
+}
+
 # Represents staker information.
 type Staker {
     # ID number the staker.
@@ -1325,6 +1374,24 @@ type UniswapPair {
     # To get the share percentage, divide this value by the total supply
     # of the pair.
     shareOf(user: Address!): BigInt!
+
+    # featured signals the pair is featured by the API configuration.
+    featured: Boolean!
+
+    # createdBlock represents the number of the block
+    # where the pair was created, if known.
+    createdBlock: Long
+
+    # createdTimeStamp represents the time stamp of the block
+    # where the pair was created, if known.
+    createdTimeStamp: Long
+
+    # liquidityProviders represents the list of open liquidity
+    # positions on the pair.
+    liquidityProviders(cursor: Cursor, count: Int = 25): UniswapPositionList!
+
+    # analytics represents the pre-calculated statistics of the pair.
+    analytics: UniswapPairAnalytics!
 }
 
 
@@ -1354,6 +1421,8 @@ type DefiUniswapVolume {
     # to calculate value in fUSD
     isInFUSD: Boolean!
 
+    # analytics represents the pre-calculated statistics of the pair.
+    analytics: UniswapPairAnalytics!
 }
 
 # DefiSwaps represents swap volume for given pair and time interval
@@ -1755,6 +1824,10 @@ type Account {
     # NOTE: This values is slow to calculate.
     totalValue: BigInt!
 
+    # Portfolio is the valuation of all the holdings of the account
+    # in the given currency, including DeFi and liquidity positions.
+    portfolio(currency: String = "USD"): Portfolio!
+
     # txCount represents number of transaction sent from the account (Nonce).
     txCount: Long!
 
@@ -1781,6 +1854,9 @@ type Account {
 
     # Token summaries for the account
     tokenSummaries: [TokenSummary!]!
+
+    # List of open liquidity positions of the account on Uniswap pairs.
+    liquidityPositions: [UniswapPosition!]!
 }
 
 # TokenSummary represents a summary of token information
@@ -2075,6 +2151,10 @@ type Query {
     # Get transaction information for given transaction hash.
     transaction(hash:Bytes32!):Transaction
 
+    # Get the lifecycle state of a transaction submitted through the API.
+    # Transactions submitted elsewhere, or no longer tracked, resolve to null.
+    transactionStatus(hash: Bytes32!): TransactionStatus
+
     # Get list of Transactions with at most <count> edges.
     # If <count> is positive, return edges after the cursor,
     # if negative, return edges before the cursor.
@@ -2138,9 +2218,32 @@ type Query {
     # for the transaction described by the parameters of the call.
     estimateGas(from: Address, to: Address, value: BigInt, data: String): Long
 
+    # simulateTransaction executes the described transaction against the state
+    # of the given block, or the latest block, with the state overrides applied.
+    # Nothing is broadcast. The outcome provides the decoded return data and logs
+    # and the net balance changes of the involved accounts. The gas of the call
+    # is capped by the server configuration; busy servers refuse the simulation.
+    simulateTransaction(from: Address!, to: Address, value: BigInt, data: Bytes, gas: Long, blockNumber: Long, stateOverrides: [StateOverrideInput!]): TransactionSimulation!
+
+    # Get list of transactions waiting in the pool of the connected node
+    # sent from, or to the given address.
+    pendingTransactions(address: Address!): [Transaction!]!
+
+    # Get the number of transactions waiting in the pool of the connected node.
+    txPoolStatus: TxPoolStatus!
+
     # Get price details of the Ncogearthchain blockchain token for the given target symbols.
     price(to:String!):Price!
 
+    # priceHistory provides the price history of the Ncogearthchain blockchain token
+    # in the given target symbol for the given time span. The resolution is "sample",
+    # "hour", or "day"; it's derived from the time span if not given.
+    priceHistory(to: String!, from: Time!, until: Time, resolution: String): [PriceTick!]!
+
+    # priceAt provides the price of the Ncogearthchain blockchain token
+    # in the given target symbol at the given time, if known.
+    priceAt(to: String!, time: Time!): PriceTick
+
     # Get calculated staking rewards for an account or given
     # staking amount in NEC tokens.
     # At least one of the address and amount parameters must be provided.
@@ -2176,20 +2279,24 @@ type Query {
 
     # defiUniswapPairs represents a list of all pairs managed
     # by the Uniswap Core contract on Ncogearthchain blockchain.
-    defiUniswapPairs: [UniswapPair!]!
+    # New pairs are discovered automatically from the factory events,
+    # use featuredOnly to get only the pairs featured by the API.
+    defiUniswapPairs(featuredOnly: Boolean = false): [UniswapPair!]!
 
     # defiUniswapAmountsOut calculates the expected output amounts
     # required to finalize a swap operation specified by a list of
     # tokens involved in the swap steps and the input amount.
     # At least two addresses of tokens must be given
-    # for the calculation to succeed.
+    # for the calculation to succeed. Use defiUniswapQuoteOut to get
+    # the price impact, LP fees and the slippage limits of the swap.
     defiUniswapAmountsOut(amountIn: BigInt!, tokens:[Address!]!): [BigInt!]!
 
     # defiUniswapAmountsIn calculates the expected input amounts
     # required to finalize a swap operation specified by a list of
     # tokens involved in the swap steps and the output amount.
     # At least two addresses of tokens must be given
-    # for the calculation to succeed.
+    # for the calculation to succeed. Use defiUniswapQuoteIn to get
+    # the price impact, LP fees and the slippage limits of the swap.
     defiUniswapAmountsIn(amountOut: BigInt!, tokens:[Address!]!): [BigInt!]!
 
     # defiUniswapQuoteLiquidity calculates optimal amount of tokens
@@ -2200,6 +2307,35 @@ type Query {
     # Please note "amountsIn" must be in the same order as are the tokens.
     defiUniswapQuoteLiquidity(tokens:[Address!]!, amountsIn:[BigInt!]!): [BigInt!]!
 
+    # defiUniswapBestRoute finds the best routes for swapping tokenIn
+    # for tokenOut across all the known Uniswap pairs using their current
+    # reserves. Either amountIn, or amountOut must be given; routes are ranked
+    # by the output amount for exact input, and by the input amount for exact output.
+    # The maxHops limits the number of swaps on a route (1 to 4), count limits
+    # the number of routes returned. The slippage tolerance is in percent.
+    defiUniswapBestRoute(tokenIn: Address!, tokenOut: Address!, amountIn: BigInt, amountOut: BigInt, maxHops: Int = 3, count: Int = 3, slippage: Float = 0.5): [UniswapRoute!]!
+
+    # defiUniswapQuoteOut calculates the swap of the given input amount
+    # along the given list of tokens including the price impact, LP fees
+    # and the minimal output for the slippage tolerance in percent.
+    defiUniswapQuoteOut(amountIn: BigInt!, tokens:[Address!]!, slippage: Float = 0.5): UniswapRoute!
+
+    # defiUniswapQuoteIn calculates the swap of the given output amount
+    # along the given list of tokens including the price impact, LP fees
+    # and the maximal input for the slippage tolerance in percent.
+    defiUniswapQuoteIn(amountOut: BigInt!, tokens:[Address!]!, slippage: Float = 0.5): UniswapRoute!
+
+    # defiUniswapQuoteAddLiquidity calculates the preview of adding liquidity
+    # to an Uniswap pair defined by a pair of tokens. The amounts are adjusted
+    # to the optimal ratio the same way defiUniswapQuoteLiquidity does.
+    # Please note "amountsIn" must be in the same order as are the tokens.
+    defiUniswapQuoteAddLiquidity(tokens:[Address!]!, amountsIn:[BigInt!]!, slippage: Float = 0.5): UniswapLiquidityQuote!
+
+    # defiUniswapQuoteRemoveLiquidity calculates the preview of removing
+    # the given amount of liquidity tokens from an Uniswap pair defined
+    # by a pair of tokens.
+    defiUniswapQuoteRemoveLiquidity(tokens:[Address!]!, liquidity: BigInt!, slippage: Float = 0.5): UniswapLiquidityQuote!
+
     # defiUniswapVolumes represents a list of pairs and their historical values
     # of traded volumes
     defiUniswapVolumes:[DefiUniswapVolume!]!
@@ -2331,13 +2467,25 @@ type Query {
 
     # Trace a transaction.
     traceTransaction(hash: Bytes32!, params: JSONAny): JSONAny!
+
+    # Get the webhook registration by its id.
+    webhook(id: String!): Webhook
+
+    # Get the delivery log of the webhook, the most recent deliveries first.
+    # Use the DEAD status to get deliveries which failed all the retries.
+    webhookDeliveries(id: String!, status: String, count: Int = 25): [WebhookDelivery!]!
 }
 
 # Mutation endpoints for modifying the data
 type Mutation {
     # SendTransaction submits a raw signed transaction into the block chain.
     # The tx parameter represents raw signed and RLP encoded transaction data.
-    sendTransaction(tx: Bytes!):Transaction
+    # The transaction is validated before it's sent; the chain id, the signature,
+    # the nonce, the balance, the intrinsic gas and the gas price are checked.
+    # If the dryRun is set, the transaction is also executed against the pending
+    # state and refused if it reverts. Refused transactions resolve to errors
+    # with the reason code in the error extensions.
+    sendTransaction(tx: Bytes!, dryRun: Boolean = false):Transaction
 
     # Validate a deployed contract byte code with the provided source code
     # so potential users can check the contract source code, access contract ABI
@@ -2345,14 +2493,685 @@ type Mutation {
     # Returns updated contract information. If the contract can not be validated,
     # it raises a GraphQL error.
     validateContract(contract: ContractValidationInput!): Contract!
+
+    # Register a webhook receiving events matching the filter by HTTP POST
+    # to the given URL. Deliveries are signed by HMAC-SHA256 of the body
+    # with the webhook secret, sent in the X-Webhook-Signature header.
+    # The secret is disclosed in the response of the registration only.
+    registerWebhook(url: String!, filter: WebhookFilterInput!): Webhook!
+
+    # Unregister the webhook of the given id.
+    unregisterWebhook(id: String!): Boolean!
 }
 
 # Subscriptions to live events broadcasting
 type Subscription {
     # Subscribe to receive information about new blocks in the blockchain.
-    onBlock: Block!
+    # If the fromBlock is given, blocks since the given block are replayed first
+    # and the subscription continues with new blocks without gaps or duplicates.
+    # The replay is limited to the most recent 1000 blocks, older starting
+    # blocks are refused.
+    onBlock(fromBlock: Long): Block!
 
     # Subscribe to receive information about new transactions in the blockchain.
-    onTransaction: Transaction!
+    # If the fromBlock, or the fromCursor of the last received transaction is given,
+    # the stored transactions since the starting point are replayed first and
+    # the subscription continues with new transactions without gaps or duplicates.
+    # The cursor takes precedence over the block. The replay is limited
+    # to the transactions of the most recent 1000 blocks, older starting
+    # points are refused.
+    onTransaction(fromBlock: Long, fromCursor: Cursor): Transaction!
+
+    # Subscribe to receive lifecycle changes of a transaction submitted through
+    # the API. The current state is sent right after the subscription is made,
+    # the subscription completes once the state is final.
+    onTransactionStatus(hash: Bytes32!): TransactionStatus!
+
+    # Subscribe to receive new event logs emitted by contracts. The logs are filtered
+    # the same way eth_subscribe does. An empty list of addresses matches any contract.
+    # Topics are matched by position, null or empty position matches any topic,
+    # multiple topics on a position match any of them.
+    onLogs(addresses: [Address!], topics: [[Bytes32!]]): Log!
+
+    # Subscribe to receive events involving the given account; native transactions,
+    # token transfers, delegations, undelegations, withdrawals and reward claims.
+    onAccountActivity(address: Address!): AccountActivity!
+
+    # Subscribe to receive token transfers, mints, burns and wraps as they are processed.
+    # The transfers can be filtered by the token contract, by the account sending
+    # or receiving the tokens and by the type of the token (ERC20/ERC721/ERC1155).
+    onTokenTransfer(token: Address, account: Address, tokenType: String): TokenTransaction!
+
+    # Subscribe to receive new transactions entering the pool of the connected node
+    # before they are mined, optionally filtered by the sender and the recipient.
+    onPendingTransaction(from: Address, to: Address): Transaction!
+
+    # Subscribe to receive epochs as they are sealed.
+    onEpochSealed: Epoch!
+
+    # Subscribe to receive the extended gas price estimation whenever it changes.
+    # The current estimation is sent right after the subscription is made.
+    onGasPrice: GasPriceEstimate!
+
+    # Subscribe to receive swaps on the given Uniswap pair as they are processed.
+    onUniswapSwap(pair: Address!): UniswapAction!
+
+    # Subscribe to receive price changes of the given Uniswap pair
+    # derived from the pair reserves as they are updated.
+    onPairPrice(pair: Address!): UniswapPairPrice!
+}
+
+# UniswapRoute represents a path of swaps between two tokens
+# across Uniswap pairs managed by the Uniswap Core. It's used
+# to describe both the found best routes and the swap quotes.
+type UniswapRoute {
+    # tokens is the list of tokens on the route in the order of the swaps.
+    tokens: [ERC20Token!]!
+
+    # path is the list of token addresses on the route; it can be used
+    # as the path argument of the Uniswap Router swap calls.
+    path: [Address!]!
+
+    # amounts is the list of amounts of tokens swapped on the route
+    # in the same order as the tokens are.
+    amounts: [BigInt!]!
+
+    # amountIn is the amount of tokens sent into the route.
+    amountIn: BigInt!
+
+    # amountOut is the amount of tokens received from the route.
+    amountOut: BigInt!
+
+    # midPrice is the price of the input token in output tokens
+    # given by the current reserves of the pairs on the route.
+    midPrice: Float!
+
+    # executionPrice is the price of the input token in output tokens
+    # actually paid on the route.
+    executionPrice: Float!
+
+    # priceImpact is the percentage of the price change caused
+    # by the swap on the route. The LP fee is not included.
+    priceImpact: Float!
+
+    # lpFeePercent is the percentage of the input amount paid
+    # to liquidity providers of the pairs along the route.
+    lpFeePercent: Float!
+
+    # slippage is the slippage tolerance in percent used
+    # to calculate the minimumReceived and maximumSold.
+    slippage: Float!
+
+    # minimumReceived is the lowest output amount acceptable
+    # for the slippage tolerance; use it as amountOutMin on exact input swaps.
+    minimumReceived: BigInt!
+
+    # maximumSold is the highest input amount acceptable
+    # for the slippage tolerance; use it as amountInMax on exact output swaps.
+    maximumSold: BigInt!
+
+    # hops is the list of swap steps of the route.
+    hops: [UniswapRouteHop!]!
+}
+
+# UniswapRouteHop represents a single swap step of an Uniswap route.
+type UniswapRouteHop {
+    # pair is the Uniswap pair used by the step.
+    pair: UniswapPair!
+
+    # tokenIn is the token sent into the pair.
+    tokenIn: ERC20Token!
+
+    # tokenOut is the token received from the pair.
+    tokenOut: ERC20Token!
+
+    # amountIn is the amount of tokens sent into the pair.
+    amountIn: BigInt!
+
+    # amountOut is the amount of tokens received from the pair.
+    amountOut: BigInt!
+
+    # midPrice is the price of the input token in output tokens
+    # given by the reserves of the pair before the swap.
+    midPrice: Float!
+
+    # priceImpact is the percentage of the price change caused
+    # by the step. The LP fee is not included.
+    priceImpact: Float!
+
+    # lpFee is the amount of input tokens paid to the liquidity providers of the pair.
+    lpFee: BigInt!
+}
+
+# UniswapLiquidityQuote represents a preview of adding liquidity to,
+# or removing liquidity from an Uniswap pair. The token related values
+# are in the same order as the tokens are.
+type UniswapLiquidityQuote {
+    # pair is the Uniswap pair of the liquidity change.
+    pair: UniswapPair!
+
+    # tokens is the list of tokens of the pair.
+    tokens: [ERC20Token!]!
+
+    # amounts is the list of amounts of tokens added, or removed.
+    amounts: [BigInt!]!
+
+    # minimumAmounts is the list of the lowest amounts of tokens acceptable
+    # for the slippage tolerance; use them as amountAMin and amountBMin.
+    minimumAmounts: [BigInt!]!
+
+    # liquidity is the amount of the pair liquidity tokens minted, or burned.
+    liquidity: BigInt!
+
+    # shareOfPool is the percentage of the pool owned by the liquidity tokens.
+    shareOfPool: Float!
+
+    # prices is the list of prices of each token in the sibling token.
+    prices: [Float!]!
+
+    # slippage is the slippage tolerance in percent used
+    # to calculate the minimumAmounts.
+    slippage: Float!
+}
+
+# UniswapPosition represents a liquidity position of an account
+# on an Uniswap pair. The token related values are in the order
+# of the pair tokens.
+type UniswapPosition {
+    # pair is the Uniswap pair of the position.
+    pair: UniswapPair!
+
+    # owner is the address of the liquidity provider.
+    owner: Address!
+
+    # liquidity is the amount of the pair liquidity tokens held.
+    liquidity: BigInt!
+
+    # shareOfPool is the percentage of the pool owned by the position.
+    shareOfPool: Float!
+
+    # currentAmounts is the list of the current amounts
+    # of the pair tokens owned by the position.
+    currentAmounts: [BigInt!]!
+
+    # deposited is the list of amounts of the pair tokens
+    # deposited for the liquidity currently held.
+    deposited: [BigInt!]!
+
+    # withdrawn is the list of amounts of the pair tokens
+    # withdrawn from the position so far.
+    withdrawn: [BigInt!]!
+
+    # feesEarned is the list of estimated amounts of the pair tokens
+    # earned on swap fees by the liquidity currently held.
+    feesEarned: [BigInt!]!
+
+    # impermanentLoss is the percentage of the value lost by providing
+    # the liquidity compared to holding the deposited tokens.
+    # Fees earned are not included.
+    impermanentLoss: Float!
+
+    # opened is the time stamp of the position opening.
+    opened: Long!
+
+    # updated is the time stamp of the last position change.
+    updated: Long!
+}
+
+# UniswapPositionList is a list of liquidity positions edges provided by sequential access request.
+type UniswapPositionList {
+    # Edges contains provided edges of the sequential list.
+    edges: [UniswapPositionListEdge!]!
+
+    # TotalCount is the maximum number of positions available for sequential access.
+    totalCount: Long!
+
+    # PageInfo is an information about the current page of position edges.
+    pageInfo: ListPageInfo!
+}
+
+# UniswapPositionListEdge is a single edge in a sequential list of liquidity positions.
+type UniswapPositionListEdge {
+    # Cursor defines a scroll key to this edge.
+    cursor: Cursor!
+
+    # position represents the liquidity position provided by this list edge.
+    position: UniswapPosition!
+}
+
+# UniswapPairAnalytics represents statistics of an Uniswap pair
+# calculated periodically in the background. Volumes are denominated
+# in the first token of the pair, values are in fUSD and changes
+# and rates are percentages.
+type UniswapPairAnalytics {
+    # pair represents the Uniswap pair of the statistics.
+    pair: UniswapPair!
+
+    # reserves of the tokens of the pair at the time of the calculation.
+    reserves: [BigInt!]!
+
+    # totalValueLocked represents the value of the pair reserves in fUSD.
+    totalValueLocked: Float!
+
+    # isInFUSD indicates if at least one of the pair tokens has a price value
+    # to be able to calculate values in fUSD.
+    isInFUSD: Boolean!
+
+    # volume24h represents the swap volume of the last 24 hours.
+    volume24h: BigInt!
+
+    # volumePrevious24h represents the swap volume
+    # of the 24 hours before the last 24 hours.
+    volumePrevious24h: BigInt!
+
+    # volumeValue24h represents the swap volume of the last 24 hours in fUSD.
+    volumeValue24h: Float!
+
+    # volumeChange24h represents the change of the last 24 hours volume
+    # against the previous 24 hours.
+    volumeChange24h: Float!
+
+    # price represents the current price of the first token in the second token.
+    price: Float!
+
+    # priceChange24h represents the change of the price during the last 24 hours.
+    priceChange24h: Float!
+
+    # feeApr represents the annual rate of the liquidity fees estimated
+    # from the last 24 hours volume and the current reserves.
+    feeApr: Float!
+
+    # txCount24h represents the number of the pair transactions
+    # during the last 24 hours.
+    txCount24h: Long!
+
+    # txCount represents the total number of the pair transactions.
+    txCount: Long!
+
+    # updated represents the time stamp of the statistics calculation.
+    updated: Long!
+}
+
+# PriceTick represents a price of the native token in a target symbol
+# over a period of time. A single price sample has all the prices equal.
+type PriceTick {
+    # symbol is the target price symbol.
+    symbol: String!
+
+    # time is the starting time of the tick.
+    time: Time!
+
+    # open is the first price of the tick.
+    open: Float!
+
+    # high is the highest price of the tick.
+    high: Float!
+
+    # low is the lowest price of the tick.
+    low: Float!
+
+    # close is the last price of the tick.
+    close: Float!
+}
+
+# Portfolio represents the valuation of all the holdings of an account
+# in a target currency calculated at the given block.
+type Portfolio {
+    # address of the account.
+    address: Address!
+
+    # currency symbol of the valuation.
+    currency: String!
+
+    # block number the valuation was calculated at.
+    block: Long!
+
+    # total value of the priced items in the target currency.
+    total: Float!
+
+    # items of the portfolio.
+    items: [PortfolioItem!]!
+}
+
+# PortfolioItem represents a single holding of an account portfolio.
+# The type is one of NATIVE, DELEGATION, REWARDS, ERC20, FMINT, FLEND and LIQUIDITY.
+# The debt of fMint and fLend positions is represented by an item with negative value.
+type PortfolioItem {
+    # type of the holding.
+    type: String!
+
+    # address of the token held; zero address for the native token.
+    token: Address!
+
+    # symbol of the token held.
+    symbol: String!
+
+    # reference identifies the holding, e.g. the validator ID of a delegation,
+    # the liquidity pair of a position, or collateral/debt of a DeFi position.
+    reference: String!
+
+    # amount of the token held.
+    amount: BigInt!
+
+    # number of decimals of the amount.
+    decimals: Int!
+
+    # price of a single token in the target currency.
+    price: Float!
+
+    # value of the holding in the target currency.
+    value: Float!
+
+    # isPriced signals the value of the holding could be calculated.
+    isPriced: Boolean!
+}
+
+# Log represents an event log emitted by a contract during a transaction execution.
+type Log {
+    # address of the contract emitting the log.
+    address: Address!
+
+    # topics of the log; the first one is usually the event signature.
+    topics: [Bytes32!]!
+
+    # data represents the non-indexed parameters of the event.
+    data: Bytes!
+
+    # blockNumber is the number of the block containing the log.
+    blockNumber: Long!
+
+    # blockHash is the hash of the block containing the log.
+    blockHash: Bytes32!
+
+    # block containing the log.
+    block: Block
+
+    # transactionHash is the hash of the transaction emitting the log.
+    transactionHash: Bytes32!
+
+    # transactionIndex is the index of the transaction in the block.
+    transactionIndex: Long!
+
+    # transaction emitting the log.
+    transaction: Transaction
+
+    # index of the log in the block.
+    index: Long!
+
+    # removed signals the log was reverted due to a chain reorganization.
+    removed: Boolean!
+}
+
+# AccountActivity represents a single on-chain event involving an account.
+type AccountActivity {
+    # address of the account involved.
+    address: Address!
+
+    # type of the activity; one of TRANSACTION, TOKEN_TRANSFER, DELEGATION,
+    # UNDELEGATION, WITHDRAWAL and REWARD_CLAIM.
+    type: String!
+
+    # trxHash is the hash of the transaction the activity comes from.
+    trxHash: Bytes32!
+
+    # transaction the activity comes from.
+    transaction: Transaction
+
+    # timeStamp is the time of the block of the activity in Unix epoch seconds.
+    timeStamp: Long!
+
+    # amount of the activity, if relevant. Amounts of token transfers
+    # are in the token units.
+    amount: BigInt
+
+    # validatorId is the validator of a staking activity, if relevant.
+    validatorId: BigInt
+
+    # tokenTransaction is the token transfer of the activity, if relevant.
+    tokenTransaction: TokenTransaction
+}
+
+# TxPoolStatus represents the number of transactions waiting in the node pool.
+type TxPoolStatus {
+    # pending is the number of transactions ready to be processed.
+    pending: Long!
+
+    # queued is the number of transactions waiting for a nonce gap to close.
+    queued: Long!
+}
+
+# EpochValidatorRewards represents a summary of rewards distributed
+# to a validator in a sealed epoch.
+type EpochValidatorRewards {
+    # ID of the validator.
+    validatorId: BigInt!
+
+    # Amount of stake delegated to the validator in the epoch.
+    receivedStake: BigInt!
+
+    # Reward per token of the stake accumulated in the epoch.
+    rewardPerToken: BigInt!
+
+    # Amount of rewards distributed to the validator delegations in the epoch,
+    # calculated as the received stake times the reward per token. The validator
+    # commission is not distributed per token and is not included.
+    delegatorRewards: BigInt!
+
+    # Fee of transactions originated by the validator in the epoch.
+    originatedFee: BigInt!
+
+    # Uptime of the validator in the epoch in seconds.
+    uptime: Long!
+}
+
+# WebhookFilterInput represents the events filter of a new webhook.
+# The type is one of ACCOUNT_ACTIVITY, TOKEN_TRANSFER, LOGS, EPOCH.
+# ACCOUNT_ACTIVITY requires the address; TOKEN_TRANSFER may be narrowed
+# by the address, the token and the token type; LOGS by the contract
+# address and the topics.
+input WebhookFilterInput {
+    type: String!
+    address: Address
+    token: Address
+    tokenType: String
+    topics: [Bytes32!]
+}
+
+# WebhookFilter represents the events filter of a webhook.
+type WebhookFilter {
+    type: String!
+    address: Address
+    token: Address
+    tokenType: String
+    topics: [Bytes32!]!
+}
+
+# Webhook represents a webhook registration.
+type Webhook {
+    # Unique identifier of the webhook.
+    id: String!
+
+    # Target URL the events are delivered to.
+    url: String!
+
+    # Filter of the delivered events.
+    filter: WebhookFilter!
+
+    # Time of the registration.
+    created: Time!
+
+    # Secret used to sign the deliveries; available on the registration only.
+    secret: String
+}
+
+# WebhookDelivery represents a single event delivery to a webhook.
+type WebhookDelivery {
+    # Unique identifier of the delivery.
+    id: String!
+
+    # Identifier of the webhook.
+    hook: String!
+
+    # Type of the delivered event.
+    event: String!
+
+    # JSON payload of the delivery.
+    payload: String!
+
+    # Status of the delivery; PENDING, DELIVERED or DEAD.
+    status: String!
+
+    # Number of delivery attempts made.
+    attempts: Int!
+
+    # HTTP response code of the last attempt, zero if no response was received.
+    responseCode: Int!
+
+    # Failure of the last attempt, if any.
+    lastError: String
+
+    # Time the delivery was created.
+    created: Time!
+
+    # Time of the last delivery attempt.
+    lastAttempt: Time
+
+    # Time of the next delivery attempt of a pending delivery.
+    nextAttempt: Time
+}
+
+# TransactionStatus represents the lifecycle state of a transaction
+# submitted through the API. The status is one of SUBMITTED, PENDING
+# (seen in the node pool), MINED, DROPPED, or REPLACED (another transaction
+# of the same sender and nonce was mined instead).
+type TransactionStatus {
+    # Hash of the transaction.
+    hash: Bytes32!
+
+    # Current lifecycle state of the transaction.
+    status: String!
+
+    # Address of the sender.
+    from: Address!
+
+    # Sender nonce of the transaction.
+    nonce: Long!
+
+    # Number of the block the transaction was mined in, if mined.
+    blockNumber: Long
+
+    # Number of blocks since the transaction was mined, including its own block.
+    confirmations: Int!
+
+    # Hash of the transaction mined instead of this one, if replaced.
+    replacedBy: Bytes32
+
+    # Time the transaction was submitted.
+    submitted: Time!
+
+    # Time of the last change of the state.
+    updated: Time!
+
+    # The state will not change anymore; mined transactions are final
+    # once they have enough confirmations.
+    final: Boolean!
+}
+
+# StateOverrideInput represents an override of an account state applied
+# to the simulation only. The state replaces the whole storage of the account,
+# the stateDiff replaces the given storage slots only.
+input StateOverrideInput {
+    address: Address!
+    balance: BigInt
+    nonce: Long
+    code: Bytes
+    state: [StorageSlotInput!]
+    stateDiff: [StorageSlotInput!]
+}
+
+# StorageSlotInput represents a value of an account storage slot.
+input StorageSlotInput {
+    key: Bytes32!
+    value: Bytes32!
+}
+
+# DecodedParam represents a single value decoded by the contract ABI.
+# Numbers are decimal, addresses and bytes are hex encoded.
+type DecodedParam {
+    name: String!
+    type: String!
+    value: String!
+}
+
+# SimulatedLog represents an event log emitted by a simulated transaction.
+type SimulatedLog {
+    # Address of the contract emitting the log.
+    address: Address!
+
+    # Topics of the log.
+    topics: [Bytes32!]!
+
+    # Raw data of the log.
+    data: Bytes!
+
+    # Name of the event, if the contract ABI is known.
+    event: String
+
+    # Values of the event, if the contract ABI is known.
+    params: [DecodedParam!]!
+}
+
+# BalanceChange represents the net change of an asset balance of an account
+# caused by a simulated transaction. The asset is one of NATIVE, ERC20,
+# ERC721, ERC1155. The amount is negative if the account loses the asset.
+type BalanceChange {
+    # Address of the account.
+    address: Address!
+
+    # Type of the asset.
+    asset: String!
+
+    # Address of the token contract; null for the native token.
+    token: Address
+
+    # Identifier of the ERC721/ERC1155 token.
+    tokenId: BigInt
+
+    # Signed change of the balance.
+    amount: BigInt!
+}
+
+# TransactionSimulation represents the outcome of a simulated transaction.
+type TransactionSimulation {
+    # The transaction would succeed.
+    success: Boolean!
+
+    # Failure of the transaction, if any.
+    error: String
+
+    # Decoded revert reason of a reverted transaction.
+    revertReason: String
+
+    # Gas consumed by the transaction; zero if the transaction was not traced.
+    gasUsed: Long!
+
+    # Raw data returned by the transaction, or the revert data.
+    returnData: Bytes!
+
+    # Name of the called contract function, if the contract ABI is known.
+    method: String
+
+    # Returned values, if the contract ABI is known.
+    returnValues: [DecodedParam!]!
+
+    # Event logs emitted by the transaction; logs of reverted inner calls are excluded.
+    logs: [SimulatedLog!]!
+
+    # Net balance changes of the accounts involved; the gas fee is not included.
+    balanceChanges: [BalanceChange!]!
+
+    # The transaction was traced by the node. If not, the gas used, the logs,
+    # and the inner transfers are not available.
+    traced: Boolean!
 }
 `
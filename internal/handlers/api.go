@@ -10,7 +10,6 @@ import (
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
-	"github.com/graph-gophers/graphql-transport-ws/graphqlws"
 	"github.com/rs/cors"
 )
 
@@ -30,11 +29,12 @@ func Api(cfg *config.Config, log logger.Logger, rs resolvers.ApiResolver) http.H
 	// Mux to handle separate endpoints cleanly
 	mux := http.NewServeMux()
 
-	// HTTP for queries/mutations
-	mux.Handle("/graphql", corsHandler.Handler(&relay.Handler{Schema: schema}))
+	// HTTP for queries/mutations, server-sent events for subscriptions
+	mux.Handle("/graphql", corsHandler.Handler(EventStream(cfg, log, schema, &relay.Handler{Schema: schema})))
 
 	// WS for subscriptions
-	mux.Handle("/graphql-ws", corsHandler.Handler(graphqlws.NewHandlerFunc(schema, &relay.Handler{Schema: schema})))
+	// both the graphql-transport-ws and the legacy graphql-ws subprotocols are negotiated here
+	mux.Handle("/graphql-ws", corsHandler.Handler(Subscriptions(cfg, log, schema, &relay.Handler{Schema: schema})))
 
 	// Return wrapped handler with logging
 	return &LoggingHandler{
@@ -48,7 +48,7 @@ func corsOptions(cfg *config.Config) cors.Options {
 	return cors.Options{
 		AllowedOrigins: cfg.Server.CorsOrigin,
 		AllowedMethods: []string{"HEAD", "GET", "POST"},
-		AllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With"},
+		AllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization"},
 		MaxAge:         300,
 	}
 }
@@ -3,9 +3,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/config"
 	"ncogearthchain-api-graphql/internal/logger"
 	"ncogearthchain-api-graphql/internal/repository"
+	"ncogearthchain-api-graphql/internal/types"
 	"net/http"
+	"time"
+)
+
+const (
+	// gasPriceStreamKeepAlive is the interval of comments sent to idle stream clients
+	// so proxies on the way do not close the connection.
+	gasPriceStreamKeepAlive = 30 * time.Second
 )
 
 // GasPrice constructs and return the REST API HTTP handler for Gas Price provider.
@@ -30,3 +41,103 @@ func GasPrice(log logger.Logger) http.Handler {
 		}
 	})
 }
+
+// GasPriceStream constructs and return the REST API HTTP handler streaming changes
+// of the gas price estimation to the client as server-sent events. The current
+// estimation is sent first, each change follows as a new "gas" event. If the subscription
+// auth tokens are configured, the client must present one of them as a bearer token
+// in the Authorization header.
+func GasPriceStream(cfg *config.Config, log logger.Logger) http.Handler {
+	tokens := cfg.Subscriptions.AuthTokens
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(tokens) > 0 && !wsTokenValid(tokens, map[string]interface{}{"authorization": r.Header.Get("Authorization")}) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+
+		// the stream outlives the server write timeout
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Errorf("gas price stream not supported; %s", err.Error())
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+
+		// subscribe first so we don't miss a change made while loading the current value
+		sub, err := broker.B().Subscribe(broker.WithConnection(r.Context()), broker.TopicGasPrice)
+		if err != nil {
+			log.Errorf("gas price stream refused; %s", err.Error())
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		defer sub.Close()
+
+		val, err := repository.R().GasPriceExtended()
+		if err != nil {
+			log.Criticalf("can not get gas price; %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		ka := time.NewTicker(gasPriceStreamKeepAlive)
+		defer ka.Stop()
+
+		for {
+			if val != nil {
+				if err := writeGasPriceEvent(w, val); err != nil {
+					log.Debugf("gas price stream closed; %s", err.Error())
+					return
+				}
+				val = nil
+			}
+			if err := rc.Flush(); err != nil {
+				log.Debugf("gas price stream closed; %s", err.Error())
+				return
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ka.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			case evt, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+				val, _ = evt.(*types.GasPrice)
+			}
+		}
+	})
+}
+
+// BrokerStats constructs and return the REST API HTTP handler providing the counters
+// of the subscriptions broker; the number of subscribers, delivered and dropped events.
+// The topic counters are aggregated by the topic prefix, so no subscribed address is exposed.
+func BrokerStats(log logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(broker.B().Stats()); err != nil {
+			log.Criticalf("can not encode broker stats; %s", err.Error())
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// writeGasPriceEvent writes the gas price estimation to the stream as a server-sent event.
+func writeGasPriceEvent(w http.ResponseWriter, val *types.GasPrice) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: gas\ndata: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestGasPriceStreamUnauthorized(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cfg := &config.Config{Log: config.Log{Level: "CRITICAL", Format: "%{message}"}}
+	cfg.Subscriptions.AuthTokens = []string{"secret"}
+	h := GasPriceStream(cfg, logger.New(cfg))
+
+	for _, auth := range []string{"", "other", "Bearer other"} {
+		req := httptest.NewRequest(http.MethodGet, "/json/gas/stream", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(gomega.Equal(http.StatusUnauthorized), auth)
+	}
+}
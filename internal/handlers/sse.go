@@ -0,0 +1,308 @@
+// Package handlers holds HTTP/WS handlers chain along with separate middleware implementations.
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/logger"
+	"net/http"
+	"strings"
+	"text/scanner"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	// sseContentType is the content type of server-sent events streams.
+	sseContentType = "text/event-stream"
+
+	// sseKeepAliveInterval is the interval of comments sent to idle stream clients
+	// so proxies on the way do not close the connection.
+	sseKeepAliveInterval = 15 * time.Second
+
+	// sseMaxRequestSize is the max size of the operation request body.
+	sseMaxRequestSize = 64 * 1024
+
+	// sseEventNext is the event carrying a result of the operation.
+	sseEventNext = "next"
+
+	// sseEventComplete is the event closing the stream.
+	sseEventComplete = "complete"
+
+	// sseOperationSubscription is the only operation type served as an event stream.
+	sseOperationSubscription = "subscription"
+)
+
+// sseHandler implements GraphQL operations, subscriptions especially, served
+// as server-sent events for clients which can not hold websocket connections.
+type sseHandler struct {
+	schema   *graphql.Schema
+	log      logger.Logger
+	tokens   []string
+	fallback http.Handler
+}
+
+// EventStream constructs the HTTP handler serving GraphQL subscriptions as server-sent events
+// to requests accepting the text/event-stream content. Each result of the subscription is sent
+// as a "next" event, the stream ends with a "complete" event. Other operations are refused;
+// if the subscription auth tokens are configured, the client must present one of them
+// as a bearer token in the Authorization header. Other requests are passed to the fallback handler.
+func EventStream(cfg *config.Config, log logger.Logger, schema *graphql.Schema, fallback http.Handler) http.Handler {
+	return &sseHandler{
+		schema:   schema,
+		log:      log,
+		tokens:   cfg.Subscriptions.AuthTokens,
+		fallback: fallback,
+	}
+}
+
+// ExemptStreams routes event stream subscription requests to the streaming handler and all the other
+// requests to the regular handler. The streaming responses must not be cut off by the time limit
+// of the regular requests; other operations asking for an event stream stay limited.
+func ExemptStreams(regular http.Handler, streaming http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isEventStreamRequest(r) {
+			if _, err := sseSubscription(w, r); err == nil {
+				streaming.ServeHTTP(w, r)
+				return
+			}
+		}
+		regular.ServeHTTP(w, r)
+	})
+}
+
+// isEventStreamRequest checks if the client asks for server-sent events.
+func isEventStreamRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), sseContentType)
+}
+
+// ServeHTTP executes the requested operation and streams its results to the client.
+func (h *sseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !isEventStreamRequest(r) {
+		h.fallback.ServeHTTP(w, r)
+		return
+	}
+
+	if len(h.tokens) > 0 && !wsTokenValid(h.tokens, map[string]interface{}{"authorization": r.Header.Get("Authorization")}) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	op, err := sseSubscription(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the stream outlives the server write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.log.Errorf("event stream not supported; %s", err.Error())
+		http.Error(w, "event stream not supported", http.StatusNotImplemented)
+		return
+	}
+
+	// the operation lives as long as the request
+	ctx, cancel := context.WithCancel(broker.WithConnection(r.Context()))
+	defer cancel()
+
+	results, err := h.schema.Subscribe(ctx, op.Query, op.OperationName, op.Variables)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", sseContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		h.log.Debugf("event stream closed; %s", err.Error())
+		return
+	}
+
+	ka := time.NewTicker(sseKeepAliveInterval)
+	defer ka.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ka.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+
+		case res, ok := <-results:
+			if !ok {
+				if err := writeSseEvent(w, sseEventComplete, nil); err != nil {
+					h.log.Debugf("event stream closed; %s", err.Error())
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					h.log.Debugf("event stream closed; %s", err.Error())
+				}
+				return
+			}
+
+			data, err := json.Marshal(res)
+			if err != nil {
+				h.log.Errorf("can not encode event stream response; %s", err.Error())
+				continue
+			}
+			if err := writeSseEvent(w, sseEventNext, data); err != nil {
+				h.log.Debugf("event stream closed; %s", err.Error())
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			h.log.Debugf("event stream closed; %s", err.Error())
+			return
+		}
+	}
+}
+
+// sseSubscription decodes the operation request and makes sure it's a subscription.
+func sseSubscription(w http.ResponseWriter, r *http.Request) (*operationRequest, error) {
+	op, err := sseOperation(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	ot, err := operationType(op.Query, op.OperationName)
+	if err != nil {
+		return nil, err
+	}
+	if ot != sseOperationSubscription {
+		return nil, fmt.Errorf("only subscriptions are served as event streams, %s received", ot)
+	}
+	return op, nil
+}
+
+// sseOperation decodes the operation request. GET requests carry the operation
+// in the query string with JSON encoded variables, POST requests in the JSON body.
+// The body is restored after reading, so the request can be decoded again down the chain.
+func sseOperation(w http.ResponseWriter, r *http.Request) (*operationRequest, error) {
+	var op operationRequest
+
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		op.Query = q.Get("query")
+		op.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &op.Variables); err != nil {
+				return nil, fmt.Errorf("invalid variables; %s", err.Error())
+			}
+		}
+
+	case http.MethodPost:
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, sseMaxRequestSize))
+		if err != nil {
+			return nil, fmt.Errorf("invalid request; %s", err.Error())
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err := json.Unmarshal(body, &op); err != nil {
+			return nil, fmt.Errorf("invalid request; %s", err.Error())
+		}
+
+	default:
+		return nil, fmt.Errorf("method %s not supported", r.Method)
+	}
+
+	if op.Query == "" {
+		return nil, fmt.Errorf("query missing")
+	}
+	return &op, nil
+}
+
+// operationType finds the type of the operation of the given name in the GraphQL document.
+// The name may be omitted if the document contains a single operation. Only the top level
+// of the document is inspected; the full validation is left to the schema.
+func operationType(query string, name string) (string, error) {
+	var sc scanner.Scanner
+	sc.Init(strings.NewReader(query))
+	sc.Mode = scanner.ScanIdents | scanner.ScanInts | scanner.ScanFloats | scanner.ScanStrings
+	sc.Error = func(*scanner.Scanner, string) {}
+
+	var types, names []string
+	var depth, parens int
+	var keyword string
+	expectName := false
+
+	for tok := sc.Scan(); tok != scanner.EOF; tok = sc.Scan() {
+		switch tok {
+		case '#':
+			// comments run to the end of the line
+			for ch := sc.Peek(); ch != '\n' && ch != scanner.EOF; ch = sc.Peek() {
+				sc.Next()
+			}
+			continue
+		case '(':
+			parens++
+		case ')':
+			parens--
+		case '{':
+			// a selection set on the top level without a keyword is an anonymous query
+			if depth == 0 && parens == 0 {
+				if keyword == "" {
+					types, names = append(types, "query"), append(names, "")
+				}
+				keyword = ""
+			}
+			depth++
+		case '}':
+			depth--
+		case scanner.Ident:
+			if depth > 0 || parens > 0 {
+				break
+			}
+			if expectName {
+				names[len(names)-1] = sc.TokenText()
+				break
+			}
+			switch kw := sc.TokenText(); kw {
+			case "query", "mutation", "subscription":
+				types, names = append(types, kw), append(names, "")
+				keyword = kw
+				expectName = true
+				continue
+			case "fragment":
+				keyword = kw
+			}
+		}
+		expectName = false
+	}
+
+	if len(types) == 0 {
+		return "", fmt.Errorf("no operation found")
+	}
+	if name == "" {
+		if len(types) > 1 {
+			return "", fmt.Errorf("operation name required")
+		}
+		return types[0], nil
+	}
+	for i, n := range names {
+		if n == name {
+			return types[i], nil
+		}
+	}
+	return "", fmt.Errorf("operation %s not found", name)
+}
+
+// writeSseEvent writes a single server-sent event with the given data to the stream.
+func writeSseEvent(w http.ResponseWriter, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestOperationType(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	for query, expected := range map[string]string{
+		`{ block { number } }`:                                               "query",
+		`query { block { number } }`:                                         "query",
+		`mutation M { sendTransaction(tx: "0x00") { hash } }`:                "mutation",
+		`subscription { onBlock { number } }`:                                "subscription",
+		`subscription S($b: Long = 1) { onBlock(fromBlock: $b) { number } }`: "subscription",
+		"# subscription { onBlock { number } }\n{ block { number } }":        "query",
+		`fragment F on Block { number } subscription { onBlock { ...F } }`:   "subscription",
+		`query Q($f: Filter = {a: 1}) { logs(filter: $f) { data } }`:         "query",
+	} {
+		ot, err := operationType(query, "")
+		g.Expect(err).To(gomega.BeNil(), query)
+		g.Expect(ot).To(gomega.Equal(expected), query)
+	}
+}
+
+func TestOperationTypeByName(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	doc := `query Q { block { number } } subscription S { onBlock { number } }`
+
+	ot, err := operationType(doc, "S")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(ot).To(gomega.Equal("subscription"))
+
+	ot, err = operationType(doc, "Q")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(ot).To(gomega.Equal("query"))
+
+	// the name is required for multiple operations and must exist
+	_, err = operationType(doc, "")
+	g.Expect(err).ToNot(gomega.BeNil())
+	_, err = operationType(doc, "X")
+	g.Expect(err).ToNot(gomega.BeNil())
+	_, err = operationType(`fragment F on Block { number }`, "")
+	g.Expect(err).ToNot(gomega.BeNil())
+}
@@ -0,0 +1,584 @@
+// Package handlers holds HTTP/WS handlers chain along with separate middleware implementations.
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/logger"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const (
+	// wsProtocolTransport is the subprotocol of the graphql-ws library used by modern clients.
+	wsProtocolTransport = "graphql-transport-ws"
+
+	// wsProtocolLegacy is the subprotocol of the deprecated subscriptions-transport-ws library.
+	wsProtocolLegacy = "graphql-ws"
+
+	// wsInitTimeout is the time a client has to initialize the connection after it's open.
+	wsInitTimeout = 10 * time.Second
+
+	// wsKeepAliveInterval is the interval of the keep alive messages sent to the client.
+	wsKeepAliveInterval = 15 * time.Second
+
+	// wsPongWait is the time the client has to respond to the websocket ping
+	// before the connection is considered dead.
+	wsPongWait = 3 * wsKeepAliveInterval
+
+	// wsWriteTimeout is the max time of a single message write.
+	wsWriteTimeout = 5 * time.Second
+
+	// wsReadLimit is the max size of a message accepted from the client.
+	wsReadLimit = 64 * 1024
+)
+
+// close codes of the graphql-transport-ws protocol
+const (
+	wsCloseInvalidMessage   = 4400
+	wsCloseUnauthorized     = 4401
+	wsCloseForbidden        = 4403
+	wsCloseInitTimeout      = 4408
+	wsCloseSubscriberExists = 4409
+	wsCloseTooManyInits     = 4429
+)
+
+// message types of the graphql-transport-ws protocol
+const (
+	wsMsgConnectionInit = "connection_init"
+	wsMsgConnectionAck  = "connection_ack"
+	wsMsgPing           = "ping"
+	wsMsgPong           = "pong"
+	wsMsgSubscribe      = "subscribe"
+	wsMsgNext           = "next"
+	wsMsgError          = "error"
+	wsMsgComplete       = "complete"
+)
+
+// message types of the legacy subscriptions-transport-ws protocol
+const (
+	wsMsgLegacyConnectionError     = "connection_error"
+	wsMsgLegacyConnectionTerminate = "connection_terminate"
+	wsMsgLegacyKeepAlive           = "ka"
+	wsMsgLegacyStart               = "start"
+	wsMsgLegacyData                = "data"
+	wsMsgLegacyStop                = "stop"
+)
+
+// errWsForbidden is returned if the connection_init payload does not carry a valid auth token.
+var errWsForbidden = errors.New("forbidden")
+
+// wsMessage represents a message of both websocket protocols.
+type wsMessage struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// operationRequest represents the payload of an operation request.
+type operationRequest struct {
+	OperationName string                 `json:"operationName"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// wsHandler implements GraphQL over websocket for both the graphql-transport-ws
+// and the legacy graphql-ws subprotocols on the same endpoint.
+type wsHandler struct {
+	schema   *graphql.Schema
+	log      logger.Logger
+	tokens   []string
+	upgrader websocket.Upgrader
+	fallback http.Handler
+
+	// connection timing; the init timeout, the keep alive interval and the pong wait
+	initTimeout time.Duration
+	keepAlive   time.Duration
+	pongWait    time.Duration
+}
+
+// wsConnection represents a single websocket client connection.
+type wsConnection struct {
+	ws     *websocket.Conn
+	legacy bool
+	h      *wsHandler
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// write lock; the websocket supports only one writer at a time
+	wmu sync.Mutex
+
+	// active operations and the connection state
+	mu    sync.Mutex
+	ops   map[string]context.CancelFunc
+	init  bool
+	acked bool
+}
+
+// Subscriptions constructs the HTTP handler serving GraphQL operations over websocket.
+// The subprotocol is negotiated with the client; the graphql-transport-ws is preferred,
+// the legacy graphql-ws is served for older clients. Requests not asking for any of them
+// are passed to the fallback handler.
+func Subscriptions(cfg *config.Config, log logger.Logger, schema *graphql.Schema, fallback http.Handler) http.Handler {
+	return &wsHandler{
+		schema:   schema,
+		log:      log,
+		tokens:   cfg.Subscriptions.AuthTokens,
+		fallback: fallback,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{wsProtocolTransport, wsProtocolLegacy},
+			CheckOrigin:  wsOriginChecker(cfg.Server.CorsOrigin),
+		},
+		initTimeout: wsInitTimeout,
+		keepAlive:   wsKeepAliveInterval,
+		pongWait:    wsPongWait,
+	}
+}
+
+// wsOriginChecker builds the websocket origin check from the list of allowed CORS origins.
+func wsOriginChecker(origins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		for _, o := range origins {
+			if o == "*" || origin == "" || strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ServeHTTP upgrades the request to a websocket connection and serves it.
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) || !wsProtocolOffered(r) {
+		w.Header().Set("X-WebSocket-Upgrade-Failure", "no subprotocols available")
+		h.fallback.ServeHTTP(w, r)
+		return
+	}
+
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.log.Debugf("websocket upgrade failed; %s", err.Error())
+		return
+	}
+
+	// the connection outlives the request; subscriptions are limited per connection
+	ctx, cancel := context.WithCancel(broker.WithConnection(context.Background()))
+	conn := wsConnection{
+		ws:     ws,
+		legacy: ws.Subprotocol() == wsProtocolLegacy,
+		h:      h,
+		ctx:    ctx,
+		cancel: cancel,
+		ops:    make(map[string]context.CancelFunc),
+	}
+	go conn.run()
+}
+
+// wsProtocolOffered checks if the client offers any of the supported subprotocols.
+func wsProtocolOffered(r *http.Request) bool {
+	for _, p := range websocket.Subprotocols(r) {
+		if p == wsProtocolTransport || p == wsProtocolLegacy {
+			return true
+		}
+	}
+	return false
+}
+
+// run reads and handles the client messages until the connection is closed.
+func (c *wsConnection) run() {
+	// the keep alive routine closes the socket once the connection context is done
+	defer c.cancel()
+
+	c.ws.SetReadLimit(wsReadLimit)
+	c.alive()
+	c.ws.SetPongHandler(func(string) error {
+		c.alive()
+		return nil
+	})
+	go c.keepAlive()
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.alive()
+
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if c.legacy {
+				c.send("", wsMsgLegacyConnectionError, wsErrorPayload(errors.New("invalid message received")))
+				continue
+			}
+			c.closeWith(wsCloseInvalidMessage, "Invalid message received")
+			return
+		}
+
+		var ok bool
+		if c.legacy {
+			ok = c.handleLegacy(&msg)
+		} else {
+			ok = c.handle(&msg)
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// alive extends the read deadline of the connection after a sign of life from the client.
+func (c *wsConnection) alive() {
+	if err := c.ws.SetReadDeadline(time.Now().Add(c.h.pongWait)); err != nil {
+		c.h.log.Debugf("websocket read deadline not set; %s", err.Error())
+	}
+}
+
+// keepAlive pings the client periodically and closes the connection
+// if the client does not initialize it in time. The socket is closed on exit.
+func (c *wsConnection) keepAlive() {
+	defer func() {
+		if err := c.ws.Close(); err != nil {
+			c.h.log.Debugf("websocket not closed; %s", err.Error())
+		}
+	}()
+
+	initTimer := time.NewTimer(c.h.initTimeout)
+	defer initTimer.Stop()
+
+	ticker := time.NewTicker(c.h.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-initTimer.C:
+			if !c.isAcked() {
+				c.closeWith(wsCloseInitTimeout, "Connection initialisation timeout")
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				c.cancel()
+				return
+			}
+
+			// protocol level keep alive for clients which watch the connection themselves
+			if c.isAcked() {
+				if c.legacy {
+					c.send("", wsMsgLegacyKeepAlive, nil)
+				} else {
+					c.send("", wsMsgPing, nil)
+				}
+			}
+		}
+	}
+}
+
+// handle processes a message of the graphql-transport-ws protocol.
+// It returns false if the connection should be closed.
+func (c *wsConnection) handle(msg *wsMessage) bool {
+	switch msg.Type {
+	case wsMsgConnectionInit:
+		if !c.setInit() {
+			c.closeWith(wsCloseTooManyInits, "Too many initialisation requests")
+			return false
+		}
+		if err := c.authorize(msg.Payload); err != nil {
+			c.closeWith(wsCloseForbidden, "Forbidden")
+			return false
+		}
+		c.send("", wsMsgConnectionAck, nil)
+
+	case wsMsgPing:
+		c.send("", wsMsgPong, nil)
+
+	case wsMsgPong:
+		// the response to our ping, the connection is alive
+
+	case wsMsgSubscribe:
+		if !c.isAcked() {
+			c.closeWith(wsCloseUnauthorized, "Unauthorized")
+			return false
+		}
+
+		var op operationRequest
+		if msg.Id == "" || json.Unmarshal(msg.Payload, &op) != nil {
+			c.closeWith(wsCloseInvalidMessage, "Invalid message received")
+			return false
+		}
+		if !c.start(msg.Id, &op) {
+			c.closeWith(wsCloseSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", msg.Id))
+			return false
+		}
+
+	case wsMsgComplete:
+		c.stop(msg.Id)
+
+	default:
+		c.closeWith(wsCloseInvalidMessage, "Invalid message received")
+		return false
+	}
+	return true
+}
+
+// handleLegacy processes a message of the legacy subscriptions-transport-ws protocol.
+// It returns false if the connection should be closed.
+func (c *wsConnection) handleLegacy(msg *wsMessage) bool {
+	switch msg.Type {
+	case wsMsgConnectionInit:
+		c.setInit()
+		if err := c.authorize(msg.Payload); err != nil {
+			c.send("", wsMsgLegacyConnectionError, wsErrorPayload(err))
+			c.closeWith(websocket.ClosePolicyViolation, err.Error())
+			return false
+		}
+		c.send("", wsMsgConnectionAck, nil)
+		c.send("", wsMsgLegacyKeepAlive, nil)
+
+	case wsMsgLegacyStart:
+		if msg.Id == "" {
+			c.send("", wsMsgLegacyConnectionError, wsErrorPayload(errors.New("missing ID for start operation")))
+			return true
+		}
+		if !c.isAcked() {
+			c.send(msg.Id, wsMsgError, wsErrorPayload(errors.New("connection not initialised")))
+			return true
+		}
+
+		var op operationRequest
+		if err := json.Unmarshal(msg.Payload, &op); err != nil {
+			c.send(msg.Id, wsMsgError, wsErrorPayload(fmt.Errorf("invalid payload for type: %s", msg.Type)))
+			return true
+		}
+		if !c.start(msg.Id, &op) {
+			c.send("", wsMsgLegacyConnectionError, wsErrorPayload(errors.New("duplicate message ID for start operation")))
+		}
+
+	case wsMsgLegacyStop:
+		c.stop(msg.Id)
+		c.send(msg.Id, wsMsgComplete, nil)
+
+	case wsMsgLegacyConnectionTerminate:
+		return false
+
+	case wsMsgLegacyKeepAlive:
+		// the client keep alive, the connection is alive
+
+	default:
+		c.send(msg.Id, wsMsgError, wsErrorPayload(fmt.Errorf("unknown operation message of type: %s", msg.Type)))
+	}
+	return true
+}
+
+// authorize checks the connection_init payload for a valid auth token, if the tokens are configured.
+// The token is accepted in the "token" field, or as a bearer token in the "authorization" field.
+func (c *wsConnection) authorize(payload json.RawMessage) error {
+	if len(c.h.tokens) > 0 {
+		var params map[string]interface{}
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &params); err != nil {
+				return errWsForbidden
+			}
+		}
+		if !wsTokenValid(c.h.tokens, params) {
+			return errWsForbidden
+		}
+	}
+
+	c.mu.Lock()
+	c.acked = true
+	c.mu.Unlock()
+	return nil
+}
+
+// wsTokenValid checks the auth token of the connection params against the list of valid tokens.
+func wsTokenValid(tokens []string, params map[string]interface{}) bool {
+	token, _ := params["token"].(string)
+	if token == "" {
+		if auth, ok := params["authorization"].(string); ok {
+			token = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		}
+	}
+	if token == "" {
+		return false
+	}
+
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// setInit marks the connection initialisation requested; it returns false if it was already requested.
+func (c *wsConnection) setInit() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.init {
+		return false
+	}
+	c.init = true
+	return true
+}
+
+// isAcked checks if the connection has been initialized and acknowledged.
+func (c *wsConnection) isAcked() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.acked
+}
+
+// start registers a new operation and executes it. It returns false if an operation
+// with the same id is already active.
+func (c *wsConnection) start(id string, op *operationRequest) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ops[id]; ok {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.ops[id] = cancel
+	go c.execute(ctx, id, op)
+	return true
+}
+
+// stop terminates the operation of the given id on the client request.
+func (c *wsConnection) stop(id string) {
+	if cancel, ok := c.remove(id); ok {
+		cancel()
+	}
+}
+
+// remove drops the operation of the given id from the list of active operations.
+func (c *wsConnection) remove(id string) (context.CancelFunc, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cancel, ok := c.ops[id]
+	if ok {
+		delete(c.ops, id)
+	}
+	return cancel, ok
+}
+
+// execute runs the operation and streams its results to the client until the operation
+// finishes, the client stops it, or the connection is closed.
+func (c *wsConnection) execute(ctx context.Context, id string, op *operationRequest) {
+	results, err := c.h.schema.Subscribe(ctx, op.Query, op.OperationName, op.Variables)
+	if err != nil {
+		c.fail(id, []*gqlerrors.QueryError{gqlerrors.Errorf("%s", err)})
+		return
+	}
+
+	first := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case res, ok := <-results:
+			if !ok {
+				// the operation is done; tell the client unless the client stopped it already
+				if cancel, ok := c.remove(id); ok {
+					cancel()
+					c.send(id, wsMsgComplete, nil)
+				}
+				return
+			}
+
+			resp, ok := res.(*graphql.Response)
+			if !ok {
+				continue
+			}
+
+			// the operation was refused before any execution (invalid query, refused subscription)
+			if first && resp.Data == nil && len(resp.Errors) > 0 && !c.legacy {
+				c.fail(id, resp.Errors)
+				return
+			}
+			first = false
+
+			data, err := json.Marshal(resp)
+			if err != nil {
+				c.h.log.Errorf("can not encode websocket response; %s", err.Error())
+				continue
+			}
+			if c.legacy {
+				c.send(id, wsMsgLegacyData, data)
+			} else {
+				c.send(id, wsMsgNext, data)
+			}
+		}
+	}
+}
+
+// fail terminates the operation with the given errors.
+func (c *wsConnection) fail(id string, list []*gqlerrors.QueryError) {
+	cancel, ok := c.remove(id)
+	if !ok {
+		return
+	}
+	cancel()
+
+	// the legacy protocol reports a single error and completes the operation explicitly
+	if c.legacy {
+		c.send(id, wsMsgError, wsErrorPayload(list[0]))
+		c.send(id, wsMsgComplete, nil)
+		return
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		c.h.log.Errorf("can not encode websocket errors; %s", err.Error())
+		return
+	}
+	c.send(id, wsMsgError, data)
+}
+
+// send writes a message to the client.
+func (c *wsConnection) send(id string, typ string, payload json.RawMessage) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if err := c.ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		c.cancel()
+		return
+	}
+	if err := c.ws.WriteJSON(wsMessage{Id: id, Type: typ, Payload: payload}); err != nil {
+		c.h.log.Debugf("websocket message %s not sent; %s", typ, err.Error())
+		c.cancel()
+	}
+}
+
+// closeWith sends the close code and reason to the client and terminates the connection.
+func (c *wsConnection) closeWith(code int, reason string) {
+	err := c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteTimeout))
+	if err != nil {
+		c.h.log.Debugf("websocket close not sent; %s", err.Error())
+	}
+	c.cancel()
+}
+
+// wsErrorPayload builds the error payload of the legacy protocol.
+func wsErrorPayload(err error) json.RawMessage {
+	data, _ := json.Marshal(struct {
+		Message string `json:"message"`
+	}{Message: err.Error()})
+	return data
+}
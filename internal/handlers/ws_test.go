@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/onsi/gomega"
+)
+
+// wsTestSchema represents a minimal schema with a finite and an endless subscription.
+const wsTestSchema = `
+schema {
+    query: Query
+    subscription: Subscription
+}
+
+type Query {
+    hello: String!
+}
+
+type Subscription {
+    count(n: Int!): Int!
+    wait: Int!
+}`
+
+// wsTestReadTimeout is the max time the test client waits for a message.
+const wsTestReadTimeout = 2 * time.Second
+
+// wsTestResolver implements the resolvers of the test schema.
+type wsTestResolver struct{}
+
+// Hello resolves the only query of the test schema.
+func (*wsTestResolver) Hello() string {
+	return "hello"
+}
+
+// Count sends the numbers from zero up to n and ends the subscription.
+func (*wsTestResolver) Count(args struct{ N int32 }) <-chan int32 {
+	c := make(chan int32, args.N)
+	for i := int32(0); i < args.N; i++ {
+		c <- i
+	}
+	close(c)
+	return c
+}
+
+// Wait never sends anything, the subscription ends with the context.
+func (*wsTestResolver) Wait(ctx context.Context) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		<-ctx.Done()
+		close(c)
+	}()
+	return c
+}
+
+// newWsTestServer starts a test server of the websocket handler with the given auth tokens and timing.
+func newWsTestServer(tokens []string, initTimeout time.Duration, keepAlive time.Duration) *httptest.Server {
+	cfg := &config.Config{Log: config.Log{Level: "CRITICAL", Format: "%{message}"}}
+	cfg.Server.CorsOrigin = []string{"*"}
+	cfg.Subscriptions.AuthTokens = tokens
+
+	h := Subscriptions(cfg, logger.New(cfg), graphql.MustParseSchema(wsTestSchema, &wsTestResolver{}), http.NotFoundHandler()).(*wsHandler)
+	h.initTimeout = initTimeout
+	h.keepAlive = keepAlive
+	h.pongWait = time.Minute
+	return httptest.NewServer(h)
+}
+
+// wsDial opens a websocket connection to the test server with the given subprotocol.
+func wsDial(g *gomega.WithT, srv *httptest.Server, protocol string) *websocket.Conn {
+	d := websocket.Dialer{Subprotocols: []string{protocol}}
+	ws, _, err := d.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(ws.Subprotocol()).To(gomega.Equal(protocol))
+	return ws
+}
+
+// wsSend writes a protocol message to the server.
+func wsSend(g *gomega.WithT, ws *websocket.Conn, id string, typ string, payload interface{}) {
+	msg := wsMessage{Id: id, Type: typ}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		g.Expect(err).To(gomega.BeNil())
+		msg.Payload = data
+	}
+	g.Expect(ws.WriteJSON(msg)).To(gomega.Succeed())
+}
+
+// wsRead reads the next protocol message from the server.
+func wsRead(g *gomega.WithT, ws *websocket.Conn) wsMessage {
+	g.Expect(ws.SetReadDeadline(time.Now().Add(wsTestReadTimeout))).To(gomega.Succeed())
+
+	var msg wsMessage
+	g.Expect(ws.ReadJSON(&msg)).To(gomega.Succeed())
+	return msg
+}
+
+// wsCloseCode reads from the server until the connection is closed and provides the close code.
+func wsCloseCode(g *gomega.WithT, ws *websocket.Conn) int {
+	g.Expect(ws.SetReadDeadline(time.Now().Add(wsTestReadTimeout))).To(gomega.Succeed())
+	for {
+		if _, _, err := ws.ReadMessage(); err != nil {
+			var ce *websocket.CloseError
+			g.Expect(errors.As(err, &ce)).To(gomega.BeTrue(), err.Error())
+			return ce.Code
+		}
+	}
+}
+
+// wsInit initializes the connection and checks it's acknowledged.
+func wsInit(g *gomega.WithT, ws *websocket.Conn, payload interface{}) {
+	wsSend(g, ws, "", wsMsgConnectionInit, payload)
+	g.Expect(wsRead(g, ws).Type).To(gomega.Equal(wsMsgConnectionAck))
+}
+
+func TestWsFallback(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer(nil, time.Minute, time.Minute)
+	defer srv.Close()
+
+	// requests without any supported subprotocol are not upgraded
+	d := websocket.Dialer{Subprotocols: []string{"other"}}
+	_, resp, err := d.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	g.Expect(err).ToNot(gomega.BeNil())
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusNotFound))
+}
+
+func TestWsTransportInit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer(nil, time.Minute, time.Minute)
+	defer srv.Close()
+
+	ws := wsDial(g, srv, wsProtocolTransport)
+	defer ws.Close()
+
+	wsInit(g, ws, nil)
+
+	// the client ping is answered
+	wsSend(g, ws, "", wsMsgPing, nil)
+	g.Expect(wsRead(g, ws).Type).To(gomega.Equal(wsMsgPong))
+
+	// the connection can not be initialized twice
+	wsSend(g, ws, "", wsMsgConnectionInit, nil)
+	g.Expect(wsCloseCode(g, ws)).To(gomega.Equal(wsCloseTooManyInits))
+}
+
+func TestWsTransportAuth(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer([]string{"secret"}, time.Minute, time.Minute)
+	defer srv.Close()
+
+	for _, params := range []map[string]string{
+		{"token": "secret"},
+		{"authorization": "Bearer secret"},
+	} {
+		ws := wsDial(g, srv, wsProtocolTransport)
+		wsInit(g, ws, params)
+		g.Expect(ws.Close()).To(gomega.Succeed())
+	}
+
+	for _, params := range []map[string]string{
+		nil,
+		{"token": "other"},
+		{"authorization": "Bearer other"},
+	} {
+		ws := wsDial(g, srv, wsProtocolTransport)
+		wsSend(g, ws, "", wsMsgConnectionInit, params)
+		g.Expect(wsCloseCode(g, ws)).To(gomega.Equal(wsCloseForbidden))
+		g.Expect(ws.Close()).To(gomega.Succeed())
+	}
+}
+
+func TestWsTransportInitTimeout(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer(nil, 50*time.Millisecond, time.Minute)
+	defer srv.Close()
+
+	ws := wsDial(g, srv, wsProtocolTransport)
+	defer ws.Close()
+
+	g.Expect(wsCloseCode(g, ws)).To(gomega.Equal(wsCloseInitTimeout))
+}
+
+func TestWsTransportUnauthorized(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer(nil, time.Minute, time.Minute)
+	defer srv.Close()
+
+	ws := wsDial(g, srv, wsProtocolTransport)
+	defer ws.Close()
+
+	// operations are refused before the connection is initialized
+	wsSend(g, ws, "1", wsMsgSubscribe, operationRequest{Query: `subscription { count(n: 1) }`})
+	g.Expect(wsCloseCode(g, ws)).To(gomega.Equal(wsCloseUnauthorized))
+}
+
+func TestWsTransportKeepAlive(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer(nil, time.Minute, 50*time.Millisecond)
+	defer srv.Close()
+
+	ws := wsDial(g, srv, wsProtocolTransport)
+	defer ws.Close()
+
+	wsInit(g, ws, nil)
+
+	// the server pings the initialized client, the client answers
+	for i := 0; i < 2; i++ {
+		g.Expect(wsRead(g, ws).Type).To(gomega.Equal(wsMsgPing))
+		wsSend(g, ws, "", wsMsgPong, nil)
+	}
+}
+
+func TestWsTransportSubscribe(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer(nil, time.Minute, time.Minute)
+	defer srv.Close()
+
+	ws := wsDial(g, srv, wsProtocolTransport)
+	defer ws.Close()
+
+	wsInit(g, ws, nil)
+
+	// the results are streamed, the finished operation is completed
+	wsSend(g, ws, "1", wsMsgSubscribe, operationRequest{Query: `subscription { count(n: 2) }`})
+	for _, data := range []string{`{"data":{"count":0}}`, `{"data":{"count":1}}`} {
+		msg := wsRead(g, ws)
+		g.Expect(msg.Id).To(gomega.Equal("1"))
+		g.Expect(msg.Type).To(gomega.Equal(wsMsgNext))
+		g.Expect(string(msg.Payload)).To(gomega.MatchJSON(data))
+	}
+	msg := wsRead(g, ws)
+	g.Expect(msg.Id).To(gomega.Equal("1"))
+	g.Expect(msg.Type).To(gomega.Equal(wsMsgComplete))
+
+	// an invalid operation is reported as an error
+	wsSend(g, ws, "2", wsMsgSubscribe, operationRequest{Query: `subscription { unknown }`})
+	msg = wsRead(g, ws)
+	g.Expect(msg.Id).To(gomega.Equal("2"))
+	g.Expect(msg.Type).To(gomega.Equal(wsMsgError))
+
+	// the operation stopped by the client releases its id
+	wsSend(g, ws, "3", wsMsgSubscribe, operationRequest{Query: `subscription { wait }`})
+	wsSend(g, ws, "3", wsMsgComplete, nil)
+	wsSend(g, ws, "3", wsMsgSubscribe, operationRequest{Query: `subscription { count(n: 1) }`})
+	msg = wsRead(g, ws)
+	g.Expect(msg.Id).To(gomega.Equal("3"))
+	g.Expect(msg.Type).To(gomega.Equal(wsMsgNext))
+}
+
+func TestWsTransportDuplicateId(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer(nil, time.Minute, time.Minute)
+	defer srv.Close()
+
+	ws := wsDial(g, srv, wsProtocolTransport)
+	defer ws.Close()
+
+	wsInit(g, ws, nil)
+
+	wsSend(g, ws, "1", wsMsgSubscribe, operationRequest{Query: `subscription { wait }`})
+	wsSend(g, ws, "1", wsMsgSubscribe, operationRequest{Query: `subscription { wait }`})
+	g.Expect(wsCloseCode(g, ws)).To(gomega.Equal(wsCloseSubscriberExists))
+}
+
+func TestWsLegacyInit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer([]string{"secret"}, time.Minute, 50*time.Millisecond)
+	defer srv.Close()
+
+	// the refused connection is told why before it's closed
+	ws := wsDial(g, srv, wsProtocolLegacy)
+	wsSend(g, ws, "", wsMsgConnectionInit, map[string]string{"token": "other"})
+	g.Expect(wsRead(g, ws).Type).To(gomega.Equal(wsMsgLegacyConnectionError))
+	g.Expect(wsCloseCode(g, ws)).To(gomega.Equal(websocket.ClosePolicyViolation))
+	g.Expect(ws.Close()).To(gomega.Succeed())
+
+	// the accepted connection is acknowledged and kept alive
+	ws = wsDial(g, srv, wsProtocolLegacy)
+	defer ws.Close()
+
+	wsInit(g, ws, map[string]string{"token": "secret"})
+	for i := 0; i < 3; i++ {
+		g.Expect(wsRead(g, ws).Type).To(gomega.Equal(wsMsgLegacyKeepAlive))
+	}
+}
+
+func TestWsLegacyStartStop(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	srv := newWsTestServer(nil, time.Minute, time.Minute)
+	defer srv.Close()
+
+	ws := wsDial(g, srv, wsProtocolLegacy)
+	defer ws.Close()
+
+	wsInit(g, ws, nil)
+	g.Expect(wsRead(g, ws).Type).To(gomega.Equal(wsMsgLegacyKeepAlive))
+
+	// the results are streamed as data, the finished operation is completed
+	wsSend(g, ws, "1", wsMsgLegacyStart, operationRequest{Query: `subscription { count(n: 2) }`})
+	for _, data := range []string{`{"data":{"count":0}}`, `{"data":{"count":1}}`} {
+		msg := wsRead(g, ws)
+		g.Expect(msg.Id).To(gomega.Equal("1"))
+		g.Expect(msg.Type).To(gomega.Equal(wsMsgLegacyData))
+		g.Expect(string(msg.Payload)).To(gomega.MatchJSON(data))
+	}
+	msg := wsRead(g, ws)
+	g.Expect(msg.Id).To(gomega.Equal("1"))
+	g.Expect(msg.Type).To(gomega.Equal(wsMsgComplete))
+
+	// a duplicate id is reported, the connection stays
+	wsSend(g, ws, "2", wsMsgLegacyStart, operationRequest{Query: `subscription { wait }`})
+	wsSend(g, ws, "2", wsMsgLegacyStart, operationRequest{Query: `subscription { wait }`})
+	g.Expect(wsRead(g, ws).Type).To(gomega.Equal(wsMsgLegacyConnectionError))
+
+	// the stopped operation is completed
+	wsSend(g, ws, "2", wsMsgLegacyStop, nil)
+	msg = wsRead(g, ws)
+	g.Expect(msg.Id).To(gomega.Equal("2"))
+	g.Expect(msg.Type).To(gomega.Equal(wsMsgComplete))
+
+	// the client keep alive is accepted, the connection terminates on request
+	wsSend(g, ws, "", wsMsgLegacyKeepAlive, nil)
+	wsSend(g, ws, "", wsMsgLegacyConnectionTerminate, nil)
+	g.Expect(ws.SetReadDeadline(time.Now().Add(wsTestReadTimeout))).To(gomega.Succeed())
+	_, _, err := ws.ReadMessage()
+	g.Expect(err).ToNot(gomega.BeNil())
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"encoding/json"
 	"ncogearthchain-api-graphql/internal/types"
 	"strings"
 
@@ -10,6 +11,9 @@ import (
 // lastEpochCacheKey represents the in-memory cache key for the latest sealed Epoch data.
 const epochCacheKey = "epoch"
 
+// epochRewardsCacheKey represents the in-memory cache key prefix for the validator rewards of a sealed epoch.
+const epochRewardsCacheKey = "epoch_rewards"
+
 // epochKey generates cache key for the given epoch number.
 func epochKey(id *hexutil.Uint64) string {
 	var sb strings.Builder
@@ -18,6 +22,14 @@ func epochKey(id *hexutil.Uint64) string {
 	return sb.String()
 }
 
+// epochRewardsKey generates cache key for the validator rewards of the given epoch.
+func epochRewardsKey(id *hexutil.Uint64) string {
+	var sb strings.Builder
+	sb.WriteString(epochRewardsCacheKey)
+	sb.WriteString(id.String())
+	return sb.String()
+}
+
 // PullEpoch extracts information about the given Epoch from the in-memory cache if available.
 func (b *MemBridge) PullEpoch(id *hexutil.Uint64) *types.Epoch {
 	// try to get the Epoch data from the cache
@@ -55,3 +67,31 @@ func (b *MemBridge) PushEpoch(ep *types.Epoch) {
 		b.log.Errorf("can not cache epoch #%d; %s", ep.Id, err.Error())
 	}
 }
+
+// PullEpochValidatorRewards extracts the validator rewards of the given sealed epoch from the in-memory cache if available.
+func (b *MemBridge) PullEpochValidatorRewards(id *hexutil.Uint64) []types.EpochValidatorRewards {
+	data, err := b.cache.Get(epochRewardsKey(id))
+	if err != nil {
+		return nil
+	}
+
+	var list []types.EpochValidatorRewards
+	if err := json.Unmarshal(data, &list); err != nil {
+		b.log.Criticalf("can not decode epoch rewards from in-memory cache; %s", err.Error())
+		return nil
+	}
+	return list
+}
+
+// PushEpochValidatorRewards stores the validator rewards of the given sealed epoch in the in-memory cache.
+func (b *MemBridge) PushEpochValidatorRewards(id *hexutil.Uint64, list []types.EpochValidatorRewards) {
+	data, err := json.Marshal(list)
+	if err != nil {
+		b.log.Criticalf("can not marshal epoch rewards to JSON; %s", err.Error())
+		return
+	}
+
+	if err := b.cache.Set(epochRewardsKey(id), data); err != nil {
+		b.log.Errorf("can not cache rewards of epoch #%d; %s", *id, err.Error())
+	}
+}
@@ -0,0 +1,60 @@
+// Package cache implements bridge to fast in-memory object cache.
+package cache
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/types"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// portfolioPrefix represents a prefix used for account portfolio caching key.
+const portfolioPrefix = "pf"
+
+// portfolioKey generates cache key for account portfolio entry valid at the given block.
+func portfolioKey(addr *common.Address, currency string, block hexutil.Uint64) string {
+	var sb strings.Builder
+	sb.WriteString(portfolioPrefix)
+	sb.WriteString(addr.String())
+	sb.WriteString(strings.ToUpper(currency))
+	sb.WriteString(block.String())
+	return sb.String()
+}
+
+// PushPortfolio stores account portfolio in the in-memory cache.
+func (b *MemBridge) PushPortfolio(pf *types.Portfolio) error {
+	// we need valid portfolio
+	if nil == pf {
+		return fmt.Errorf("undefined portfolio can not be pushed to the in-memory cache")
+	}
+
+	// encode the portfolio
+	data, err := pf.Marshal()
+	if err != nil {
+		b.log.Criticalf("can not marshal portfolio to JSON; %s", err.Error())
+		return err
+	}
+
+	// set the data to cache
+	return b.cache.Set(portfolioKey(&pf.Address, pf.Currency, pf.Block), data)
+}
+
+// PullPortfolio tries to load account portfolio valid at the given block from the cache.
+func (b *MemBridge) PullPortfolio(addr *common.Address, currency string, block hexutil.Uint64) *types.Portfolio {
+	// try to get the data from cache
+	data, err := b.cache.Get(portfolioKey(addr, currency, block))
+	if err != nil {
+		// cache returns ErrEntryNotFound if the key does not exist
+		return nil
+	}
+
+	// do we have the data?
+	pf, err := types.UnmarshalPortfolio(data)
+	if err != nil {
+		b.log.Criticalf("can not decode portfolio from in-memory cache; %s", err.Error())
+		return nil
+	}
+	return pf
+}
@@ -2,8 +2,16 @@
 package cache
 
 import (
-	"github.com/ethereum/go-ethereum/common"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
 	"strings"
+	"time"
+
+	"github.com/allegro/bigcache"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // uniswapPairTokensPrefix represents a prefix used for uniswap pair tokens caching key.
@@ -91,3 +99,125 @@ func (b *MemBridge) PullAllPairsList() []common.Address {
 	}
 	return list
 }
+
+// EvictAllPairsList removes the list of all uniswap pairs from memory cache
+// so it's reloaded on the next access.
+func (b *MemBridge) EvictAllPairsList() {
+	if err := b.cache.Delete(uniswapPairListKey); err != nil && err != bigcache.ErrEntryNotFound {
+		b.log.Errorf("can not evict uniswap pairs list; %s", err.Error())
+	}
+}
+
+// uniswapPairReservesPrefix represents a prefix used for uniswap pair reserves caching key.
+const uniswapPairReservesPrefix = "unr"
+
+// uniswapPairReservesTTL represents the max age of cached uniswap pair reserves.
+// Reserves are also evicted on the pair Sync event, but the event may come late
+// if the dispatcher is catching up.
+const uniswapPairReservesTTL = 5 * time.Second
+
+// uniswapPairReservesKey generates cache key for uniswap pair reserves entry.
+func uniswapPairReservesKey(pair *common.Address) string {
+	var sb strings.Builder
+	sb.WriteString(uniswapPairReservesPrefix)
+	sb.WriteString(pair.String())
+	return sb.String()
+}
+
+// PushUniswapReserves stores uniswap pair reserves in the in-memory cache.
+func (b *MemBridge) PushUniswapReserves(pair *common.Address, rs []hexutil.Big) {
+	// nothing to store or bad data?
+	if pair == nil || rs == nil || 2 != len(rs) {
+		return
+	}
+
+	// reserves are uint112 values, so 32 bytes for each of them is more than enough;
+	// the time of the push follows the reserves
+	data := make([]byte, 72)
+	rs[0].ToInt().FillBytes(data[:32])
+	rs[1].ToInt().FillBytes(data[32:64])
+	binary.BigEndian.PutUint64(data[64:], uint64(time.Now().UnixNano()))
+
+	if err := b.cache.Set(uniswapPairReservesKey(pair), data); err != nil {
+		b.log.Errorf("can not store uniswap pair %s reserves; %s", pair.String(), err.Error())
+	}
+}
+
+// PullUniswapReserves tries to load uniswap pair reserves from the cache.
+func (b *MemBridge) PullUniswapReserves(pair *common.Address) []hexutil.Big {
+	// nothing to load if the pair is not given
+	if pair == nil {
+		return nil
+	}
+
+	// try to get the data from cache
+	data, err := b.cache.Get(uniswapPairReservesKey(pair))
+	if err != nil || len(data) != 72 {
+		return nil
+	}
+
+	// too old to be trusted?
+	if time.Since(time.Unix(0, int64(binary.BigEndian.Uint64(data[64:])))) > uniswapPairReservesTTL {
+		return nil
+	}
+
+	return []hexutil.Big{
+		hexutil.Big(*new(big.Int).SetBytes(data[:32])),
+		hexutil.Big(*new(big.Int).SetBytes(data[32:64])),
+	}
+}
+
+// EvictUniswapReserves removes uniswap pair reserves from the cache
+// so the current reserves are loaded on the next access.
+func (b *MemBridge) EvictUniswapReserves(pair *common.Address) {
+	if err := b.cache.Delete(uniswapPairReservesKey(pair)); err != nil && err != bigcache.ErrEntryNotFound {
+		b.log.Errorf("can not evict uniswap pair %s reserves; %s", pair.String(), err.Error())
+	}
+}
+
+// uniswapPairAnalyticsPrefix represents a prefix used for uniswap pair analytics caching key.
+const uniswapPairAnalyticsPrefix = "una"
+
+// uniswapPairAnalyticsKey generates cache key for uniswap pair analytics entry.
+func uniswapPairAnalyticsKey(pair *common.Address) string {
+	var sb strings.Builder
+	sb.WriteString(uniswapPairAnalyticsPrefix)
+	sb.WriteString(pair.String())
+	return sb.String()
+}
+
+// PushUniswapPairAnalytics stores uniswap pair analytics in the in-memory cache.
+func (b *MemBridge) PushUniswapPairAnalytics(upa *types.UniswapPairAnalytics) error {
+	// we need valid analytics
+	if nil == upa {
+		return fmt.Errorf("undefined uniswap pair analytics can not be pushed to the in-memory cache")
+	}
+
+	// encode the analytics
+	data, err := upa.Marshal()
+	if err != nil {
+		b.log.Criticalf("can not marshal uniswap pair analytics to JSON; %s", err.Error())
+		return err
+	}
+
+	// set the data to cache
+	return b.cache.Set(uniswapPairAnalyticsKey(&upa.Pair), data)
+}
+
+// PullUniswapPairAnalytics tries to load uniswap pair analytics from the cache.
+func (b *MemBridge) PullUniswapPairAnalytics(pair *common.Address) *types.UniswapPairAnalytics {
+	// try to get the data from cache
+	data, err := b.cache.Get(uniswapPairAnalyticsKey(pair))
+	if err != nil {
+		// cache returns ErrEntryNotFound if the key does not exist
+		return nil
+	}
+
+	// do we have the data?
+	upa, err := types.UnmarshalUniswapPairAnalytics(data)
+	if err != nil {
+		b.log.Criticalf("can not decode uniswap pair analytics from in-memory cache; %s", err.Error())
+		return nil
+	}
+	return upa
+}
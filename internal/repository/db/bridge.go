@@ -22,18 +22,26 @@ type MongoDbBridge struct {
 	dbName string
 
 	// init state marks
-	initAccounts     *sync.Once
-	initTransactions *sync.Once
-	initContracts    *sync.Once
-	initSwaps        *sync.Once
-	initDelegations  *sync.Once
-	initWithdrawals  *sync.Once
-	initRewards      *sync.Once
-	initErc20Trx     *sync.Once
-	initFMintTrx     *sync.Once
-	initEpochs       *sync.Once
-	initGasPrice     *sync.Once
-	initBurns        *sync.Once
+	initAccounts          *sync.Once
+	initTransactions      *sync.Once
+	initContracts         *sync.Once
+	initSwaps             *sync.Once
+	initDelegations       *sync.Once
+	initWithdrawals       *sync.Once
+	initRewards           *sync.Once
+	initErc20Trx          *sync.Once
+	initFMintTrx          *sync.Once
+	initEpochs            *sync.Once
+	initGasPrice          *sync.Once
+	initBurns             *sync.Once
+	initUniswapPairs      *sync.Once
+	initUniswapCandles    *sync.Once
+	initUniswapPositions  *sync.Once
+	initPrices            *sync.Once
+	initWebhookDeliveries *sync.Once
+
+	// candle rollups build state
+	candles uniswapCandlesState
 }
 
 // docListCountAggregationTimeout represents a max duration of DB query executed to calculate
@@ -178,6 +186,11 @@ func (db *MongoDbBridge) CheckDatabaseInitState() {
 	db.collectionNeedInit("epochs", db.EpochsCount, &db.initEpochs)
 	db.collectionNeedInit("gas price periods", db.GasPricePeriodCount, &db.initGasPrice)
 	db.collectionNeedInit("burned fees", db.BurnCount, &db.initBurns)
+	db.collectionNeedInit("uniswap pairs", db.UniswapPairsCount, &db.initUniswapPairs)
+	db.collectionNeedInit("uniswap candles", db.UniswapCandlesCount, &db.initUniswapCandles)
+	db.collectionNeedInit("uniswap positions", db.UniswapPositionsCount, &db.initUniswapPositions)
+	db.collectionNeedInit("price samples", db.PriceSamplesCount, &db.initPrices)
+	db.collectionNeedInit("webhook deliveries", db.WebhookDeliveriesCount, &db.initWebhookDeliveries)
 }
 
 // checkAccountCollectionState checks the Accounts' collection state.
@@ -0,0 +1,159 @@
+// Package db implements bridge to persistent storage represented by Mongo database.
+package db
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// colPriceSamples represents the name of the price samples collection in database.
+	colPriceSamples = "price_samples"
+
+	// colPriceDaily represents the name of the daily prices collection in database.
+	colPriceDaily = "price_daily"
+
+	// priceSampleMaxDistance represents the max distance of a price sample
+	// from the requested time to be used as the price at the time.
+	priceSampleMaxDistance = 24 * time.Hour
+)
+
+// initPriceHistoryCollections initializes the price history collections with
+// indexes and additional parameters needed by the app.
+func (db *MongoDbBridge) initPriceHistoryCollections() {
+	for _, name := range []string{colPriceSamples, colPriceDaily} {
+		ix := []mongo.IndexModel{{
+			Keys:    bson.D{{Key: types.FiPriceTickSymbol, Value: 1}, {Key: types.FiPriceTickTime, Value: -1}},
+			Options: options.Index().SetUnique(name == colPriceDaily),
+		}}
+
+		if _, err := db.client.Database(db.dbName).Collection(name).Indexes().CreateMany(context.Background(), ix); err != nil {
+			db.log.Panicf("can not create indexes for %s collection; %s", name, err.Error())
+		}
+	}
+
+	// log we are done that
+	db.log.Debugf("price history collections initialized")
+}
+
+// PriceSamplesCount calculates total number of price samples in the database.
+func (db *MongoDbBridge) PriceSamplesCount() (uint64, error) {
+	return db.EstimateCount(db.client.Database(db.dbName).Collection(colPriceSamples))
+}
+
+// AddPriceSample stores a new sample of the native token price in the given symbol
+// and updates the daily price of the sample day.
+func (db *MongoDbBridge) AddPriceSample(sym string, ts time.Time, price float64) error {
+	ts = ts.UTC()
+	if _, err := db.client.Database(db.dbName).Collection(colPriceSamples).InsertOne(context.Background(), types.NewPriceSample(sym, ts, price)); err != nil {
+		db.log.Errorf("can not store price sample [%s]; %s", sym, err.Error())
+		return err
+	}
+
+	// the samples come in order, so the last one closes the day
+	day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := db.client.Database(db.dbName).Collection(colPriceDaily).UpdateOne(context.Background(),
+		bson.D{
+			{Key: types.FiPriceTickSymbol, Value: sym},
+			{Key: types.FiPriceTickTime, Value: day},
+		},
+		bson.D{
+			{Key: "$setOnInsert", Value: bson.D{{Key: "open", Value: price}}},
+			{Key: "$max", Value: bson.D{{Key: "high", Value: price}}},
+			{Key: "$min", Value: bson.D{{Key: "low", Value: price}}},
+			{Key: "$set", Value: bson.D{{Key: "close", Value: price}}},
+		},
+		options.Update().SetUpsert(true),
+	); err != nil {
+		db.log.Errorf("can not update daily price [%s]; %s", sym, err.Error())
+		return err
+	}
+
+	// make sure price history collections are initialized
+	if db.initPrices != nil {
+		db.initPrices.Do(func() { db.initPriceHistoryCollections(); db.initPrices = nil })
+	}
+	return nil
+}
+
+// PriceTicks provides a list of price samples, or daily prices, of the native token
+// in the given symbol for the given time period.
+func (db *MongoDbBridge) PriceTicks(sym string, from *time.Time, to *time.Time, daily bool) ([]types.PriceTick, error) {
+	col := db.client.Database(db.dbName).Collection(colPriceSamples)
+	if daily {
+		col = db.client.Database(db.dbName).Collection(colPriceDaily)
+	}
+
+	// find ticks inside the date/time range
+	cursor, err := col.Find(context.Background(), bson.D{
+		{Key: types.FiPriceTickSymbol, Value: sym},
+		{Key: types.FiPriceTickTime, Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lte", Value: to}}},
+	}, options.Find().SetSort(bson.D{{Key: types.FiPriceTickTime, Value: 1}}))
+	if err != nil {
+		db.log.Errorf("can not pull price ticks [%s]; %s", sym, err.Error())
+		return nil, err
+	}
+
+	// make sure to close the cursor
+	defer db.closeCursor(cursor)
+
+	// load all the data from the database
+	list := make([]types.PriceTick, 0)
+	for cursor.Next(context.Background()) {
+		var row types.PriceTick
+		if err := cursor.Decode(&row); err != nil {
+			db.log.Errorf("could not decode price tick; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, row)
+	}
+	return list, nil
+}
+
+// PriceAt provides the price of the native token in the given symbol at the given time.
+// The last sample before the time is used if available, the daily price of the day otherwise.
+// Nil is returned if the price is not known.
+func (db *MongoDbBridge) PriceAt(sym string, at time.Time) (*types.PriceTick, error) {
+	pt, err := db.priceTickBefore(colPriceSamples, sym, at)
+	if err != nil {
+		return nil, err
+	}
+	if pt != nil && at.Sub(pt.Time) <= priceSampleMaxDistance {
+		return pt, nil
+	}
+
+	// use the daily price of the day
+	dp, err := db.priceTickBefore(colPriceDaily, sym, at)
+	if err != nil || dp == nil {
+		return pt, err
+	}
+	return dp, nil
+}
+
+// priceTickBefore loads the last price tick of the given collection before the given time.
+func (db *MongoDbBridge) priceTickBefore(colName string, sym string, at time.Time) (*types.PriceTick, error) {
+	sr := db.client.Database(db.dbName).Collection(colName).FindOne(context.Background(), bson.D{
+		{Key: types.FiPriceTickSymbol, Value: sym},
+		{Key: types.FiPriceTickTime, Value: bson.D{{Key: "$lte", Value: at}}},
+	}, options.FindOne().SetSort(bson.D{{Key: types.FiPriceTickTime, Value: -1}}))
+
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		db.log.Errorf("can not load price tick [%s]; %s", sym, sr.Err().Error())
+		return nil, sr.Err()
+	}
+
+	var row types.PriceTick
+	if err := sr.Decode(&row); err != nil {
+		db.log.Errorf("could not decode price tick; %s", err.Error())
+		return nil, err
+	}
+	return &row, nil
+}
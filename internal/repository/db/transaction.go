@@ -385,6 +385,37 @@ func (db *MongoDbBridge) txListLoad(col *mongo.Collection, cursor *string, count
 	return nil
 }
 
+// TransactionsFrom loads up to the given number of transactions with the ordinal index
+// at or above the given one sorted from older to newer.
+func (db *MongoDbBridge) TransactionsFrom(orx uint64, count int64) ([]*types.Transaction, error) {
+	// get the collection and context
+	col := db.client.Database(db.dbName).Collection(coTransactions)
+	ctx := context.Background()
+
+	// load the data
+	ld, err := col.Find(ctx,
+		bson.D{{Key: fiTransactionOrdinalIndex, Value: bson.D{{Key: "$gte", Value: orx}}}},
+		options.Find().SetSort(bson.D{{Key: fiTransactionOrdinalIndex, Value: 1}}).SetLimit(count))
+	if err != nil {
+		db.log.Errorf("can not load transactions from #%d; %s", orx, err.Error())
+		return nil, err
+	}
+
+	defer db.closeCursor(ld)
+
+	// loop and load
+	list := make([]*types.Transaction, 0, count)
+	for ld.Next(ctx) {
+		var row types.Transaction
+		if err := ld.Decode(&row); err != nil {
+			db.log.Errorf("can not decode the transaction row; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
+
 // TransactionsCount returns the number of transactions stored in the database.
 func (db *MongoDbBridge) TransactionsCount() (uint64, error) {
 	return db.EstimateCount(db.client.Database(db.dbName).Collection(coTransactions))
@@ -3,7 +3,6 @@ package db
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
 	"math/big"
 	"ncogearthchain-api-graphql/internal/types"
@@ -48,10 +47,7 @@ var swapReserveDecimalsCorrection = new(big.Int).SetUint64(1000000000000)
 
 // getHash generates hash for swap from transaction hash and pair address
 func getHash(swap *types.Swap) *common.Hash {
-	hashBytes := swap.Hash.Big().Bytes()
-	pairBytes := swap.Pair.Bytes()
-	sum := sha256.Sum256(append(hashBytes, pairBytes...))
-	swapHash := common.BytesToHash(sum[:])
+	swapHash := swap.Id()
 	return &swapHash
 }
 
@@ -80,6 +76,7 @@ func (db *MongoDbBridge) initUniswapCollection(col *mongo.Collection) {
 	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: fiSwapDate, Value: 1}}})
 	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: fiSwapSender, Value: 1}}})
 	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: fiSwapOrdIndex, Value: -1}}})
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: fiSwapPair, Value: 1}, {Key: fiSwapDate, Value: -1}}})
 
 	// create indexes
 	if _, err := col.Indexes().CreateMany(context.Background(), ix); err != nil {
@@ -139,6 +136,11 @@ func (db *MongoDbBridge) UniswapAdd(swap *types.Swap) error {
 	// calculate swap hash to use it as a pk
 	swapHash := getHash(swap)
 
+	// swaps stored during the candles rebuild are applied by the rebuild
+	db.candles.RLock()
+	defer db.candles.RUnlock()
+	deferred := db.candles.deferSwap(swapHash.String(), swap)
+
 	// try to do the insert
 	if _, err := col.InsertOne(context.Background(),
 		swapData(&bson.D{
@@ -148,6 +150,7 @@ func (db *MongoDbBridge) UniswapAdd(swap *types.Swap) error {
 			{Key: fiSwapDate, Value: primitive.NewDateTimeFromTime(time.Unix((int64)(*swap.TimeStamp), 0).UTC())},
 		}, swap)); err != nil {
 
+		db.candles.forget(swapHash.String())
 		db.log.Critical(err)
 		return err
 	}
@@ -159,6 +162,11 @@ func (db *MongoDbBridge) UniswapAdd(swap *types.Swap) error {
 	if db.initSwaps != nil {
 		db.initSwaps.Do(func() { db.initUniswapCollection(col); db.initSwaps = nil })
 	}
+
+	// update the candle rollups
+	if !deferred {
+		db.uniswapCandlesApply(swap)
+	}
 	return nil
 }
 
@@ -218,6 +226,8 @@ func (db *MongoDbBridge) IsSwapKnown(col *mongo.Collection, hash *common.Hash, s
 				{Key: "$set", Value: bson.M{fiSwapReserve1: removeDecimals(swap.Reserve1, swapReserveDecimalsCorrection)}}})
 		if err != nil {
 			db.log.Errorf("unable to update reserves for swap %s", hash.String())
+		} else {
+			db.uniswapCandlesApplyReserves(hash, swap)
 		}
 	} else {
 		// in case the sync event was recorded first, update reserves into actual swap
@@ -395,6 +405,10 @@ func (db *MongoDbBridge) UniswapVolume(pairAddress *common.Address, fromTime int
 // UniswapTimeVolumes resolves volumes of swap trades for specified pair grouped by date interval.
 // If toTime is 0, then it calculates volumes till now
 func (db *MongoDbBridge) UniswapTimeVolumes(pairAddress *common.Address, resolution string, fromTime int64, toTime int64) ([]types.DefiSwapVolume, error) {
+	// use the candle rollups if built and available for the resolution
+	if colName, length, ok := uniswapCandleCollection(resolution); ok && db.UniswapCandlesReady() {
+		return db.uniswapCandleTimeVolumes(colName, length, pairAddress, fromTime, toTime)
+	}
 
 	fTime := primitive.NewDateTimeFromTime(time.Unix(fromTime, 0))
 
@@ -524,6 +538,11 @@ func getDateBsonD(fromTime int64, toTime int64) bson.D {
 // UniswapTimePrices resolves price of swap trades for specified pair grouped by date interval.
 // If toTime is 0, then it calculates prices till now
 func (db *MongoDbBridge) UniswapTimePrices(pairAddress *common.Address, resolution string, fromTime int64, toTime int64, direction int32) ([]types.DefiTimePrice, error) {
+	// use the candle rollups if built and available for the resolution
+	if colName, length, ok := uniswapCandleCollection(resolution); ok && db.UniswapCandlesReady() {
+		return db.uniswapCandleTimePrices(colName, length, pairAddress, fromTime, toTime, direction)
+	}
+
 	tokenASum := bson.D{{Key: "$add", Value: bson.A{"$am0in", "$am0out"}}}
 	tokenBSum := bson.D{{Key: "$add", Value: bson.A{"$am1in", "$am1out"}}}
 
@@ -597,6 +616,10 @@ func (db *MongoDbBridge) UniswapTimePrices(pairAddress *common.Address, resoluti
 // UniswapTimeReserves resolves reserves of uniswap trades for specified pair grouped by date interval.
 // If toTime is 0, then it calculates prices till now
 func (db *MongoDbBridge) UniswapTimeReserves(pairAddress *common.Address, resolution string, fromTime int64, toTime int64) ([]types.DefiTimeReserve, error) {
+	// use the candle rollups if built and available for the resolution
+	if colName, length, ok := uniswapCandleCollection(resolution); ok && db.UniswapCandlesReady() {
+		return db.uniswapCandleTimeReserves(colName, length, pairAddress, fromTime, toTime)
+	}
 
 	// create query pipeline
 	pipe := mongo.Pipeline{
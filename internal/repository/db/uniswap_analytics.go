@@ -0,0 +1,76 @@
+// Package db implements bridge to persistent storage represented by Mongo database.
+package db
+
+import (
+	"context"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UniswapTransactionsCount resolves the number of swap, mint and burn transactions
+// of the given pair in the given date interval. If toTime is 0, then it counts transactions till now.
+func (db *MongoDbBridge) UniswapTransactionsCount(pairAddress *common.Address, fromTime int64, toTime int64) (uint64, error) {
+	filter := bson.D{
+		{Key: fiSwapPair, Value: pairAddress.String()},
+		{Key: fiSwapType, Value: bson.D{{Key: "$ne", Value: types.SwapSync}}},
+	}
+
+	// construct date condition
+	if fromTime != 0 || toTime != 0 {
+		dt := bson.D{{Key: "$gte", Value: primitive.NewDateTimeFromTime(time.Unix(fromTime, 0))}}
+		if toTime != 0 {
+			dt = append(dt, bson.E{Key: "$lte", Value: primitive.NewDateTimeFromTime(time.Unix(toTime, 0))})
+		}
+		filter = append(filter, bson.E{Key: fiSwapDate, Value: dt})
+	}
+
+	col := db.client.Database(db.dbName).Collection(coUniswap)
+	val, err := col.CountDocuments(context.Background(), filter)
+	if err != nil {
+		db.log.Errorf("can not count uniswap transactions of %s; %s", pairAddress.String(), err.Error())
+		return 0, err
+	}
+	return uint64(val), nil
+}
+
+// UniswapReservesAt resolves the reserves of the given pair recorded by the last
+// swap before the given time. Nil is returned if no such swap is known.
+func (db *MongoDbBridge) UniswapReservesAt(pairAddress *common.Address, at int64) ([]hexutil.Big, error) {
+	col := db.client.Database(db.dbName).Collection(coUniswap)
+	sr := col.FindOne(context.Background(), bson.D{
+		{Key: fiSwapPair, Value: pairAddress.String()},
+		{Key: fiSwapDate, Value: bson.D{{Key: "$lte", Value: primitive.NewDateTimeFromTime(time.Unix(at, 0))}}},
+		{Key: fiSwapReserve0, Value: bson.D{{Key: "$gt", Value: 0}}},
+		{Key: fiSwapReserve1, Value: bson.D{{Key: "$gt", Value: 0}}},
+	}, options.FindOne().SetSort(bson.D{{Key: fiSwapDate, Value: -1}}))
+
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		db.log.Errorf("can not load uniswap reserves of %s; %s", pairAddress.String(), sr.Err().Error())
+		return nil, sr.Err()
+	}
+
+	var row struct {
+		Reserve0 int64 `bson:"reserve0"`
+		Reserve1 int64 `bson:"reserve1"`
+	}
+	if err := sr.Decode(&row); err != nil {
+		db.log.Errorf("can not decode uniswap reserves of %s; %s", pairAddress.String(), err.Error())
+		return nil, err
+	}
+
+	return []hexutil.Big{
+		hexutil.Big(*returnDecimals(big.NewInt(row.Reserve0), swapReserveDecimalsCorrection)),
+		hexutil.Big(*returnDecimals(big.NewInt(row.Reserve1), swapReserveDecimalsCorrection)),
+	}, nil
+}
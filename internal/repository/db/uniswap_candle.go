@@ -0,0 +1,560 @@
+// Package db implements bridge to persistent storage represented by Mongo database.
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// colUniswapCandlesPrefix is the prefix of the Uniswap candle rollup collections;
+	// the resolution of the candles is added to the prefix.
+	colUniswapCandlesPrefix = "uniswap_candles_"
+
+	// colUniswapCandlesBuildPrefix is the prefix of the candle collections being rebuilt;
+	// the rebuilt collections replace the live ones when the rebuild is done.
+	colUniswapCandlesBuildPrefix = "uniswap_candles_build_"
+
+	// keyConfigCandlesBuilt is the config collection key marking the candle rollups fully built.
+	keyConfigCandlesBuilt = "cdl"
+
+	// fiCandlePair is the name of the pair address column of the candle collections.
+	fiCandlePair = "pair"
+
+	// fiCandleTime is the name of the candle starting time column of the candle collections.
+	fiCandleTime = "ts"
+
+	// uniswapCandlesRebuildBatch is the number of candle updates sent to the database in one batch on rebuild.
+	uniswapCandlesRebuildBatch = 1000
+
+	// candleTimeFormat is the format of the time tag of the candles in API responses.
+	candleTimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// uniswapCandleResolutions represents the list of candle rollup resolutions and their length in seconds.
+var uniswapCandleResolutions = []struct {
+	name   string
+	length int64
+}{
+	{name: "1m", length: 60},
+	{name: "5m", length: 5 * 60},
+	{name: "15m", length: 15 * 60},
+	{name: "1h", length: 60 * 60},
+	{name: "4h", length: 4 * 60 * 60},
+	{name: "1d", length: 24 * 60 * 60},
+}
+
+// uniswapCandlesState represents the state of the candle rollups build. Swaps stored
+// while the rollups are being rebuilt are collected and applied by the rebuild itself,
+// so they are neither lost with the replaced collections, nor counted twice.
+type uniswapCandlesState struct {
+	// the read lock is held by swap inserts, the rebuild locks to switch its phases
+	sync.RWMutex
+
+	// mu guards the state values below
+	mu      sync.Mutex
+	checked bool
+	ready   bool
+	late    map[string]*uniswapCandleInput
+}
+
+// deferSwap registers the given swap to be applied by a running rebuild.
+// It returns false if no rebuild is running and the swap should be applied directly.
+func (st *uniswapCandlesState) deferSwap(pk string, swap *types.Swap) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.late == nil {
+		return false
+	}
+	st.late[pk] = newUniswapCandleInput(swap)
+	return true
+}
+
+// forget removes the swap of the given pk from the swaps deferred to a running rebuild.
+func (st *uniswapCandlesState) forget(pk string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.late != nil {
+		delete(st.late, pk)
+	}
+}
+
+// stop ends collecting the swaps stored during a rebuild.
+func (st *uniswapCandlesState) stop() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.late = nil
+}
+
+// isLate checks if the swap of the given pk has been deferred to the running rebuild.
+func (st *uniswapCandlesState) isLate(pk string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	_, ok := st.late[pk]
+	return ok
+}
+
+// uniswapCandleInput represents a single swap collection record applied to candles.
+// Amounts and reserves use the decimals correction of the swap collection.
+type uniswapCandleInput struct {
+	Pk       string    `bson:"_id"`
+	Orx      int64     `bson:"orx"`
+	Type     int       `bson:"type"`
+	Pair     string    `bson:"pair"`
+	Date     time.Time `bson:"date"`
+	Am0in    int64     `bson:"am0in"`
+	Am0out   int64     `bson:"am0out"`
+	Am1in    int64     `bson:"am1in"`
+	Am1out   int64     `bson:"am1out"`
+	Reserve0 int64     `bson:"reserve0"`
+	Reserve1 int64     `bson:"reserve1"`
+}
+
+// uniswapCandle represents a single candle rollup record.
+type uniswapCandle struct {
+	Pair     string    `bson:"pair"`
+	Time     time.Time `bson:"ts"`
+	Open     float64   `bson:"open"`
+	Close    float64   `bson:"close"`
+	Low      float64   `bson:"low"`
+	High     float64   `bson:"high"`
+	Sum      float64   `bson:"sum"`
+	Open1    float64   `bson:"open1"`
+	Close1   float64   `bson:"close1"`
+	Low1     float64   `bson:"low1"`
+	High1    float64   `bson:"high1"`
+	Sum1     float64   `bson:"sum1"`
+	Count    int64     `bson:"cnt"`
+	Volume   int64     `bson:"vol"`
+	Reserve0 *int64    `bson:"res0"`
+	Reserve1 *int64    `bson:"res1"`
+}
+
+// uniswapCandleCollection provides the name of the candle rollup collection for the given resolution.
+// The resolution names follow the API, e.g. "day" is the same as "1d". Resolutions without
+// a rollup collection are rejected.
+func uniswapCandleCollection(resolution string) (string, int64, bool) {
+	switch resolution {
+	case "30m", "month":
+		return "", 0, false
+	case "day", "":
+		resolution = "1d"
+	}
+
+	for _, r := range uniswapCandleResolutions {
+		if r.name == resolution {
+			return colUniswapCandlesPrefix + r.name, r.length, true
+		}
+	}
+
+	// unknown resolutions default to a day, same as the raw swap aggregations
+	return colUniswapCandlesPrefix + "1d", 24 * 60 * 60, true
+}
+
+// initUniswapCandlesCollection initializes the candle rollup collection indexes.
+func (db *MongoDbBridge) initUniswapCandlesCollection(col *mongo.Collection) {
+	// prepare index models
+	ix := make([]mongo.IndexModel, 0)
+
+	// index pair + time
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: fiCandlePair, Value: 1}, {Key: fiCandleTime, Value: 1}}})
+
+	// create indexes
+	if _, err := col.Indexes().CreateMany(context.Background(), ix); err != nil {
+		db.log.Panicf("can not create indexes for uniswap candles collection; %s", err.Error())
+	}
+
+	// log we are done that
+	db.log.Debugf("uniswap candles collection %s initialized", col.Name())
+}
+
+// newUniswapCandleInput creates the candle input record for the given swap.
+func newUniswapCandleInput(swap *types.Swap) *uniswapCandleInput {
+	return &uniswapCandleInput{
+		Orx:      int64(swap.OrdIndex),
+		Type:     swap.Type,
+		Pair:     swap.Pair.String(),
+		Date:     time.Unix(int64(*swap.TimeStamp), 0).UTC(),
+		Am0in:    int64(removeDecimals(swap.Amount0In, swapAmountDecimalsCorrection)),
+		Am0out:   int64(removeDecimals(swap.Amount0Out, swapAmountDecimalsCorrection)),
+		Am1in:    int64(removeDecimals(swap.Amount1In, swapAmountDecimalsCorrection)),
+		Am1out:   int64(removeDecimals(swap.Amount1Out, swapAmountDecimalsCorrection)),
+		Reserve0: int64(removeDecimals(swap.Reserve0, swapReserveDecimalsCorrection)),
+		Reserve1: int64(removeDecimals(swap.Reserve1, swapReserveDecimalsCorrection)),
+	}
+}
+
+// uniswapCandleUpdate builds the candle update model for the given input and candle length.
+// The update does not depend on the order the inputs are applied in; the opening and closing
+// values are decided by the ordinal index of the input, so late swaps land in the right place.
+func uniswapCandleUpdate(in *uniswapCandleInput, length int64) *mongo.UpdateOneModel {
+	ts := time.Unix(in.Date.Unix()-in.Date.Unix()%length, 0).UTC()
+	set := bson.D{
+		{Key: fiCandlePair, Value: in.Pair},
+		{Key: fiCandleTime, Value: ts},
+		{Key: "vol", Value: bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$vol", 0}}}, in.Am0in + in.Am0out}}}},
+	}
+
+	// is this the first or the last known input of the candle?
+	isOpen := bson.D{{Key: "$lte", Value: bson.A{in.Orx, bson.D{{Key: "$ifNull", Value: bson.A{"$orxo", int64(math.MaxInt64)}}}}}}
+	isClose := bson.D{{Key: "$gte", Value: bson.A{in.Orx, "$orxc"}}}
+
+	// the price is available on trades only; sync events carry only the reserves
+	a0, a1 := in.Am0in+in.Am0out, in.Am1in+in.Am1out
+	if in.Type != types.SwapSync && a0 > 0 && a1 > 0 {
+		p := float64(a0) / float64(a1)
+		p1 := float64(a1) / float64(a0)
+
+		set = append(set,
+			bson.E{Key: "open", Value: bson.D{{Key: "$cond", Value: bson.A{isOpen, p, "$open"}}}},
+			bson.E{Key: "open1", Value: bson.D{{Key: "$cond", Value: bson.A{isOpen, p1, "$open1"}}}},
+			bson.E{Key: "close", Value: bson.D{{Key: "$cond", Value: bson.A{isClose, p, "$close"}}}},
+			bson.E{Key: "close1", Value: bson.D{{Key: "$cond", Value: bson.A{isClose, p1, "$close1"}}}},
+			bson.E{Key: "low", Value: bson.D{{Key: "$min", Value: bson.A{"$low", p}}}},
+			bson.E{Key: "low1", Value: bson.D{{Key: "$min", Value: bson.A{"$low1", p1}}}},
+			bson.E{Key: "high", Value: bson.D{{Key: "$max", Value: bson.A{"$high", p}}}},
+			bson.E{Key: "high1", Value: bson.D{{Key: "$max", Value: bson.A{"$high1", p1}}}},
+			bson.E{Key: "sum", Value: bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$sum", 0}}}, p}}}},
+			bson.E{Key: "sum1", Value: bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$sum1", 0}}}, p1}}}},
+			bson.E{Key: "cnt", Value: bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$cnt", 0}}}, 1}}}},
+			bson.E{Key: "orxo", Value: bson.D{{Key: "$min", Value: bson.A{"$orxo", in.Orx}}}},
+			bson.E{Key: "orxc", Value: bson.D{{Key: "$max", Value: bson.A{"$orxc", in.Orx}}}},
+		)
+	}
+
+	// reserves are closed by the latest input carrying them
+	if in.Reserve0 > 0 || in.Reserve1 > 0 {
+		isLast := bson.D{{Key: "$gte", Value: bson.A{in.Orx, "$orxr"}}}
+		set = append(set,
+			bson.E{Key: "res0", Value: bson.D{{Key: "$cond", Value: bson.A{isLast, in.Reserve0, "$res0"}}}},
+			bson.E{Key: "res1", Value: bson.D{{Key: "$cond", Value: bson.A{isLast, in.Reserve1, "$res1"}}}},
+			bson.E{Key: "orxr", Value: bson.D{{Key: "$max", Value: bson.A{"$orxr", in.Orx}}}},
+		)
+	}
+
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.D{{Key: "_id", Value: strings.Join([]string{in.Pair, ts.Format(time.RFC3339)}, "_")}}).
+		SetUpdate(mongo.Pipeline{{{Key: "$set", Value: set}}}).
+		SetUpsert(true)
+}
+
+// UniswapCandlesCount estimates the number of the finest candle rollups in the database.
+func (db *MongoDbBridge) UniswapCandlesCount() (uint64, error) {
+	return db.EstimateCount(db.client.Database(db.dbName).Collection(colUniswapCandlesPrefix + uniswapCandleResolutions[0].name))
+}
+
+// UniswapCandlesReady checks if the candle rollups have been fully built from the stored swaps.
+// Until then, the rollups miss the history and the raw swaps aggregation must be used instead.
+func (db *MongoDbBridge) UniswapCandlesReady() bool {
+	db.candles.mu.Lock()
+	defer db.candles.mu.Unlock()
+
+	if db.candles.checked {
+		return db.candles.ready
+	}
+
+	res := db.client.Database(db.dbName).Collection(coConfiguration).FindOne(context.Background(), bson.D{{Key: fiConfigPk, Value: keyConfigCandlesBuilt}})
+	if res.Err() != nil {
+		if res.Err() != mongo.ErrNoDocuments {
+			db.log.Errorf("can not check uniswap candles state; %s", res.Err().Error())
+			return false
+		}
+	} else {
+		db.candles.ready = true
+	}
+
+	db.candles.checked = true
+	return db.candles.ready
+}
+
+// uniswapCandlesBuilt marks the candle rollups as fully built.
+func (db *MongoDbBridge) uniswapCandlesBuilt() error {
+	col := db.client.Database(db.dbName).Collection(coConfiguration)
+	_, err := col.UpdateByID(context.Background(), keyConfigCandlesBuilt, bson.D{{Key: "$set", Value: bson.D{
+		{Key: fiConfigPk, Value: keyConfigCandlesBuilt},
+		{Key: fiConfigValue, Value: time.Now().UTC().Format(time.RFC3339)},
+	}}}, options.Update().SetUpsert(true))
+	if err != nil {
+		db.log.Errorf("can not mark uniswap candles built; %s", err.Error())
+		return err
+	}
+
+	db.candles.mu.Lock()
+	db.candles.checked, db.candles.ready = true, true
+	db.candles.mu.Unlock()
+	return nil
+}
+
+// uniswapCandlesApply applies the given swap to all the candle rollups.
+func (db *MongoDbBridge) uniswapCandlesApply(swap *types.Swap) {
+	// make sure the rollup collections are initialized
+	if db.initUniswapCandles != nil {
+		db.initUniswapCandles.Do(func() {
+			for _, r := range uniswapCandleResolutions {
+				db.initUniswapCandlesCollection(db.client.Database(db.dbName).Collection(colUniswapCandlesPrefix + r.name))
+			}
+			db.initUniswapCandles = nil
+		})
+	}
+
+	in := newUniswapCandleInput(swap)
+	for _, r := range uniswapCandleResolutions {
+		col := db.client.Database(db.dbName).Collection(colUniswapCandlesPrefix + r.name)
+		if _, err := col.BulkWrite(context.Background(), []mongo.WriteModel{uniswapCandleUpdate(in, r.length)}); err != nil {
+			db.log.Errorf("can not update uniswap %s candle of %s; %s", r.name, in.Pair, err.Error())
+		}
+	}
+}
+
+// uniswapCandlesApplyReserves applies the reserves of the given sync event of a known swap
+// to all the candle rollups. The reserves update does not replace the swap itself,
+// so it's deferred to a running rebuild under its own key.
+func (db *MongoDbBridge) uniswapCandlesApplyReserves(hash *common.Hash, swap *types.Swap) {
+	db.candles.RLock()
+	defer db.candles.RUnlock()
+
+	if !db.candles.deferSwap(hash.String()+"/res", swap) {
+		db.uniswapCandlesApply(swap)
+	}
+}
+
+// UniswapCandlesRebuild builds all the candle rollups from the swap collection.
+// The rollups are built into separate collections, which replace the live rollups
+// when the build is done; swaps stored in the meantime are applied before the switch.
+func (db *MongoDbBridge) UniswapCandlesRebuild() error {
+	// start collecting swaps stored while we rebuild; wait for the inserts in progress
+	db.candles.Lock()
+	db.candles.mu.Lock()
+	if db.candles.late != nil {
+		db.candles.mu.Unlock()
+		db.candles.Unlock()
+		return fmt.Errorf("uniswap candles rebuild already running")
+	}
+	db.candles.late = make(map[string]*uniswapCandleInput)
+	db.candles.mu.Unlock()
+	db.candles.Unlock()
+
+	count, err := db.uniswapCandlesBuild()
+	if err != nil {
+		db.candles.stop()
+		return err
+	}
+
+	// no swap can be stored while we apply the late swaps and switch the collections
+	db.candles.Lock()
+	defer db.candles.Unlock()
+	defer db.candles.stop()
+
+	batch := make([][]mongo.WriteModel, len(uniswapCandleResolutions))
+	for _, in := range db.candles.late {
+		for i, r := range uniswapCandleResolutions {
+			batch[i] = append(batch[i], uniswapCandleUpdate(in, r.length))
+		}
+	}
+	if err := db.uniswapCandlesFlush(colUniswapCandlesBuildPrefix, batch); err != nil {
+		return err
+	}
+
+	for _, r := range uniswapCandleResolutions {
+		if err := db.client.Database("admin").RunCommand(context.Background(), bson.D{
+			{Key: "renameCollection", Value: db.dbName + "." + colUniswapCandlesBuildPrefix + r.name},
+			{Key: "to", Value: db.dbName + "." + colUniswapCandlesPrefix + r.name},
+			{Key: "dropTarget", Value: true},
+		}).Err(); err != nil {
+			db.log.Errorf("can not replace uniswap %s candles; %s", r.name, err.Error())
+			return err
+		}
+	}
+
+	db.log.Noticef("uniswap candles rebuilt from %d swaps", count+len(db.candles.late))
+	return db.uniswapCandlesBuilt()
+}
+
+// uniswapCandlesBuild rolls up all the stored swaps into empty build collections, except
+// the swaps stored after the rebuild started. It returns the number of swaps rolled up.
+func (db *MongoDbBridge) uniswapCandlesBuild() (int, error) {
+	for _, r := range uniswapCandleResolutions {
+		col := db.client.Database(db.dbName).Collection(colUniswapCandlesBuildPrefix + r.name)
+		if err := col.Drop(context.Background()); err != nil {
+			db.log.Errorf("can not drop uniswap %s candles build; %s", r.name, err.Error())
+			return 0, err
+		}
+		db.initUniswapCandlesCollection(col)
+	}
+
+	// walk all the swaps in their natural order
+	col := db.client.Database(db.dbName).Collection(coUniswap)
+	cr, err := col.Find(context.Background(),
+		bson.D{{Key: fiSwapBlock, Value: bson.D{{Key: "$exists", Value: true}}}},
+		options.Find().SetSort(bson.D{{Key: fiSwapOrdIndex, Value: 1}}))
+	if err != nil {
+		db.log.Errorf("can not load swaps; %s", err.Error())
+		return 0, err
+	}
+	defer db.closeCursor(cr)
+
+	var count int
+	batch := make([][]mongo.WriteModel, len(uniswapCandleResolutions))
+	for cr.Next(context.Background()) {
+		var in uniswapCandleInput
+		if err := cr.Decode(&in); err != nil {
+			db.log.Errorf("can not decode swap; %s", err.Error())
+			return 0, err
+		}
+
+		// the late swaps are applied at the end
+		if db.candles.isLate(in.Pk) {
+			continue
+		}
+
+		for i, r := range uniswapCandleResolutions {
+			batch[i] = append(batch[i], uniswapCandleUpdate(&in, r.length))
+		}
+
+		count++
+		if count%uniswapCandlesRebuildBatch == 0 {
+			if err := db.uniswapCandlesFlush(colUniswapCandlesBuildPrefix, batch); err != nil {
+				return 0, err
+			}
+			db.log.Infof("%d swaps rolled up into candles", count)
+		}
+	}
+
+	if err := db.uniswapCandlesFlush(colUniswapCandlesBuildPrefix, batch); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// uniswapCandlesFlush writes the batch of candle updates into the rollup collections of the given prefix.
+func (db *MongoDbBridge) uniswapCandlesFlush(prefix string, batch [][]mongo.WriteModel) error {
+	for i, r := range uniswapCandleResolutions {
+		if len(batch[i]) == 0 {
+			continue
+		}
+
+		col := db.client.Database(db.dbName).Collection(prefix + r.name)
+		if _, err := col.BulkWrite(context.Background(), batch[i], options.BulkWrite().SetOrdered(true)); err != nil {
+			db.log.Errorf("can not write uniswap %s candles; %s", r.name, err.Error())
+			return err
+		}
+		batch[i] = batch[i][:0]
+	}
+	return nil
+}
+
+// uniswapCandles loads candles of the given pair, resolution and time range.
+// If toTime is 0, then it loads candles till now.
+func (db *MongoDbBridge) uniswapCandles(colName string, length int64, pairAddress *common.Address, fromTime int64, toTime int64) ([]uniswapCandle, error) {
+	// include the candle the starting time falls into
+	dt := getDateBsonD(fromTime-fromTime%length, toTime)
+
+	col := db.client.Database(db.dbName).Collection(colName)
+	cr, err := col.Find(context.Background(),
+		bson.D{{Key: fiCandlePair, Value: pairAddress.String()}, {Key: fiCandleTime, Value: dt}},
+		options.Find().SetSort(bson.D{{Key: fiCandleTime, Value: 1}}))
+	if err != nil {
+		db.log.Errorf("can not load uniswap candles; %s", err.Error())
+		return nil, err
+	}
+	defer db.closeCursor(cr)
+
+	list := make([]uniswapCandle, 0)
+	for cr.Next(context.Background()) {
+		var row uniswapCandle
+		if err := cr.Decode(&row); err != nil {
+			db.log.Errorf("can not decode uniswap candle; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, row)
+	}
+	return list, nil
+}
+
+// uniswapCandleTimeVolumes provides swap volumes of the given pair from the candle rollups.
+func (db *MongoDbBridge) uniswapCandleTimeVolumes(colName string, length int64, pairAddress *common.Address, fromTime int64, toTime int64) ([]types.DefiSwapVolume, error) {
+	list := make([]types.DefiSwapVolume, 0)
+	cl, err := db.uniswapCandles(colName, length, pairAddress, fromTime, toTime)
+	if err != nil {
+		return list, nil
+	}
+
+	for _, c := range cl {
+		list = append(list, types.DefiSwapVolume{
+			PairAddress: pairAddress,
+			Volume:      returnDecimals(big.NewInt(c.Volume), swapAmountDecimalsCorrection),
+			DateString:  c.Time.UTC().Format(candleTimeFormat),
+		})
+	}
+	return list, nil
+}
+
+// uniswapCandleTimePrices provides swap prices of the given pair from the candle rollups.
+func (db *MongoDbBridge) uniswapCandleTimePrices(colName string, length int64, pairAddress *common.Address, fromTime int64, toTime int64, direction int32) ([]types.DefiTimePrice, error) {
+	list := make([]types.DefiTimePrice, 0)
+	cl, err := db.uniswapCandles(colName, length, pairAddress, fromTime, toTime)
+	if err != nil {
+		return list, nil
+	}
+
+	for _, c := range cl {
+		// candles with reserves update only do not have a price
+		if c.Count == 0 {
+			continue
+		}
+
+		tp := types.DefiTimePrice{
+			PairAddress: *pairAddress,
+			Time:        c.Time.UTC().Format(candleTimeFormat),
+			Open:        c.Open,
+			Close:       c.Close,
+			Low:         c.Low,
+			High:        c.High,
+			Average:     c.Sum / float64(c.Count),
+		}
+		if direction != 0 {
+			tp.Open, tp.Close, tp.Low, tp.High, tp.Average = c.Open1, c.Close1, c.Low1, c.High1, c.Sum1/float64(c.Count)
+		}
+		list = append(list, tp)
+	}
+	return list, nil
+}
+
+// uniswapCandleTimeReserves provides closing reserves of the given pair from the candle rollups.
+func (db *MongoDbBridge) uniswapCandleTimeReserves(colName string, length int64, pairAddress *common.Address, fromTime int64, toTime int64) ([]types.DefiTimeReserve, error) {
+	list := make([]types.DefiTimeReserve, 0)
+	cl, err := db.uniswapCandles(colName, length, pairAddress, fromTime, toTime)
+	if err != nil {
+		return list, nil
+	}
+
+	for _, c := range cl {
+		if c.Reserve0 == nil || c.Reserve1 == nil {
+			continue
+		}
+
+		list = append(list, types.DefiTimeReserve{
+			Time: c.Time.UTC().Format(candleTimeFormat),
+			ReserveClose: []hexutil.Big{
+				hexutil.Big(*returnDecimals(big.NewInt(*c.Reserve0), swapReserveDecimalsCorrection)),
+				hexutil.Big(*returnDecimals(big.NewInt(*c.Reserve1), swapReserveDecimalsCorrection)),
+			},
+		})
+	}
+	return list, nil
+}
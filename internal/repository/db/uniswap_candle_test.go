@@ -0,0 +1,143 @@
+package db
+
+import (
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// candleSetStage extracts the fields of the $set stage of the given candle update model.
+func candleSetStage(g *gomega.WithT, um *mongo.UpdateOneModel) map[string]interface{} {
+	pipe, ok := um.Update.(mongo.Pipeline)
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(pipe).To(gomega.HaveLen(1))
+	g.Expect(pipe[0][0].Key).To(gomega.Equal("$set"))
+
+	set := make(map[string]interface{})
+	for _, e := range pipe[0][0].Value.(bson.D) {
+		set[e.Key] = e.Value
+	}
+	return set
+}
+
+func TestUniswapCandleCollection(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	name, length, ok := uniswapCandleCollection("4h")
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(name).To(gomega.Equal("uniswap_candles_4h"))
+	g.Expect(length).To(gomega.Equal(int64(4 * 60 * 60)))
+
+	name, length, ok = uniswapCandleCollection("day")
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(name).To(gomega.Equal("uniswap_candles_1d"))
+	g.Expect(length).To(gomega.Equal(int64(24 * 60 * 60)))
+
+	_, _, ok = uniswapCandleCollection("30m")
+	g.Expect(ok).To(gomega.BeFalse())
+
+	_, _, ok = uniswapCandleCollection("month")
+	g.Expect(ok).To(gomega.BeFalse())
+}
+
+func TestUniswapCandleUpdateTrade(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	in := uniswapCandleInput{
+		Orx:   77,
+		Type:  types.SwapMint,
+		Pair:  "0x01",
+		Date:  time.Date(2021, 5, 4, 12, 34, 56, 0, time.UTC),
+		Am0in: 10,
+		Am1in: 0, Am1out: 40,
+	}
+
+	um := uniswapCandleUpdate(&in, 60*60)
+	g.Expect(*um.Upsert).To(gomega.BeTrue())
+	g.Expect(um.Filter).To(gomega.Equal(bson.D{{Key: "_id", Value: "0x01_2021-05-04T12:00:00Z"}}))
+
+	set := candleSetStage(g, um)
+	g.Expect(set[fiCandleTime]).To(gomega.Equal(time.Date(2021, 5, 4, 12, 0, 0, 0, time.UTC)))
+	g.Expect(set[fiCandlePair]).To(gomega.Equal("0x01"))
+	for _, k := range []string{"open", "close", "low", "high", "sum", "open1", "close1", "low1", "high1", "sum1", "cnt", "orxo", "orxc", "vol"} {
+		g.Expect(set).To(gomega.HaveKey(k))
+	}
+
+	// trades without reserves do not touch the closing reserves
+	g.Expect(set).NotTo(gomega.HaveKey("res0"))
+	g.Expect(set).NotTo(gomega.HaveKey("orxr"))
+
+	// the price is the amount of token0 per token1 and the other way around
+	g.Expect(set["low"]).To(gomega.Equal(bson.D{{Key: "$min", Value: bson.A{"$low", 0.25}}}))
+	g.Expect(set["high1"]).To(gomega.Equal(bson.D{{Key: "$max", Value: bson.A{"$high1", 4.0}}}))
+	g.Expect(set["vol"]).To(gomega.Equal(bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$vol", 0}}}, int64(10)}}}))
+}
+
+func TestUniswapCandleUpdateSync(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	in := uniswapCandleInput{
+		Orx:      78,
+		Type:     types.SwapSync,
+		Pair:     "0x01",
+		Date:     time.Date(2021, 5, 4, 12, 34, 56, 0, time.UTC),
+		Reserve0: 1000,
+		Reserve1: 2000,
+	}
+
+	um := uniswapCandleUpdate(&in, 24*60*60)
+	g.Expect(um.Filter).To(gomega.Equal(bson.D{{Key: "_id", Value: "0x01_2021-05-04T00:00:00Z"}}))
+
+	// sync events carry no price, but close the reserves
+	set := candleSetStage(g, um)
+	g.Expect(set).NotTo(gomega.HaveKey("open"))
+	g.Expect(set).NotTo(gomega.HaveKey("cnt"))
+	g.Expect(set).To(gomega.HaveKey("res0"))
+	g.Expect(set).To(gomega.HaveKey("res1"))
+	g.Expect(set["orxr"]).To(gomega.Equal(bson.D{{Key: "$max", Value: bson.A{"$orxr", int64(78)}}}))
+}
+
+func TestUniswapCandlesStateDefer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	ts := hexutil.Uint64(1620131696)
+	swap := types.Swap{
+		OrdIndex:   1,
+		Type:       types.SwapMint,
+		TimeStamp:  &ts,
+		Pair:       common.HexToAddress("0x01"),
+		Amount0In:  big.NewInt(0),
+		Amount0Out: big.NewInt(0),
+		Amount1In:  big.NewInt(0),
+		Amount1Out: big.NewInt(0),
+		Reserve0:   big.NewInt(0),
+		Reserve1:   big.NewInt(0),
+	}
+
+	// no rebuild running, the swap is applied directly
+	var st uniswapCandlesState
+	g.Expect(st.deferSwap("a", &swap)).To(gomega.BeFalse())
+	g.Expect(st.isLate("a")).To(gomega.BeFalse())
+
+	// rebuild running, the swap is collected
+	st.late = make(map[string]*uniswapCandleInput)
+	g.Expect(st.deferSwap("a", &swap)).To(gomega.BeTrue())
+	g.Expect(st.isLate("a")).To(gomega.BeTrue())
+
+	// failed insert is not applied
+	st.forget("a")
+	g.Expect(st.isLate("a")).To(gomega.BeFalse())
+
+	// the rebuild is done
+	g.Expect(st.deferSwap("b", &swap)).To(gomega.BeTrue())
+	st.stop()
+	g.Expect(st.isLate("b")).To(gomega.BeFalse())
+	g.Expect(st.deferSwap("c", &swap)).To(gomega.BeFalse())
+}
@@ -0,0 +1,204 @@
+// Package db implements bridge to persistent storage represented by Mongo database.
+package db
+
+import (
+	"context"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// colUniswapPairs represents the name of the discovered Uniswap pairs collection in database.
+const colUniswapPairs = "uniswap_pairs"
+
+// initUniswapPairsCollection initializes the Uniswap pairs collection indexes.
+func (db *MongoDbBridge) initUniswapPairsCollection(col *mongo.Collection) {
+	// prepare index models
+	ix := make([]mongo.IndexModel, 0)
+
+	// index creation block and backfill state
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: types.FiUniswapPairBlock, Value: 1}}})
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: types.FiUniswapPairBackfilled, Value: 1}}})
+
+	// create indexes
+	if _, err := col.Indexes().CreateMany(context.Background(), ix); err != nil {
+		db.log.Panicf("can not create indexes for uniswap pairs collection; %s", err.Error())
+	}
+
+	// log we are done that
+	db.log.Debugf("uniswap pairs collection initialized")
+}
+
+// UniswapPairsCount estimates the number of discovered Uniswap pairs in the database.
+func (db *MongoDbBridge) UniswapPairsCount() (uint64, error) {
+	return db.EstimateCount(db.client.Database(db.dbName).Collection(colUniswapPairs))
+}
+
+// UniswapAddPair stores the given discovered Uniswap pair; an existing record is kept untouched.
+func (db *MongoDbBridge) UniswapAddPair(pair *types.UniswapPairInfo) error {
+	// do we have anything to store at all?
+	if pair == nil {
+		return fmt.Errorf("no value to store")
+	}
+
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPairs)
+
+	// make sure the collection is initialized
+	if db.initUniswapPairs != nil {
+		db.initUniswapPairs.Do(func() { db.initUniswapPairsCollection(col); db.initUniswapPairs = nil })
+	}
+
+	// insert the pair only if it's not known yet
+	_, err := col.UpdateOne(context.Background(),
+		bson.D{{Key: types.FiUniswapPairAddress, Value: pair.Pair.String()}},
+		bson.D{{Key: "$setOnInsert", Value: pair}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		db.log.Errorf("can not store uniswap pair %s; %s", pair.Pair.String(), err.Error())
+	}
+	return err
+}
+
+// UniswapPairInfo loads details of a discovered Uniswap pair.
+func (db *MongoDbBridge) UniswapPairInfo(pair *common.Address) (*types.UniswapPairInfo, error) {
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPairs)
+
+	// try to find the pair
+	sr := col.FindOne(context.Background(), bson.D{{Key: types.FiUniswapPairAddress, Value: pair.String()}})
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		db.log.Errorf("can not load uniswap pair %s; %s", pair.String(), sr.Err().Error())
+		return nil, sr.Err()
+	}
+
+	// decode the pair
+	var row types.UniswapPairInfo
+	if err := sr.Decode(&row); err != nil {
+		db.log.Errorf("can not decode uniswap pair %s; %s", pair.String(), err.Error())
+		return nil, err
+	}
+	return &row, nil
+}
+
+// UniswapPairsList loads the list of discovered Uniswap pairs
+// matching the given filter, ordered by their creation block.
+func (db *MongoDbBridge) UniswapPairsList(filter *bson.D) ([]*types.UniswapPairInfo, error) {
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPairs)
+
+	// load the pairs
+	cr, err := col.Find(context.Background(), filter, options.Find().SetSort(bson.D{{Key: types.FiUniswapPairBlock, Value: 1}}))
+	if err != nil {
+		db.log.Errorf("can not load uniswap pairs; %s", err.Error())
+		return nil, err
+	}
+	defer db.closeCursor(cr)
+
+	// decode the list
+	list := make([]*types.UniswapPairInfo, 0)
+	for cr.Next(context.Background()) {
+		var row types.UniswapPairInfo
+		if err := cr.Decode(&row); err != nil {
+			db.log.Errorf("can not decode uniswap pair; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
+
+// UniswapPairsAddresses loads addresses of all the discovered Uniswap pairs.
+func (db *MongoDbBridge) UniswapPairsAddresses() ([]common.Address, error) {
+	list, err := db.UniswapPairsList(&bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	al := make([]common.Address, len(list))
+	for i, p := range list {
+		al[i] = p.Pair
+	}
+	return al, nil
+}
+
+// UniswapPairsToBackfill loads the list of discovered Uniswap pairs
+// with historical events not yet loaded.
+func (db *MongoDbBridge) UniswapPairsToBackfill() ([]*types.UniswapPairInfo, error) {
+	return db.UniswapPairsList(&bson.D{{Key: types.FiUniswapPairBackfilled, Value: false}})
+}
+
+// UniswapPairBackfilled marks the given Uniswap pair as having historical events loaded.
+func (db *MongoDbBridge) UniswapPairBackfilled(pair *common.Address) error {
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPairs)
+
+	// update the record
+	_, err := col.UpdateOne(context.Background(),
+		bson.D{{Key: types.FiUniswapPairAddress, Value: pair.String()}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: types.FiUniswapPairBackfilled, Value: true}}}},
+	)
+	if err != nil {
+		db.log.Errorf("can not update uniswap pair %s; %s", pair.String(), err.Error())
+	}
+	return err
+}
+
+// UniswapPairsToRebuildPositions loads the list of backfilled Uniswap pairs
+// with liquidity positions not yet built from the whole pair history.
+func (db *MongoDbBridge) UniswapPairsToRebuildPositions() ([]*types.UniswapPairInfo, error) {
+	return db.UniswapPairsList(&bson.D{
+		{Key: types.FiUniswapPairBackfilled, Value: true},
+		{Key: types.FiUniswapPairPositions, Value: bson.D{{Key: "$ne", Value: true}}},
+	})
+}
+
+// UniswapPairPositionsRebuilt marks the given Uniswap pair as having liquidity positions built.
+func (db *MongoDbBridge) UniswapPairPositionsRebuilt(pair *common.Address) error {
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPairs)
+
+	// update the record
+	_, err := col.UpdateOne(context.Background(),
+		bson.D{{Key: types.FiUniswapPairAddress, Value: pair.String()}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: types.FiUniswapPairPositions, Value: true}}}},
+	)
+	if err != nil {
+		db.log.Errorf("can not update uniswap pair %s; %s", pair.String(), err.Error())
+	}
+	return err
+}
+
+// UniswapLastPairBlock returns the creation block of the most recently discovered Uniswap pair.
+func (db *MongoDbBridge) UniswapLastPairBlock() (uint64, error) {
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPairs)
+
+	// find the most recent pair
+	sr := col.FindOne(context.Background(), bson.D{}, options.FindOne().SetSort(bson.D{{Key: types.FiUniswapPairBlock, Value: -1}}))
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+
+		db.log.Errorf("can not load the latest uniswap pair; %s", sr.Err().Error())
+		return 0, sr.Err()
+	}
+
+	// decode the pair
+	var row types.UniswapPairInfo
+	if err := sr.Decode(&row); err != nil {
+		db.log.Errorf("can not decode the latest uniswap pair; %s", err.Error())
+		return 0, err
+	}
+	return uint64(row.Block), nil
+}
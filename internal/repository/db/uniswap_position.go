@@ -0,0 +1,367 @@
+// Package db implements bridge to persistent storage represented by Mongo database.
+package db
+
+import (
+	"context"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// colUniswapPositions represents the name of the Uniswap liquidity positions collection in database.
+const colUniswapPositions = "uniswap_positions"
+
+// initUniswapPositionsCollection initializes the Uniswap liquidity positions collection indexes.
+func (db *MongoDbBridge) initUniswapPositionsCollection(col *mongo.Collection) {
+	// prepare index models
+	ix := make([]mongo.IndexModel, 0)
+
+	// index owner, pair and their combination with the ordinal index used for listing
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: types.FiUniswapPositionOwner, Value: 1}}})
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: types.FiUniswapPositionPair, Value: 1}, {Key: types.FiUniswapPositionOrdinal, Value: -1}}})
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{{Key: types.FiUniswapPositionValue, Value: 1}}})
+
+	// create indexes
+	if _, err := col.Indexes().CreateMany(context.Background(), ix); err != nil {
+		db.log.Panicf("can not create indexes for uniswap positions collection; %s", err.Error())
+	}
+
+	// log we are done that
+	db.log.Debugf("uniswap positions collection initialized")
+}
+
+// UniswapPositionsCount estimates the number of Uniswap liquidity positions in the database.
+func (db *MongoDbBridge) UniswapPositionsCount() (uint64, error) {
+	return db.EstimateCount(db.client.Database(db.dbName).Collection(colUniswapPositions))
+}
+
+// UniswapPosition loads the liquidity position of the given owner on the given pair.
+// Nil is returned if the position does not exist.
+func (db *MongoDbBridge) UniswapPosition(pair *common.Address, owner *common.Address) (*types.UniswapPosition, error) {
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPositions)
+
+	// try to find the position
+	sr := col.FindOne(context.Background(), bson.D{{Key: types.FiUniswapPositionPk, Value: types.UniswapPositionPk(pair, owner)}})
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+
+		db.log.Errorf("can not load uniswap position of %s on %s; %s", owner.String(), pair.String(), sr.Err().Error())
+		return nil, sr.Err()
+	}
+
+	var pos types.UniswapPosition
+	if err := sr.Decode(&pos); err != nil {
+		db.log.Errorf("can not decode uniswap position of %s on %s; %s", owner.String(), pair.String(), err.Error())
+		return nil, err
+	}
+	return &pos, nil
+}
+
+// UniswapStorePosition stores the given liquidity position changed by the pair event
+// of the given ordinal and marks the event as the last one applied to the position,
+// an existing position is replaced. It returns false if the event, or any later one,
+// has already been applied to the position before.
+func (db *MongoDbBridge) UniswapStorePosition(pos *types.UniswapPosition, ord uint64) (bool, error) {
+	// do we have anything to store at all?
+	if pos == nil {
+		return false, fmt.Errorf("no value to store")
+	}
+
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPositions)
+
+	// make sure the collection is initialized
+	if db.initUniswapPositions != nil {
+		db.initUniswapPositions.Do(func() { db.initUniswapPositionsCollection(col); db.initUniswapPositions = nil })
+	}
+
+	// the position is replaced only if the event is newer than the last one applied;
+	// otherwise the upsert collides with the existing position on the primary key
+	pos.LastEvent = ord
+	_, err := col.ReplaceOne(context.Background(),
+		bson.D{
+			{Key: types.FiUniswapPositionPk, Value: pos.Pk()},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: types.FiUniswapPositionApplied, Value: bson.D{{Key: "$lt", Value: ord}}}},
+				bson.D{{Key: types.FiUniswapPositionApplied, Value: bson.D{{Key: "$exists", Value: false}}}},
+			}},
+		},
+		pos,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+
+		db.log.Errorf("can not store uniswap position %s; %s", pos.Pk(), err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+// UniswapDropPositions removes all the liquidity positions of the given pair.
+func (db *MongoDbBridge) UniswapDropPositions(pair *common.Address) error {
+	// get the collection
+	col := db.client.Database(db.dbName).Collection(colUniswapPositions)
+
+	_, err := col.DeleteMany(context.Background(), bson.D{{Key: types.FiUniswapPositionPair, Value: pair.String()}})
+	if err != nil {
+		db.log.Errorf("can not drop uniswap positions of %s; %s", pair.String(), err.Error())
+	}
+	return err
+}
+
+// UniswapPositionsByOwner loads all the open liquidity positions of the given owner.
+func (db *MongoDbBridge) UniswapPositionsByOwner(owner *common.Address) ([]*types.UniswapPosition, error) {
+	// get the collection and context
+	col := db.client.Database(db.dbName).Collection(colUniswapPositions)
+	ctx := context.Background()
+
+	ld, err := col.Find(ctx, bson.D{
+		{Key: types.FiUniswapPositionOwner, Value: owner.String()},
+		{Key: types.FiUniswapPositionValue, Value: bson.D{{Key: "$gt", Value: 0}}},
+	}, options.Find().SetSort(bson.D{{Key: types.FiUniswapPositionOrdinal, Value: -1}}))
+	if err != nil {
+		db.log.Errorf("can not load uniswap positions of %s; %s", owner.String(), err.Error())
+		return nil, err
+	}
+
+	// close the cursor as we leave
+	defer db.closeCursor(ld)
+
+	list := make([]*types.UniswapPosition, 0)
+	for ld.Next(ctx) {
+		var row types.UniswapPosition
+		if err = ld.Decode(&row); err != nil {
+			db.log.Errorf("can not decode uniswap position; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
+
+// UniswapPositionsByPair pulls list of open liquidity positions of the given pair starting at the specified cursor.
+func (db *MongoDbBridge) UniswapPositionsByPair(pair *common.Address, cursor *string, count int32) (*types.UniswapPositionList, error) {
+	// nothing to load?
+	if count == 0 {
+		return nil, fmt.Errorf("nothing to do, zero uniswap positions requested")
+	}
+
+	// get the collection and context
+	col := db.client.Database(db.dbName).Collection(colUniswapPositions)
+	filter := bson.D{
+		{Key: types.FiUniswapPositionPair, Value: pair.String()},
+		{Key: types.FiUniswapPositionValue, Value: bson.D{{Key: "$gt", Value: 0}}},
+	}
+
+	// init the list
+	list, err := db.upsListInit(col, cursor, count, &filter)
+	if err != nil {
+		db.log.Errorf("can not build uniswap positions list; %s", err.Error())
+		return nil, err
+	}
+
+	// load data if there are any
+	if list.Total > 0 {
+		err = db.upsListLoad(col, cursor, count, list)
+		if err != nil {
+			db.log.Errorf("can not load uniswap positions list from database; %s", err.Error())
+			return nil, err
+		}
+
+		// reverse on negative so new-er positions will be on top
+		if count < 0 {
+			list.Reverse()
+			count = -count
+		}
+
+		// cut the end?
+		if len(list.Collection) > int(count) {
+			list.Collection = list.Collection[:len(list.Collection)-1]
+		}
+	}
+	return list, nil
+}
+
+// upsListInit initializes list of liquidity positions based on provided cursor, count, and filter.
+func (db *MongoDbBridge) upsListInit(col *mongo.Collection, cursor *string, count int32, filter *bson.D) (*types.UniswapPositionList, error) {
+	// find how many positions do we have in the database
+	total, err := col.CountDocuments(context.Background(), *filter)
+	if err != nil {
+		db.log.Errorf("can not count uniswap positions")
+		return nil, err
+	}
+
+	// make the list and notify the size of it
+	db.log.Debugf("found %d filtered uniswap positions", total)
+	list := types.UniswapPositionList{
+		Collection: make([]*types.UniswapPosition, 0),
+		Total:      uint64(total),
+		First:      0,
+		Last:       0,
+		IsStart:    total == 0,
+		IsEnd:      total == 0,
+		Filter:     *filter,
+	}
+
+	// is the list non-empty? return the list with properly calculated range marks
+	if 0 < total {
+		return db.upsListCollectRangeMarks(col, &list, cursor, count)
+	}
+
+	// this is an empty list
+	db.log.Debug("empty uniswap positions list created")
+	return &list, nil
+}
+
+// upsListCollectRangeMarks returns a list of liquidity positions with proper First/Last marks.
+func (db *MongoDbBridge) upsListCollectRangeMarks(col *mongo.Collection, list *types.UniswapPositionList, cursor *string, count int32) (*types.UniswapPositionList, error) {
+	var err error
+
+	// find out the cursor ordinal index
+	if cursor == nil && count > 0 {
+		// get the highest available pk
+		list.First, err = db.upsListBorderPk(col,
+			list.Filter,
+			options.FindOne().SetSort(bson.D{{Key: types.FiUniswapPositionOrdinal, Value: -1}}))
+		list.IsStart = true
+
+	} else if cursor == nil && count < 0 {
+		// get the lowest available pk
+		list.First, err = db.upsListBorderPk(col,
+			list.Filter,
+			options.FindOne().SetSort(bson.D{{Key: types.FiUniswapPositionOrdinal, Value: 1}}))
+		list.IsEnd = true
+
+	} else if cursor != nil {
+		// look for the first ordinal to make sure it's there
+		list.First, err = db.upsListBorderPk(col,
+			append(list.Filter, bson.E{Key: types.FiUniswapPositionPk, Value: *cursor}),
+			options.FindOne())
+	}
+
+	// check the error
+	if err != nil {
+		db.log.Errorf("can not find the initial uniswap position; %s", err.Error())
+		return nil, err
+	}
+
+	// inform what we are about to do
+	db.log.Debugf("uniswap positions list starts from #%d", list.First)
+	return list, nil
+}
+
+// upsListBorderPk finds the top PK of the liquidity positions collection based on given filter and options.
+func (db *MongoDbBridge) upsListBorderPk(col *mongo.Collection, filter bson.D, opt *options.FindOneOptions) (uint64, error) {
+	// prep container
+	var row struct {
+		Value uint64 `bson:"orx"`
+	}
+
+	// make sure we pull only what we need
+	opt.SetProjection(bson.D{{Key: types.FiUniswapPositionOrdinal, Value: true}})
+	sr := col.FindOne(context.Background(), filter, opt)
+
+	// try to decode
+	err := sr.Decode(&row)
+	if err != nil {
+		return 0, err
+	}
+	return row.Value, nil
+}
+
+// upsListFilter creates a filter for liquidity positions list loading.
+func (db *MongoDbBridge) upsListFilter(cursor *string, count int32, list *types.UniswapPositionList) *bson.D {
+	// build an extended filter for the query; add PK (decoded cursor) to the original filter
+	if cursor == nil {
+		if count > 0 {
+			list.Filter = append(list.Filter, bson.E{Key: types.FiUniswapPositionOrdinal, Value: bson.D{{Key: "$lte", Value: list.First}}})
+		} else {
+			list.Filter = append(list.Filter, bson.E{Key: types.FiUniswapPositionOrdinal, Value: bson.D{{Key: "$gte", Value: list.First}}})
+		}
+	} else {
+		if count > 0 {
+			list.Filter = append(list.Filter, bson.E{Key: types.FiUniswapPositionOrdinal, Value: bson.D{{Key: "$lt", Value: list.First}}})
+		} else {
+			list.Filter = append(list.Filter, bson.E{Key: types.FiUniswapPositionOrdinal, Value: bson.D{{Key: "$gt", Value: list.First}}})
+		}
+	}
+
+	// return the new filter
+	return &list.Filter
+}
+
+// upsListOptions creates a filter options set for liquidity positions list search.
+func (db *MongoDbBridge) upsListOptions(count int32) *options.FindOptions {
+	// prep options
+	opt := options.Find()
+
+	// how to sort results in the collection
+	// from high (new) to low (old) by default; reversed if loading from bottom
+	sd := -1
+	if count < 0 {
+		sd = 1
+		count = -count
+	}
+
+	// sort with the direction we want
+	opt.SetSort(bson.D{{Key: types.FiUniswapPositionOrdinal, Value: sd}})
+
+	// apply the limit, try to get one more record so we can detect list end
+	opt.SetLimit(int64(count) + 1)
+	return opt
+}
+
+// upsListLoad load the initialized list of liquidity positions from database.
+func (db *MongoDbBridge) upsListLoad(col *mongo.Collection, cursor *string, count int32, list *types.UniswapPositionList) (err error) {
+	// get the context for loader
+	ctx := context.Background()
+
+	// load the data
+	ld, err := col.Find(ctx, db.upsListFilter(cursor, count, list), db.upsListOptions(count))
+	if err != nil {
+		db.log.Errorf("error loading uniswap positions list; %s", err.Error())
+		return err
+	}
+
+	// close the cursor as we leave
+	defer db.closeCursor(ld)
+
+	// loop and load the list; we may not store the last value
+	var pos *types.UniswapPosition
+	for ld.Next(ctx) {
+		// append a previous value to the list, if we have one
+		if pos != nil {
+			list.Collection = append(list.Collection, pos)
+		}
+
+		// try to decode the next row
+		var row types.UniswapPosition
+		if err = ld.Decode(&row); err != nil {
+			db.log.Errorf("can not decode the uniswap positions list row; %s", err.Error())
+			return err
+		}
+
+		// use this row as the next item
+		pos = &row
+	}
+
+	// we should have all the items already; we may just need to check if a boundary was reached
+	list.IsEnd = (cursor == nil && count < 0) || (count > 0 && int32(len(list.Collection)) < count)
+	list.IsStart = (cursor == nil && count > 0) || (count < 0 && int32(len(list.Collection)) < -count)
+
+	// add the last item as well if we hit the boundary
+	if (list.IsStart || list.IsEnd) && pos != nil {
+		list.Collection = append(list.Collection, pos)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Package db implements bridge to persistent storage represented by Mongo database.
+package db
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// colWebhooks represents the name of the registered webhooks collection in database.
+	colWebhooks = "webhooks"
+
+	// colWebhookDeliveries represents the name of the webhook deliveries collection in database.
+	colWebhookDeliveries = "webhook_deliveries"
+)
+
+// initWebhookDeliveriesCollection initializes the webhook deliveries collection indexes.
+func (db *MongoDbBridge) initWebhookDeliveriesCollection(col *mongo.Collection) {
+	// prepare index models
+	ix := make([]mongo.IndexModel, 0)
+
+	// index webhook + creation time for the delivery log
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{
+		{Key: types.FiWebhookDeliveryHook, Value: 1},
+		{Key: types.FiWebhookDeliveryCreated, Value: -1},
+	}})
+
+	// index status + next attempt time for the retries
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{
+		{Key: types.FiWebhookDeliveryStatus, Value: 1},
+		{Key: types.FiWebhookDeliveryNext, Value: 1},
+	}})
+
+	// index status + last attempt time for the retention sweep
+	ix = append(ix, mongo.IndexModel{Keys: bson.D{
+		{Key: types.FiWebhookDeliveryStatus, Value: 1},
+		{Key: types.FiWebhookDeliveryLast, Value: 1},
+	}})
+
+	// create indexes
+	if _, err := col.Indexes().CreateMany(context.Background(), ix); err != nil {
+		db.log.Panicf("can not create indexes for webhook deliveries collection; %s", err.Error())
+	}
+
+	// log we are done that
+	db.log.Debugf("webhook deliveries collection initialized")
+}
+
+// WebhookDeliveriesCount returns the number of webhook deliveries stored in the database.
+func (db *MongoDbBridge) WebhookDeliveriesCount() (uint64, error) {
+	return db.EstimateCount(db.client.Database(db.dbName).Collection(colWebhookDeliveries))
+}
+
+// AddWebhook stores the given webhook registration.
+func (db *MongoDbBridge) AddWebhook(wh *types.Webhook) error {
+	col := db.client.Database(db.dbName).Collection(colWebhooks)
+	if _, err := col.InsertOne(context.Background(), wh); err != nil {
+		db.log.Errorf("can not store webhook %s; %s", wh.Id, err.Error())
+		return err
+	}
+	return nil
+}
+
+// RemoveWebhook removes the webhook registration of the given id.
+// The delivery log of the webhook is kept. False is returned if the webhook is not known.
+func (db *MongoDbBridge) RemoveWebhook(id string) (bool, error) {
+	col := db.client.Database(db.dbName).Collection(colWebhooks)
+
+	res, err := col.DeleteOne(context.Background(), bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		db.log.Errorf("can not remove webhook %s; %s", id, err.Error())
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// Webhook loads the webhook registration of the given id. Nil is returned if the webhook is not known.
+func (db *MongoDbBridge) Webhook(id string) (*types.Webhook, error) {
+	col := db.client.Database(db.dbName).Collection(colWebhooks)
+
+	sr := col.FindOne(context.Background(), bson.D{{Key: "_id", Value: id}})
+	if sr.Err() != nil {
+		if sr.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		db.log.Errorf("can not load webhook %s; %s", id, sr.Err().Error())
+		return nil, sr.Err()
+	}
+
+	var wh types.Webhook
+	if err := sr.Decode(&wh); err != nil {
+		db.log.Errorf("can not decode webhook %s; %s", id, err.Error())
+		return nil, err
+	}
+	return &wh, nil
+}
+
+// Webhooks loads all the registered webhooks.
+func (db *MongoDbBridge) Webhooks() ([]*types.Webhook, error) {
+	col := db.client.Database(db.dbName).Collection(colWebhooks)
+	ctx := context.Background()
+
+	ld, err := col.Find(ctx, bson.D{})
+	if err != nil {
+		db.log.Errorf("can not load webhooks; %s", err.Error())
+		return nil, err
+	}
+
+	defer db.closeCursor(ld)
+
+	list := make([]*types.Webhook, 0)
+	for ld.Next(ctx) {
+		var row types.Webhook
+		if err := ld.Decode(&row); err != nil {
+			db.log.Errorf("can not decode webhook; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
+
+// StoreWebhookDelivery stores the given webhook delivery, an existing delivery is updated.
+func (db *MongoDbBridge) StoreWebhookDelivery(wd *types.WebhookDelivery) error {
+	col := db.client.Database(db.dbName).Collection(colWebhookDeliveries)
+
+	// make sure deliveries collection is initialized
+	if db.initWebhookDeliveries != nil {
+		db.initWebhookDeliveries.Do(func() { db.initWebhookDeliveriesCollection(col); db.initWebhookDeliveries = nil })
+	}
+
+	_, err := col.ReplaceOne(context.Background(), bson.D{{Key: "_id", Value: wd.Id}}, wd, options.Replace().SetUpsert(true))
+	if err != nil {
+		db.log.Errorf("can not store webhook delivery %s; %s", wd.Id, err.Error())
+		return err
+	}
+	return nil
+}
+
+// WebhookDeliveries loads the most recent deliveries of the given webhook,
+// optionally limited to the deliveries of the given status.
+func (db *MongoDbBridge) WebhookDeliveries(hook string, status *string, count int64) ([]*types.WebhookDelivery, error) {
+	filter := bson.D{{Key: types.FiWebhookDeliveryHook, Value: hook}}
+	if status != nil {
+		filter = append(filter, bson.E{Key: types.FiWebhookDeliveryStatus, Value: *status})
+	}
+
+	return db.webhookDeliveries(filter,
+		options.Find().SetSort(bson.D{{Key: types.FiWebhookDeliveryCreated, Value: -1}}).SetLimit(count))
+}
+
+// DueWebhookDeliveries loads pending webhook deliveries scheduled to be attempted by the given time.
+func (db *MongoDbBridge) DueWebhookDeliveries(due time.Time, count int64) ([]*types.WebhookDelivery, error) {
+	return db.webhookDeliveries(bson.D{
+		{Key: types.FiWebhookDeliveryStatus, Value: types.WebhookDeliveryPending},
+		{Key: types.FiWebhookDeliveryNext, Value: bson.D{{Key: "$lte", Value: due}}},
+	}, options.Find().SetSort(bson.D{{Key: types.FiWebhookDeliveryNext, Value: 1}}).SetLimit(count))
+}
+
+// PurgeWebhookDeliveries removes the finished webhook deliveries, both delivered and dead,
+// last attempted before the given time. It returns the number of removed deliveries.
+func (db *MongoDbBridge) PurgeWebhookDeliveries(before time.Time) (int64, error) {
+	col := db.client.Database(db.dbName).Collection(colWebhookDeliveries)
+
+	res, err := col.DeleteMany(context.Background(), bson.D{
+		{Key: types.FiWebhookDeliveryStatus, Value: bson.D{{Key: "$in", Value: bson.A{types.WebhookDeliveryDelivered, types.WebhookDeliveryDead}}}},
+		{Key: types.FiWebhookDeliveryLast, Value: bson.D{{Key: "$lt", Value: before}}},
+	})
+	if err != nil {
+		db.log.Errorf("can not purge webhook deliveries; %s", err.Error())
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// webhookDeliveries loads webhook deliveries matching the given filter.
+func (db *MongoDbBridge) webhookDeliveries(filter bson.D, opt *options.FindOptions) ([]*types.WebhookDelivery, error) {
+	col := db.client.Database(db.dbName).Collection(colWebhookDeliveries)
+	ctx := context.Background()
+
+	ld, err := col.Find(ctx, filter, opt)
+	if err != nil {
+		db.log.Errorf("can not load webhook deliveries; %s", err.Error())
+		return nil, err
+	}
+
+	defer db.closeCursor(ld)
+
+	list := make([]*types.WebhookDelivery, 0)
+	for ld.Next(ctx) {
+		var row types.WebhookDelivery
+		if err := ld.Decode(&row); err != nil {
+			db.log.Errorf("can not decode webhook delivery; %s", err.Error())
+			return nil, err
+		}
+		list = append(list, &row)
+	}
+	return list, nil
+}
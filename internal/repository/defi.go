@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"math"
+	"math/big"
 	"ncogearthchain-api-graphql/internal/repository/rpc/contracts"
 	"ncogearthchain-api-graphql/internal/types"
 
@@ -29,6 +31,24 @@ func (p *proxy) DefiTokenPrice(token *common.Address) (hexutil.Big, error) {
 	return p.rpc.FMintTokenPrice(token)
 }
 
+// defiTokenPriceValue provides the price of a single token unit in fUSD
+// from on-chain price oracle. False is returned if the oracle does not know the token.
+func (p *proxy) defiTokenPriceValue(token *common.Address) (float64, bool) {
+	price, err := p.DefiTokenPrice(token)
+	if err != nil || price.ToInt().Sign() <= 0 {
+		return 0, false
+	}
+
+	// the oracle price decimals are defined by the token registry
+	pd := int32(18)
+	if tk, err := p.DefiToken(token); err == nil {
+		pd = tk.PriceDecimals
+	}
+
+	val, _ := new(big.Float).Quo(new(big.Float).SetInt(price.ToInt()), big.NewFloat(math.Pow10(int(pd)))).Float64()
+	return val, true
+}
+
 // FMintAccount loads details of a DeFi/fMint account identified by the owner address.
 func (p *proxy) FMintAccount(owner common.Address) (*types.FMintAccount, error) {
 	return p.rpc.FMintAccount(&owner)
@@ -28,6 +28,9 @@ type Repository interface {
 	// AccountBalance returns the current balance of an account at Ncogearthchain blockchain.
 	AccountBalance(*common.Address) (*hexutil.Big, error)
 
+	// Portfolio provides the valuation of all the holdings of an account in the target currency.
+	Portfolio(*common.Address, string) (*types.Portfolio, error)
+
 	// AccountNonce returns the current number of sent transactions of an account at Ncogearthchain blockchain.
 	AccountNonce(*common.Address) (*hexutil.Uint64, error)
 
@@ -72,6 +75,21 @@ type Repository interface {
 	// by the connected blockchain node.
 	ObservedHeaders() chan *etc.Header
 
+	// ObservedPending provides a channel fed with hashes of new transactions
+	// entering the pool of the connected blockchain node.
+	ObservedPending() chan common.Hash
+
+	// TxPoolStatus provides the number of transactions waiting in the pool of the connected node.
+	TxPoolStatus() (*types.TxPoolStatus, error)
+
+	// PendingTransactions provides the list of transactions waiting in the pool
+	// of the connected node sent from, or to the given address.
+	PendingTransactions(*common.Address) ([]*types.Transaction, error)
+
+	// PoolTransactionsFrom provides the list of transactions sent from the given address
+	// waiting in the pool of the connected node; the pool is read directly from the node.
+	PoolTransactionsFrom(*common.Address) ([]*types.Transaction, error)
+
 	// BlockByNumber returns a block at Ncogearthchain blockchain represented by a number.
 	// Top block is returned if the number is not provided.
 	// If the block is not found, ErrBlockNotFound error is returned.
@@ -121,6 +139,9 @@ type Repository interface {
 	// Epoch returns the id of the current epoch.
 	Epoch(*hexutil.Uint64) (*types.Epoch, error)
 
+	// EpochValidatorRewards returns the summary of rewards distributed to validators in the given sealed epoch.
+	EpochValidatorRewards(hexutil.Uint64) ([]types.EpochValidatorRewards, error)
+
 	// CurrentSealedEpoch returns the data of the latest sealed epoch.
 	CurrentSealedEpoch() (*types.Epoch, error)
 
@@ -155,6 +176,10 @@ type Repository interface {
 	// Transactions returns list of transaction hashes at Ncogearthchain blockchain.
 	Transactions(*string, int32) (*types.TransactionList, error)
 
+	// TransactionsFrom returns up to the given number of transactions stored in the database
+	// with the ordinal index at or above the given one, sorted from older to newer.
+	TransactionsFrom(uint64, int64) ([]*types.Transaction, error)
+
 	// TransactionsCount returns total number of transactions in the block chain.
 	TransactionsCount() (uint64, error)
 
@@ -173,6 +198,18 @@ type Repository interface {
 	// SendTransaction sends raw signed and RLP encoded transaction to the block chain.
 	SendTransaction(hexutil.Bytes) (*types.Transaction, error)
 
+	// ChainID returns the chain identifier of the connected Ncogearthchain blockchain.
+	ChainID() (*big.Int, error)
+
+	// DryRunTransaction executes the given signed transaction of the given sender against
+	// the pending state of the blockchain without broadcasting it.
+	DryRunTransaction(*etc.Transaction, *common.Address) error
+
+	// SimulateTransaction executes the given call against the state of the given block
+	// with the state overrides applied and provides the outcome including decoded logs
+	// and net balance changes of the involved accounts.
+	SimulateTransaction(*types.SimulationCall, *hexutil.Uint64, map[common.Address]types.StateOverride) (*types.TransactionSimulation, error)
+
 	// LastValidatorId returns the last validator id in Ncogearthchain blockchain.
 	LastValidatorId() (uint64, error)
 
@@ -286,6 +323,18 @@ type Repository interface {
 	// Price returns a price information for the given target symbol.
 	Price(sym string) (types.Price, error)
 
+	// ObservePriceSources lets the price sources which need periodic observations take a new one.
+	ObservePriceSources()
+
+	// StorePriceSample pulls the current price for the given target symbol and stores it in the price history.
+	StorePriceSample(sym string) error
+
+	// PriceTicks provides a list of price samples, or daily prices, for the given target symbol and time period.
+	PriceTicks(sym string, from *time.Time, to *time.Time, daily bool) ([]types.PriceTick, error)
+
+	// PriceAt provides the price for the given target symbol at the given time, if known.
+	PriceAt(sym string, at time.Time) (*types.PriceTick, error)
+
 	// GasPrice provides the raw suggested value for the gas price.
 	GasPrice() (hexutil.Big, error)
 
@@ -362,6 +411,36 @@ type Repository interface {
 	// UniswapKnownPairs returns list of all known and whitelisted token pairs managed by Uniswap core.
 	UniswapKnownPairs() ([]common.Address, error)
 
+	// UniswapIsFeaturedPair checks if the given Uniswap pair is featured by the configured white list.
+	UniswapIsFeaturedPair(*common.Address) bool
+
+	// UniswapAddPair stores a new Uniswap pair discovered from the factory PairCreated event.
+	UniswapAddPair(*types.UniswapPairInfo) error
+
+	// UniswapPairInfo returns details of a discovered Uniswap pair, if available.
+	UniswapPairInfo(*common.Address) (*types.UniswapPairInfo, error)
+
+	// UniswapPairsToBackfill returns list of discovered Uniswap pairs with historical events not loaded yet.
+	UniswapPairsToBackfill() ([]*types.UniswapPairInfo, error)
+
+	// UniswapPairBackfilled marks the given Uniswap pair as having historical events loaded.
+	UniswapPairBackfilled(*common.Address) error
+
+	// UniswapPairsToRebuildPositions returns list of backfilled Uniswap pairs with liquidity positions not built yet.
+	UniswapPairsToRebuildPositions() ([]*types.UniswapPairInfo, error)
+
+	// UniswapPairPositionsRebuilt marks the given Uniswap pair as having liquidity positions built.
+	UniswapPairPositionsRebuilt(*common.Address) error
+
+	// UniswapLastPairBlock returns the creation block of the most recently discovered Uniswap pair.
+	UniswapLastPairBlock() (uint64, error)
+
+	// UniswapPairCreatedLogs loads PairCreated events of the Uniswap factory in the given block range.
+	UniswapPairCreatedLogs(uint64, uint64) ([]etc.Log, error)
+
+	// UniswapPairLogs loads all the events of the given Uniswap pair in the given block range.
+	UniswapPairLogs(*common.Address, uint64, uint64) ([]etc.Log, error)
+
 	// UniswapPair returns an address of an Uniswap pair for the given tokens.
 	UniswapPair(*common.Address, *common.Address) (*common.Address, error)
 
@@ -383,6 +462,22 @@ type Repository interface {
 	// UniswapReserves returns list of token reserve amounts in a Uniswap pair.
 	UniswapReserves(*common.Address) ([]hexutil.Big, error)
 
+	// UniswapReservesChanged notifies the repository about reserves of the given pair being changed.
+	UniswapReservesChanged(*common.Address)
+
+	// UniswapBestRoutes finds the best routes for swapping tokens across known Uniswap pairs.
+	UniswapBestRoutes(*common.Address, *common.Address, *big.Int, bool, int, int, float64) ([]*types.UniswapRoute, error)
+
+	// UniswapQuote calculates the swap of an amount along the given list of tokens
+	// with the price impact, fees and limits for the given slippage tolerance.
+	UniswapQuote([]common.Address, *big.Int, bool, float64) (*types.UniswapRoute, error)
+
+	// UniswapAddLiquidityQuote calculates the preview of adding liquidity to a Uniswap pair.
+	UniswapAddLiquidityQuote(*common.Address, []hexutil.Big, float64) (*types.UniswapLiquidityQuote, error)
+
+	// UniswapRemoveLiquidityQuote calculates the preview of removing liquidity from a Uniswap pair.
+	UniswapRemoveLiquidityQuote(*common.Address, *big.Int, float64) (*types.UniswapLiquidityQuote, error)
+
 	// UniswapReservesTimeStamp returns the timestamp of the reserves of a Uniswap pair.
 	UniswapReservesTimeStamp(*common.Address) (hexutil.Uint64, error)
 
@@ -419,6 +514,37 @@ type Repository interface {
 	// UniswapTimeReserves returns grouped reserves for specified pair, time and resolution
 	UniswapTimeReserves(*common.Address, string, int64, int64) ([]types.DefiTimeReserve, error)
 
+	// UniswapCandlesRebuild builds the Uniswap candle rollups from stored swaps and replaces the live rollups.
+	UniswapCandlesRebuild() error
+
+	// UniswapCandlesReady checks if the Uniswap candle rollups have been fully built from stored swaps.
+	UniswapCandlesReady() bool
+
+	// UniswapPairAnalytics provides the pre-calculated statistics of the given Uniswap pair.
+	UniswapPairAnalytics(*common.Address) (*types.UniswapPairAnalytics, error)
+
+	// UniswapUpdatePairAnalytics calculates the statistics of the given Uniswap pair and caches them.
+	UniswapUpdatePairAnalytics(*common.Address) (*types.UniswapPairAnalytics, error)
+
+	// UniswapPosition loads the liquidity position of the given owner on the given pair.
+	UniswapPosition(*common.Address, *common.Address) (*types.UniswapPosition, error)
+
+	// UniswapStorePosition stores the given liquidity position changed by the pair event of the given ordinal.
+	// It returns false if the event, or any later one, has already been applied to the position before.
+	UniswapStorePosition(*types.UniswapPosition, uint64) (bool, error)
+
+	// UniswapDropPositions removes all the liquidity positions of the given pair.
+	UniswapDropPositions(*common.Address) error
+
+	// UniswapPositionsByOwner loads all the open liquidity positions of the given owner.
+	UniswapPositionsByOwner(*common.Address) ([]*types.UniswapPosition, error)
+
+	// UniswapPositionsByPair loads a list of open liquidity positions of the given pair.
+	UniswapPositionsByPair(*common.Address, *string, int32) (*types.UniswapPositionList, error)
+
+	// UniswapPositionValue calculates the current value of the given liquidity position.
+	UniswapPositionValue(*types.UniswapPosition) (*types.UniswapPositionValue, error)
+
 	// UniswapActions provides list of uniswap actions stored in the persistent db.
 	UniswapActions(*common.Address, *string, int32, int32) (*types.UniswapActionList, error)
 
@@ -605,6 +731,30 @@ type Repository interface {
 	// NecBurnList provides list of per-block burned native NEC tokens.
 	NecBurnList(count int64) ([]types.NecBurn, error)
 
+	// AddWebhook stores the given webhook registration.
+	AddWebhook(*types.Webhook) error
+
+	// RemoveWebhook removes the webhook registration of the given id.
+	RemoveWebhook(string) (bool, error)
+
+	// Webhook loads the webhook registration of the given id.
+	Webhook(string) (*types.Webhook, error)
+
+	// Webhooks loads all the registered webhooks.
+	Webhooks() ([]*types.Webhook, error)
+
+	// StoreWebhookDelivery stores the given webhook delivery, an existing delivery is updated.
+	StoreWebhookDelivery(*types.WebhookDelivery) error
+
+	// WebhookDeliveries loads the most recent deliveries of the given webhook.
+	WebhookDeliveries(string, *string, int64) ([]*types.WebhookDelivery, error)
+
+	// DueWebhookDeliveries loads pending webhook deliveries scheduled to be attempted by the given time.
+	DueWebhookDeliveries(time.Time, int64) ([]*types.WebhookDelivery, error)
+
+	// PurgeWebhookDeliveries removes the finished webhook deliveries last attempted before the given time.
+	PurgeWebhookDeliveries(time.Time) (int64, error)
+
 	// Close and cleanup the repository.
 	Close()
 
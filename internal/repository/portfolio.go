@@ -0,0 +1,475 @@
+package repository
+
+import (
+	"fmt"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// portfolioErc20Limit represents the max number of ERC20 assets evaluated in a portfolio.
+const portfolioErc20Limit = 250
+
+// portfolioErc20Workers represents the max number of ERC20 assets evaluated in parallel.
+const portfolioErc20Workers = 8
+
+// portfolioPricing represents the set of prices used to evaluate portfolio items.
+type portfolioPricing struct {
+	// native is the price of the native token in the target currency
+	native float64
+
+	// usd is the price of a single fUSD in the target currency
+	usd float64
+
+	// wrapped is the address of the native token wrapper, if available
+	wrapped *common.Address
+}
+
+// portfolioCollector collects portfolio items from concurrent loaders.
+// Each loader has its own slot, so the order of the items is stable.
+type portfolioCollector struct {
+	mu    sync.Mutex
+	wg    sync.WaitGroup
+	slots [][]types.PortfolioItem
+	err   error
+}
+
+// Portfolio provides the valuation of all the holdings of the given account
+// in the target currency. The valuation is cached per block.
+func (p *proxy) Portfolio(addr *common.Address, currency string) (*types.Portfolio, error) {
+	// check the symbol validity
+	cur := strings.ToUpper(currency)
+	if !p.isValidPriceSymbol(cur) {
+		return nil, fmt.Errorf("unknown price symbol requested")
+	}
+
+	// the valuation is bound to the current block
+	height, err := p.BlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	blk := hexutil.Uint64(height.ToInt().Uint64())
+
+	// try the cache first
+	if pf := p.cache.PullPortfolio(addr, cur, blk); pf != nil {
+		return pf, nil
+	}
+
+	val, err, _ := p.apiRequestGroup.Do(fmt.Sprintf("portfolio+%s+%s+%d", addr.String(), cur, blk), func() (interface{}, error) {
+		pf, err := p.portfolio(addr, cur, blk)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.cache.PushPortfolio(pf); err != nil {
+			p.log.Errorf("can not cache portfolio of %s; %s", addr.String(), err.Error())
+		}
+		return pf, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.(*types.Portfolio), nil
+}
+
+// portfolio calculates the valuation of all the holdings of the given account.
+func (p *proxy) portfolio(addr *common.Address, cur string, blk hexutil.Uint64) (*types.Portfolio, error) {
+	pr, err := p.portfolioPricing(cur)
+	if err != nil {
+		return nil, err
+	}
+
+	// load all the holdings concurrently
+	loaders := []func(*common.Address, *portfolioPricing) ([]types.PortfolioItem, error){
+		p.portfolioNative,
+		p.portfolioDelegations,
+		p.portfolioErc20,
+		p.portfolioFMint,
+		p.portfolioFLend,
+		p.portfolioLiquidity,
+	}
+
+	col := portfolioCollector{slots: make([][]types.PortfolioItem, len(loaders))}
+	for i, ld := range loaders {
+		col.wg.Add(1)
+		go col.collect(i, ld, addr, pr)
+	}
+	col.wg.Wait()
+
+	if col.err != nil {
+		return nil, col.err
+	}
+
+	pf := types.Portfolio{
+		Address:  *addr,
+		Currency: cur,
+		Block:    blk,
+		Items:    make([]types.PortfolioItem, 0),
+	}
+	for _, items := range col.slots {
+		for _, it := range items {
+			if it.IsPriced {
+				pf.Total += it.Value
+			}
+			pf.Items = append(pf.Items, it)
+		}
+	}
+	return &pf, nil
+}
+
+// collect runs the given loader and adds the items it provides to the collection.
+func (pc *portfolioCollector) collect(slot int, ld func(*common.Address, *portfolioPricing) ([]types.PortfolioItem, error), addr *common.Address, pr *portfolioPricing) {
+	defer pc.wg.Done()
+	items, err := ld(addr, pr)
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if err != nil {
+		if pc.err == nil {
+			pc.err = err
+		}
+		return
+	}
+	pc.slots[slot] = items
+}
+
+// portfolioPricing prepares the prices used to evaluate portfolio items in the given currency.
+func (p *proxy) portfolioPricing(cur string) (*portfolioPricing, error) {
+	nec, err := p.Price(cur)
+	if err != nil {
+		return nil, err
+	}
+	pr := portfolioPricing{native: nec.Price}
+
+	// fUSD is expected to follow USD
+	if cur == "USD" {
+		pr.usd = 1
+	} else if p.isValidPriceSymbol("USD") {
+		usd, err := p.Price("USD")
+		if err == nil && usd.Price > 0 {
+			pr.usd = nec.Price / usd.Price
+		}
+	}
+
+	if pr.wrapped, err = p.NativeTokenAddress(); err != nil {
+		p.log.Errorf("native token wrapper not available; %s", err.Error())
+	}
+	return &pr, nil
+}
+
+// portfolioTokenPrice resolves the price of a single unit of the given token
+// in the target currency. The price oracle is used first, Uniswap pair with
+// the wrapped native token is used as the fallback.
+func (p *proxy) portfolioTokenPrice(token *common.Address, decimals int32, pr *portfolioPricing) (float64, bool) {
+	if pr.wrapped != nil && *token == *pr.wrapped {
+		return pr.native, true
+	}
+
+	if pr.usd > 0 {
+		if price, ok := p.defiTokenPriceValue(token); ok {
+			return price * pr.usd, true
+		}
+	}
+
+	if pr.wrapped == nil {
+		return 0, false
+	}
+
+	pair, err := p.UniswapPair(token, pr.wrapped)
+	if err != nil || pair == nil || *pair == (common.Address{}) {
+		return 0, false
+	}
+
+	rs, err := p.UniswapReserves(pair)
+	if err != nil {
+		return 0, false
+	}
+
+	tl, err := p.UniswapTokens(pair)
+	if err != nil || len(tl) != 2 || len(rs) != 2 {
+		return 0, false
+	}
+
+	ix := 0
+	if tl[1] == *token {
+		ix = 1
+	}
+
+	price := uniswapMidPrice(rs[ix].ToInt(), rs[1-ix].ToInt(), decimals, 18) * pr.native
+	return price, price > 0
+}
+
+// portfolioItemValue sets the price and the value of the item.
+func portfolioItemValue(it *types.PortfolioItem, price float64, ok bool) {
+	if !ok {
+		return
+	}
+	it.Price = price
+	it.Value = uniswapTokenValue(it.Amount.ToInt(), it.Decimals) * price
+	it.IsPriced = true
+}
+
+// portfolioNative provides the native token balance of the account.
+func (p *proxy) portfolioNative(addr *common.Address, pr *portfolioPricing) ([]types.PortfolioItem, error) {
+	bal, err := p.AccountBalance(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	it := types.PortfolioItem{
+		Type:     types.PortfolioItemNative,
+		Symbol:   ownPriceSymbol,
+		Amount:   *bal,
+		Decimals: 18,
+	}
+	portfolioItemValue(&it, pr.native, true)
+	return []types.PortfolioItem{it}, nil
+}
+
+// portfolioDelegations provides the delegations of the account and the rewards
+// pending on them. Pending withdrawals are still part of the delegation.
+// Delegations which can not be evaluated are skipped.
+func (p *proxy) portfolioDelegations(addr *common.Address, pr *portfolioPricing) ([]types.PortfolioItem, error) {
+	dl, err := p.DelegationsByAddressAll(addr)
+	if err != nil {
+		p.log.Errorf("delegations of %s not available; %s", addr.String(), err.Error())
+		return nil, nil
+	}
+
+	list := make([]types.PortfolioItem, 0, len(dl))
+	for _, dlg := range dl {
+		amo := new(big.Int)
+		if dlg.AmountDelegated != nil {
+			amo.Set(dlg.AmountDelegated.ToInt())
+		}
+
+		wr, err := p.WithdrawRequestsPendingTotal(addr, dlg.ToStakerId)
+		if err != nil {
+			p.log.Errorf("pending withdrawals of %s to #%d not available; %s", addr.String(), dlg.ToStakerId.ToInt().Uint64(), err.Error())
+			continue
+		}
+		amo.Add(amo, wr)
+
+		ref := dlg.ToStakerId.ToInt().String()
+		it := types.PortfolioItem{
+			Type:      types.PortfolioItemDelegation,
+			Symbol:    ownPriceSymbol,
+			Reference: ref,
+			Amount:    hexutil.Big(*amo),
+			Decimals:  18,
+		}
+		portfolioItemValue(&it, pr.native, true)
+		list = append(list, it)
+
+		// rewards waiting to be claimed
+		rw, err := p.PendingRewards(addr, dlg.ToStakerId)
+		if err != nil {
+			p.log.Errorf("pending rewards of %s on #%d not available; %s", addr.String(), dlg.ToStakerId.ToInt().Uint64(), err.Error())
+			continue
+		}
+		if rw.Amount.ToInt().Sign() > 0 {
+			it := types.PortfolioItem{
+				Type:      types.PortfolioItemRewards,
+				Symbol:    ownPriceSymbol,
+				Reference: ref,
+				Amount:    rw.Amount,
+				Decimals:  18,
+			}
+			portfolioItemValue(&it, pr.native, true)
+			list = append(list, it)
+		}
+	}
+	return list, nil
+}
+
+// portfolioErc20 provides the ERC20 token balances of the account.
+// Liquidity pool tokens are excluded, they are evaluated as liquidity positions.
+// The assets are evaluated by a limited number of workers; assets which can not be
+// evaluated are skipped.
+func (p *proxy) portfolioErc20(addr *common.Address, pr *portfolioPricing) ([]types.PortfolioItem, error) {
+	assets, err := p.Erc20Assets(*addr, portfolioErc20Limit)
+	if err != nil {
+		p.log.Errorf("ERC20 assets of %s not available; %s", addr.String(), err.Error())
+		return nil, nil
+	}
+
+	// each asset has its own slot, so the order of the items is kept
+	res := make([]*types.PortfolioItem, len(assets))
+	queue := make(chan int, len(assets))
+	for i := range assets {
+		queue <- i
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	for w := 0; w < portfolioErc20Workers && w < len(assets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				res[i] = p.portfolioErc20Item(addr, &assets[i], pr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	list := make([]types.PortfolioItem, 0, len(assets))
+	for _, it := range res {
+		if it != nil {
+			list = append(list, *it)
+		}
+	}
+	return list, nil
+}
+
+// portfolioErc20Item provides the balance of the given ERC20 token of the account.
+// Nil is returned if the account does not hold the token, or if the token can not be evaluated.
+func (p *proxy) portfolioErc20Item(addr *common.Address, token *common.Address, pr *portfolioPricing) *types.PortfolioItem {
+	if upi, err := p.UniswapPairInfo(token); err == nil && upi != nil {
+		return nil
+	}
+
+	bal, err := p.Erc20BalanceOf(token, addr)
+	if err != nil {
+		p.log.Errorf("balance of %s in token %s not available; %s", addr.String(), token.String(), err.Error())
+		return nil
+	}
+	if bal.ToInt().Sign() <= 0 {
+		return nil
+	}
+
+	tok, err := p.Erc20Token(token)
+	if err != nil {
+		p.log.Errorf("token %s not available; %s", token.String(), err.Error())
+		return nil
+	}
+
+	it := types.PortfolioItem{
+		Type:     types.PortfolioItemErc20,
+		Token:    *token,
+		Symbol:   tok.Symbol,
+		Amount:   bal,
+		Decimals: tok.Decimals,
+	}
+	price, ok := p.portfolioTokenPrice(token, tok.Decimals, pr)
+	portfolioItemValue(&it, price, ok)
+	return &it
+}
+
+// portfolioDebtItems provides a pair of collateral and debt items of a debt based
+// protocol position valued in fUSD. The debt item carries a negative value.
+func portfolioDebtItems(typ string, collateral *big.Int, debt *big.Int, pr *portfolioPricing) []types.PortfolioItem {
+	list := make([]types.PortfolioItem, 0, 2)
+	for i, amo := range []*big.Int{collateral, debt} {
+		if amo.Sign() <= 0 {
+			continue
+		}
+
+		it := types.PortfolioItem{
+			Type:      typ,
+			Symbol:    "fUSD",
+			Reference: "collateral",
+			Amount:    hexutil.Big(*amo),
+			Decimals:  18,
+		}
+		portfolioItemValue(&it, pr.usd, pr.usd > 0)
+		if i == 1 {
+			it.Reference = "debt"
+			it.Value = -it.Value
+		}
+		list = append(list, it)
+	}
+	return list
+}
+
+// portfolioFMint provides the fMint collateral and debt of the account.
+func (p *proxy) portfolioFMint(addr *common.Address, pr *portfolioPricing) ([]types.PortfolioItem, error) {
+	fa, err := p.FMintAccount(*addr)
+	if err != nil {
+		p.log.Errorf("fMint account of %s not available; %s", addr.String(), err.Error())
+		return nil, nil
+	}
+	return portfolioDebtItems(types.PortfolioItemFMint, fa.CollateralValue.ToInt(), fa.DebtValue.ToInt(), pr), nil
+}
+
+// portfolioFLend provides the fLend collateral and debt of the account.
+func (p *proxy) portfolioFLend(addr *common.Address, pr *portfolioPricing) ([]types.PortfolioItem, error) {
+	ud, err := p.FLendGetUserAccountData(addr)
+	if err != nil {
+		p.log.Errorf("fLend account of %s not available; %s", addr.String(), err.Error())
+		return nil, nil
+	}
+	return portfolioDebtItems(types.PortfolioItemFLend, ud.TotalCollateralFUSD.ToInt(), ud.TotalDebtFUSD.ToInt(), pr), nil
+}
+
+// portfolioLiquidity provides the Uniswap liquidity positions of the account.
+// The value of a position is the value of its share of both pool tokens.
+func (p *proxy) portfolioLiquidity(addr *common.Address, pr *portfolioPricing) ([]types.PortfolioItem, error) {
+	pl, err := p.UniswapPositionsByOwner(addr)
+	if err != nil {
+		p.log.Errorf("liquidity positions of %s not available; %s", addr.String(), err.Error())
+		return nil, nil
+	}
+
+	list := make([]types.PortfolioItem, 0, len(pl))
+	dec := make(map[common.Address]int32)
+	for _, pos := range pl {
+		if pos.Liquidity.ToInt().Sign() <= 0 {
+			continue
+		}
+
+		tl, err := p.UniswapTokens(&pos.Pair)
+		if err != nil {
+			p.log.Errorf("tokens of pair %s not available; %s", pos.Pair.String(), err.Error())
+			continue
+		}
+
+		it := types.PortfolioItem{
+			Type:      types.PortfolioItemLiquidity,
+			Token:     pos.Pair,
+			Symbol:    p.portfolioTokenSymbol(&tl[0]) + "-" + p.portfolioTokenSymbol(&tl[1]),
+			Reference: pos.Pair.String(),
+			Amount:    pos.Liquidity,
+			Decimals:  18,
+		}
+
+		val, err := p.UniswapPositionValue(pos)
+		if err != nil {
+			p.log.Errorf("value of position of %s on pair %s not available; %s", addr.String(), pos.Pair.String(), err.Error())
+			list = append(list, it)
+			continue
+		}
+
+		// both sides of the position must be priced
+		it.IsPriced = true
+		for i := range tl {
+			d := p.uniswapRouteDecimals(&tl[i], dec)
+			price, ok := p.portfolioTokenPrice(&tl[i], d, pr)
+			if !ok {
+				it.IsPriced = false
+				it.Value = 0
+				break
+			}
+			it.Value += uniswapTokenValue(val.Amounts[i].ToInt(), d) * price
+		}
+		if it.IsPriced {
+			it.Price = it.Value / uniswapTokenValue(it.Amount.ToInt(), it.Decimals)
+		}
+		list = append(list, it)
+	}
+	return list, nil
+}
+
+// portfolioTokenSymbol provides the symbol of the given token, or its address if not available.
+func (p *proxy) portfolioTokenSymbol(token *common.Address) string {
+	tok, err := p.Erc20Token(token)
+	if err != nil || tok.Symbol == "" {
+		return token.String()
+	}
+	return tok.Symbol
+}
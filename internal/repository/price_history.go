@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/types"
+	"time"
+)
+
+// StorePriceSample pulls the current price of the native token in the given symbol
+// from the price sources and stores it in the price history.
+func (p *proxy) StorePriceSample(sym string) error {
+	// the cache is skipped on purpose; we need a fresh price for the sample
+	pri, err := p.requestPrice(sym)
+	if err != nil {
+		return err
+	}
+	return p.db.AddPriceSample(sym, time.Now(), pri.Price)
+}
+
+// PriceTicks provides a list of price samples, or daily prices, of the native token
+// in the given symbol for the given time period.
+func (p *proxy) PriceTicks(sym string, from *time.Time, to *time.Time, daily bool) ([]types.PriceTick, error) {
+	if !p.isValidPriceSymbol(sym) {
+		return nil, fmt.Errorf("unknown price symbol requested")
+	}
+	return p.db.PriceTicks(sym, from, to, daily)
+}
+
+// PriceAt provides the price of the native token in the given symbol at the given time.
+// Nil is returned if the price is not known.
+func (p *proxy) PriceAt(sym string, at time.Time) (*types.PriceTick, error) {
+	if !p.isValidPriceSymbol(sym) {
+		return nil, fmt.Errorf("unknown price symbol requested")
+	}
+	return p.db.PriceAt(sym, at)
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// uniswapPriceResolution represents the fixed point resolution of Uniswap cumulative prices (UQ112x112).
+var uniswapPriceResolution = new(big.Int).Lsh(big.NewInt(1), 112)
+
+// uniswapCumulativeModulo represents the modulo of the cumulative prices overflow.
+var uniswapCumulativeModulo = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// uniswapPriceProvider implements price provider calculating the time weighted
+// average price of the native token on an Uniswap pair. The other token of the pair
+// is expected to represent the target symbol. Observations of the pair cumulative price
+// are collected periodically by the price observer service, so the price is available
+// only after the window is covered by the observations.
+type uniswapPriceProvider struct {
+	pair   common.Address
+	token  common.Address
+	window int64
+	repo   *proxy
+	mu     sync.Mutex
+	obs    []uniswapPriceObservation
+}
+
+// uniswapPriceObservation represents a single observation of a pair cumulative price.
+type uniswapPriceObservation struct {
+	cumulative *big.Int
+	ts         int64
+}
+
+// fMintPriceProvider implements price provider pulling the price of a token
+// representing the native token from the fMint price oracle. The oracle does not expose
+// the time of its last update, so the price is dated by the first observation
+// of its current value; a price the oracle stopped updating gets old this way.
+type fMintPriceProvider struct {
+	token common.Address
+	repo  *proxy
+	mu    sync.Mutex
+	value float64
+	since int64
+}
+
+// newUniswapPriceProvider creates a new Uniswap pair TWAP price provider.
+func newUniswapPriceProvider(pair *common.Address, token *common.Address, window time.Duration, p *proxy) *uniswapPriceProvider {
+	return &uniswapPriceProvider{
+		pair:   *pair,
+		token:  *token,
+		window: int64(window.Seconds()),
+		repo:   p,
+		obs:    make([]uniswapPriceObservation, 0),
+	}
+}
+
+// newFMintPriceProvider creates a new fMint oracle price provider.
+func newFMintPriceProvider(token *common.Address, p *proxy) *fMintPriceProvider {
+	return &fMintPriceProvider{token: *token, repo: p}
+}
+
+// Name returns a human-readable name of the provider used for logging.
+func (up *uniswapPriceProvider) Name() string {
+	return fmt.Sprintf("uniswap twap %s", up.pair.String())
+}
+
+// Observe records the current cumulative price of the native token on the pair
+// and forgets observations no longer needed to cover the window.
+func (up *uniswapPriceProvider) Observe() error {
+	_, ix, err := up.side()
+	if err != nil {
+		return err
+	}
+
+	cum, now, err := up.cumulative(ix)
+	if err != nil {
+		return err
+	}
+
+	up.mu.Lock()
+	defer up.mu.Unlock()
+
+	// keep the most recent observation covering the window and the newer ones
+	k := 0
+	for i, o := range up.obs {
+		if now-o.ts >= up.window {
+			k = i
+		}
+	}
+	up.obs = append(up.obs[k:], uniswapPriceObservation{cumulative: cum, ts: now})
+	return nil
+}
+
+// Price calculates the time weighted average price of the native token on the pair.
+func (up *uniswapPriceProvider) Price(sym string) (*types.Price, error) {
+	tl, ix, err := up.side()
+	if err != nil {
+		return nil, err
+	}
+
+	cum, now, err := up.cumulative(ix)
+	if err != nil {
+		return nil, err
+	}
+
+	// find the most recent observation covering the window
+	up.mu.Lock()
+	start := uniswapPriceObservation{}
+	for _, o := range up.obs {
+		if now-o.ts >= up.window {
+			start = o
+		}
+	}
+	up.mu.Unlock()
+
+	if start.cumulative == nil {
+		return nil, fmt.Errorf("TWAP window of %s not covered yet", up.pair.String())
+	}
+
+	// the average price is the cumulative price growth over the elapsed time
+	diff := new(big.Int).Sub(cum, start.cumulative)
+	if diff.Sign() < 0 {
+		diff.Add(diff, uniswapCumulativeModulo)
+	}
+	avg := new(big.Float).Quo(new(big.Float).SetInt(diff), new(big.Float).SetInt(uniswapPriceResolution))
+	avg.Quo(avg, big.NewFloat(float64(now-start.ts)))
+
+	// adjust the raw price by the tokens decimals
+	dec := make(map[common.Address]int32)
+	val, _ := avg.Float64()
+	val *= math.Pow10(int(up.repo.uniswapRouteDecimals(&tl[ix], dec) - up.repo.uniswapRouteDecimals(&tl[1-ix], dec)))
+
+	return &types.Price{
+		FromSymbol: ownPriceSymbol,
+		ToSymbol:   sym,
+		Price:      val,
+		LastUpdate: hexutil.Uint64(uint64(now)),
+	}, nil
+}
+
+// side provides the pair tokens and the index of the native token on the pair.
+func (up *uniswapPriceProvider) side() ([]common.Address, int, error) {
+	tl, err := up.repo.UniswapTokens(&up.pair)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(tl) != 2 {
+		return nil, 0, fmt.Errorf("invalid pair %s", up.pair.String())
+	}
+
+	switch up.token {
+	case tl[0]:
+		return tl, 0, nil
+	case tl[1]:
+		return tl, 1, nil
+	}
+	return nil, 0, fmt.Errorf("token %s not found on pair %s", up.token.String(), up.pair.String())
+}
+
+// cumulative calculates the current cumulative price of the token on the given index
+// including the time elapsed since the last pair update.
+func (up *uniswapPriceProvider) cumulative(ix int) (*big.Int, int64, error) {
+	cp, err := up.repo.UniswapCumulativePrices(&up.pair)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rs, err := up.repo.rpc.UniswapReserves(&up.pair)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ts, err := up.repo.UniswapReservesTimeStamp(&up.pair)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now().UTC().Unix()
+	cum := new(big.Int).Set(cp[ix].ToInt())
+	if elapsed := now - int64(ts); elapsed > 0 && rs[ix].ToInt().Sign() > 0 {
+		spot := new(big.Int).Div(new(big.Int).Mul(rs[1-ix].ToInt(), uniswapPriceResolution), rs[ix].ToInt())
+		cum.Add(cum, spot.Mul(spot, big.NewInt(elapsed)))
+	}
+	return cum, now, nil
+}
+
+// Name returns a human-readable name of the provider used for logging.
+func (fp *fMintPriceProvider) Name() string {
+	return fmt.Sprintf("fmint oracle %s", fp.token.String())
+}
+
+// Observe checks the oracle price, so a change of the price is noticed even if nobody asks for it.
+func (fp *fMintPriceProvider) Observe() error {
+	_, _, err := fp.load()
+	return err
+}
+
+// Price pulls the price of the configured token from the fMint price oracle.
+func (fp *fMintPriceProvider) Price(sym string) (*types.Price, error) {
+	price, since, err := fp.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Price{
+		FromSymbol: ownPriceSymbol,
+		ToSymbol:   sym,
+		Price:      price,
+		LastUpdate: hexutil.Uint64(uint64(since)),
+	}, nil
+}
+
+// load pulls the current oracle price and the time the price was first observed.
+func (fp *fMintPriceProvider) load() (float64, int64, error) {
+	price, ok := fp.repo.defiTokenPriceValue(&fp.token)
+	if !ok {
+		return 0, 0, fmt.Errorf("oracle price of %s not available", fp.token.String())
+	}
+
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	if price != fp.value || fp.since == 0 {
+		fp.value = price
+		fp.since = time.Now().UTC().Unix()
+	}
+	return fp.value, fp.since, nil
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/types"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// price provider types available in the configuration
+const (
+	priceProviderCryptoCompare = "cryptocompare"
+	priceProviderRest          = "rest"
+	priceProviderUniswap       = "uniswap"
+	priceProviderFMint         = "fmint"
+)
+
+// PriceProvider represents a source of the native token price.
+type PriceProvider interface {
+	// Name returns a human-readable name of the provider used for logging.
+	Name() string
+
+	// Price pulls the current price of the native token in the given target symbol.
+	Price(sym string) (*types.Price, error)
+}
+
+// PriceObserver represents a price provider which needs to observe its source
+// periodically to be able to provide a valid price.
+type PriceObserver interface {
+	// Observe takes a new observation of the price source.
+	Observe() error
+}
+
+// priceSource represents a configured price provider with its aggregation parameters.
+type priceSource struct {
+	provider PriceProvider
+	symbol   string
+	weight   float64
+	priority int
+	maxAge   time.Duration
+}
+
+// priceQuote represents a valid price received from a price source.
+type priceQuote struct {
+	price  *types.Price
+	weight float64
+}
+
+// newPriceSources builds the list of price sources from the configuration.
+// If no source is configured, the CryptoCompare REST API is used for all the symbols.
+func newPriceSources(cfg *config.PriceOracle, p *proxy) []*priceSource {
+	list := make([]*priceSource, 0, len(cfg.Sources)+1)
+	for i := range cfg.Sources {
+		pp, err := newPriceProvider(&cfg.Sources[i], p)
+		if err != nil {
+			p.log.Errorf("price source #%d ignored; %s", i, err.Error())
+			continue
+		}
+
+		ps := priceSource{
+			provider: pp,
+			symbol:   cfg.Sources[i].Symbol,
+			weight:   cfg.Sources[i].Weight,
+			priority: cfg.Sources[i].Priority,
+			maxAge:   cfg.Sources[i].MaxAge,
+		}
+		if ps.weight <= 0 {
+			ps.weight = 1
+		}
+		if ps.maxAge <= 0 {
+			ps.maxAge = cfg.MaxAge
+		}
+		list = append(list, &ps)
+	}
+
+	// use the default source if nothing is configured
+	if len(list) == 0 {
+		list = append(list, &priceSource{
+			provider: newCryptoComparePriceProvider(priceApiAddress, p),
+			weight:   1,
+			maxAge:   cfg.MaxAge,
+		})
+	}
+
+	// lower priority values go first; the order of the configuration is kept otherwise
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].priority < list[j].priority
+	})
+	return list
+}
+
+// newPriceProvider creates a price provider for the given source configuration.
+func newPriceProvider(cfg *config.PriceSource, p *proxy) (PriceProvider, error) {
+	switch strings.ToLower(cfg.Type) {
+	case priceProviderCryptoCompare:
+		url := cfg.Url
+		if url == "" {
+			url = priceApiAddress
+		}
+		return newCryptoComparePriceProvider(url, p), nil
+	case priceProviderRest:
+		if cfg.Url == "" || cfg.Path == "" {
+			return nil, fmt.Errorf("REST price source requires url and path")
+		}
+		return newRestPriceProvider(cfg.Url, cfg.Path, cfg.TimePath, p), nil
+	case priceProviderUniswap:
+		if cfg.Window <= 0 {
+			return nil, fmt.Errorf("uniswap price source requires TWAP window")
+		}
+		return newUniswapPriceProvider(&cfg.Pair, &cfg.Token, cfg.Window, p), nil
+	case priceProviderFMint:
+		return newFMintPriceProvider(&cfg.Token, p), nil
+	}
+	return nil, fmt.Errorf("unknown price source type %s", cfg.Type)
+}
+
+// ObservePriceSources lets all the price providers observing their sources take a new observation.
+func (p *proxy) ObservePriceSources() {
+	for _, ps := range p.priceSources {
+		po, ok := ps.provider.(PriceObserver)
+		if !ok {
+			continue
+		}
+
+		if err := po.Observe(); err != nil {
+			p.log.Errorf("price source %s observation failed; %s", ps.provider.Name(), err.Error())
+		}
+	}
+}
+
+// appliesTo checks if the price source provides prices for the given symbol.
+func (ps *priceSource) appliesTo(sym string) bool {
+	return ps.symbol == "" || strings.EqualFold(ps.symbol, sym)
+}
+
+// aggregatePrice pulls the price for the given symbol from all the sources
+// of the highest priority able to provide a fresh price and aggregates them
+// by the weighted median. Sources of lower priorities are used as a fallback.
+func (p *proxy) aggregatePrice(sym string) (types.Price, error) {
+	var err error
+	for i := 0; i < len(p.priceSources); {
+		// collect sources of the same priority
+		j := i
+		for j < len(p.priceSources) && p.priceSources[j].priority == p.priceSources[i].priority {
+			j++
+		}
+
+		quotes := p.priceQuotes(p.priceSources[i:j], sym)
+		if len(quotes) > 0 {
+			return priceMedian(quotes), nil
+		}
+
+		err = fmt.Errorf("no price source of priority %d available", p.priceSources[i].priority)
+		i = j
+	}
+
+	if err == nil {
+		err = fmt.Errorf("no price source configured for %s", sym)
+	}
+	return types.Price{}, err
+}
+
+// priceQuotes pulls prices from the given sources in parallel
+// and returns the fresh ones.
+func (p *proxy) priceQuotes(sources []*priceSource, sym string) []priceQuote {
+	var wg sync.WaitGroup
+	res := make([]*priceQuote, len(sources))
+
+	for i, ps := range sources {
+		if !ps.appliesTo(sym) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, ps *priceSource) {
+			defer wg.Done()
+
+			pri, err := ps.provider.Price(sym)
+			if err != nil {
+				p.log.Errorf("price [%s] not available from %s; %s", sym, ps.provider.Name(), err.Error())
+				return
+			}
+
+			// check the quote staleness
+			age := time.Since(time.Unix(int64(pri.LastUpdate), 0))
+			if pri.Price <= 0 || age > ps.maxAge {
+				p.log.Warningf("price [%s] from %s rejected; price %f, age %s", sym, ps.provider.Name(), pri.Price, age.String())
+				return
+			}
+			res[i] = &priceQuote{price: pri, weight: ps.weight}
+		}(i, ps)
+	}
+	wg.Wait()
+
+	list := make([]priceQuote, 0, len(res))
+	for _, q := range res {
+		if q != nil {
+			list = append(list, *q)
+		}
+	}
+	return list
+}
+
+// priceMedian calculates the weighted median of the given price quotes.
+// The quote closest to the median provides the rest of the price details.
+func priceMedian(quotes []priceQuote) types.Price {
+	sort.Slice(quotes, func(i, j int) bool {
+		return quotes[i].price.Price < quotes[j].price.Price
+	})
+
+	var total float64
+	for _, q := range quotes {
+		total += q.weight
+	}
+
+	var sum float64
+	for i, q := range quotes {
+		sum += q.weight
+		if sum < total/2 {
+			continue
+		}
+
+		// exactly half of the weight on both sides; use the mean of the middle quotes
+		pri := *q.price
+		if sum == total/2 && i+1 < len(quotes) {
+			pri.Price = (q.price.Price + quotes[i+1].price.Price) / 2
+		}
+		return pri
+	}
+	return *quotes[len(quotes)-1].price
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"ncogearthchain-api-graphql/internal/logger"
+	"ncogearthchain-api-graphql/internal/types"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// cryptoComparePriceProvider implements price provider pulling prices
+// from the CryptoCompare REST API.
+type cryptoComparePriceProvider struct {
+	url string
+	log logger.Logger
+}
+
+// restPriceProvider implements price provider pulling prices from a generic
+// REST API responding with JSON. The price and the optional time stamp are picked
+// from the response by dot separated paths, array items are addressed by their index.
+type restPriceProvider struct {
+	url      string
+	path     string
+	timePath string
+	log      logger.Logger
+}
+
+// newCryptoComparePriceProvider creates a new CryptoCompare price provider.
+func newCryptoComparePriceProvider(url string, p *proxy) *cryptoComparePriceProvider {
+	return &cryptoComparePriceProvider{url: url, log: p.log}
+}
+
+// newRestPriceProvider creates a new generic REST price provider.
+func newRestPriceProvider(url string, path string, timePath string, p *proxy) *restPriceProvider {
+	return &restPriceProvider{url: url, path: path, timePath: timePath, log: p.log}
+}
+
+// Name returns a human-readable name of the provider used for logging.
+func (cc *cryptoComparePriceProvider) Name() string {
+	return "cryptocompare"
+}
+
+// Price pulls the current price of the native token in the given target symbol.
+func (cc *cryptoComparePriceProvider) Price(sym string) (pri *types.Price, err error) {
+	// the response is decoded blindly; make sure we don't crash on an unexpected structure
+	defer func() {
+		if r := recover(); r != nil {
+			pri, err = nil, fmt.Errorf("can not parse price API response; %s", r)
+		}
+	}()
+
+	body, err := priceHttpGet(cc.getPriceApiUrl(sym), cc.log)
+	if err != nil {
+		return nil, err
+	}
+
+	// we need to be able to read the data
+	var data map[string]map[string]map[string]map[string]interface{}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode price API response; %s", err.Error())
+	}
+
+	return &types.Price{
+		FromSymbol:    (data["RAW"][ownPriceSymbol][sym]["FROMSYMBOL"]).(string),
+		ToSymbol:      (data["RAW"][ownPriceSymbol][sym]["TOSYMBOL"]).(string),
+		Price:         (data["RAW"][ownPriceSymbol][sym]["PRICE"]).(float64),
+		Open24:        (data["RAW"][ownPriceSymbol][sym]["OPEN24HOUR"]).(float64),
+		High24:        (data["RAW"][ownPriceSymbol][sym]["HIGH24HOUR"]).(float64),
+		Low24:         (data["RAW"][ownPriceSymbol][sym]["LOW24HOUR"]).(float64),
+		Volume24:      (data["RAW"][ownPriceSymbol][sym]["VOLUME24HOUR"]).(float64),
+		Change24:      (data["RAW"][ownPriceSymbol][sym]["CHANGE24HOUR"]).(float64),
+		ChangePct24:   (data["RAW"][ownPriceSymbol][sym]["CHANGEPCT24HOUR"]).(float64),
+		TotalVolume24: (data["RAW"][ownPriceSymbol][sym]["TOTALVOLUME24H"]).(float64),
+		Supply:        (data["RAW"][ownPriceSymbol][sym]["SUPPLY"]).(float64),
+		MarketCap:     (data["RAW"][ownPriceSymbol][sym]["MKTCAP"]).(float64),
+		LastUpdate:    hexutil.Uint64(uint64((data["RAW"][ownPriceSymbol][sym]["LASTUPDATE"]).(float64))),
+	}, nil
+}
+
+// getPriceApiUrl builds REST API endpoint URL for the given target symbol.
+func (cc *cryptoComparePriceProvider) getPriceApiUrl(sym string) string {
+	// use the builder
+	var sb strings.Builder
+
+	sb.WriteString(cc.url)
+	sb.WriteString(priceApiSourceSymbolVar)
+	sb.WriteString(ownPriceSymbol)
+	sb.WriteString("&")
+	sb.WriteString(priceApiTargetSymbolVar)
+	sb.WriteString(sym)
+
+	return sb.String()
+}
+
+// Name returns a human-readable name of the provider used for logging.
+func (rp *restPriceProvider) Name() string {
+	return rp.url
+}
+
+// Price pulls the current price of the native token in the given target symbol.
+func (rp *restPriceProvider) Price(sym string) (*types.Price, error) {
+	body, err := priceHttpGet(priceSymbolsReplace(rp.url, sym), rp.log)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("can not decode price API response; %s", err.Error())
+	}
+
+	val, err := jsonPathFloat(data, priceSymbolsReplace(rp.path, sym))
+	if err != nil {
+		return nil, err
+	}
+
+	// the time stamp is optional; the price is considered current without it
+	ts := float64(time.Now().UTC().Unix())
+	if rp.timePath != "" {
+		if ts, err = jsonPathFloat(data, priceSymbolsReplace(rp.timePath, sym)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.Price{
+		FromSymbol: ownPriceSymbol,
+		ToSymbol:   sym,
+		Price:      val,
+		LastUpdate: hexutil.Uint64(uint64(ts)),
+	}, nil
+}
+
+// priceSymbolsReplace replaces symbol placeholders in the given REST source template.
+func priceSymbolsReplace(tpl string, sym string) string {
+	return strings.NewReplacer("{from}", ownPriceSymbol, "{to}", sym).Replace(tpl)
+}
+
+// jsonPathFloat picks a number from the decoded JSON data by the given dot separated path.
+// Numbers encoded as strings are accepted, too.
+func jsonPathFloat(data interface{}, path string) (float64, error) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := data.(type) {
+		case map[string]interface{}:
+			data = node[key]
+		case []interface{}:
+			ix, err := strconv.Atoi(key)
+			if err != nil || ix < 0 || ix >= len(node) {
+				return 0, fmt.Errorf("invalid index %s on path %s", key, path)
+			}
+			data = node[ix]
+		default:
+			return 0, fmt.Errorf("element %s not found on path %s", key, path)
+		}
+	}
+
+	switch val := data.(type) {
+	case float64:
+		return val, nil
+	case string:
+		return strconv.ParseFloat(val, 64)
+	}
+	return 0, fmt.Errorf("no number found on path %s", path)
+}
+
+// priceHttpGet executes a GET request to a remote price API and returns the response body.
+func priceHttpGet(url string, log logger.Logger) ([]byte, error) {
+	// prep the request
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("can not create HTTP request for price API; %s", err.Error())
+	}
+
+	// do the request
+	client := &http.Client{Timeout: time.Second * pricePullRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("can not query price API; %s", err.Error())
+	}
+
+	// don't forget to close
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Errorf("error closing price API request; %s", err.Error())
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("price API responded with status %d", resp.StatusCode)
+	}
+
+	// read the data
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("can not read price API response; %s", err.Error())
+	}
+	return body, nil
+}
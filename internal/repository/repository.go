@@ -73,6 +73,12 @@ type proxy struct {
 	// we need a Group to use single flight to control price pulls
 	apiRequestGroup singleflight.Group
 
+	// native token price sources
+	priceSources []*priceSource
+
+	// cached Uniswap routing graph
+	routeGraph uniswapRouteGraphCache
+
 	// governance contracts reference
 	govContracts map[string]*config.GovernanceContract
 
@@ -111,6 +117,9 @@ func newRepository() Repository {
 		solCompiler: cfg.Compiler.DefaultSolCompilerPath,
 	}
 
+	// prepare the price sources
+	p.priceSources = newPriceSources(&cfg.DeFi.Prices, &p)
+
 	// return the proxy
 	return &p
 }
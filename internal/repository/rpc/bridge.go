@@ -34,6 +34,9 @@ import (
 // rpcHeadProxyChannelCapacity represents the capacity of the new received blocks proxy channel.
 const rpcHeadProxyChannelCapacity = 10000
 
+// rpcPendingProxyChannelCapacity represents the capacity of the new pending transactions proxy channel.
+const rpcPendingProxyChannelCapacity = 10000
+
 // NecBridge represents Forest RPC abstraction layer.
 type NecBridge struct {
 	rpc *nec.Client
@@ -54,10 +57,14 @@ type NecBridge struct {
 	sfcAbi      *abi.ABI
 	sfcContract *contracts.SfcContract
 
+	// recent node pool content
+	txPool txPoolContentCache
+
 	// received blocks proxy
 	wg       *sync.WaitGroup
 	sigClose chan bool
 	headers  chan *etc.Header
+	pending  chan common.Hash
 }
 
 // New creates new Forest RPC connection bridge.
@@ -88,6 +95,7 @@ func New(cfg *config.Config, log logger.Logger) (*NecBridge, error) {
 		wg:       new(sync.WaitGroup),
 		sigClose: make(chan bool, 1),
 		headers:  make(chan *etc.Header, rpcHeadProxyChannelCapacity),
+		pending:  make(chan common.Hash, rpcPendingProxyChannelCapacity),
 	}
 
 	// inform about the local address of the API node
@@ -125,13 +133,14 @@ func connect(cfg *config.Config, log logger.Logger) (*nec.Client, *eth.Client, e
 
 // run starts the bridge threads required to collect blockchain data.
 func (nec *NecBridge) run() {
-	nec.wg.Add(1)
+	nec.wg.Add(2)
 	go nec.observeBlocks()
+	go nec.observePending()
 }
 
 // terminate kills the bridge threads to end the bridge gracefully.
 func (nec *NecBridge) terminate() {
-	nec.sigClose <- true
+	close(nec.sigClose)
 	nec.wg.Wait()
 	nec.log.Noticef("rpc threads terminated")
 }
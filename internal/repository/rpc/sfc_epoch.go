@@ -0,0 +1,103 @@
+/*
+Package rpc implements bridge to Forest full node API interface.
+
+We recommend using local IPC for fast and the most efficient inter-process communication between the API server
+and an Ncogearthchain/Forest node. Any remote RPC connection will work, but the performance may be significantly degraded
+by extra networking overhead of remote RPC calls.
+
+You should also consider security implications of opening Forest RPC interface for a remote access.
+If you considering it as your deployment strategy, you should establish encrypted channel between the API server
+and Forest RPC interface with connection limited to specified endpoints.
+
+We strongly discourage opening Forest RPC interface for unrestricted Internet access.
+*/
+package rpc
+
+import (
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// sfcRewardPerTokenUnit represents the decimal unit of the SFC accumulated reward per token.
+var sfcRewardPerTokenUnit = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// EpochValidatorRewards extracts the summary of rewards distributed to validators
+// in the given sealed epoch. The SFC keeps accumulated values, so the epoch values
+// are calculated as the difference against the previous epoch.
+func (nec *NecBridge) EpochValidatorRewards(id hexutil.Uint64) ([]types.EpochValidatorRewards, error) {
+	// the genesis epoch is never sealed, there are no rewards to extract
+	if id == 0 {
+		return []types.EpochValidatorRewards{}, nil
+	}
+
+	sfc := nec.SfcContract()
+	epoch := new(big.Int).SetUint64(uint64(id))
+	prev := new(big.Int).SetUint64(uint64(id) - 1)
+
+	ids, err := sfc.GetEpochValidatorIDs(nil, epoch)
+	if err != nil {
+		nec.log.Errorf("validators of epoch #%d not available; %s", id, err.Error())
+		return nil, err
+	}
+
+	list := make([]types.EpochValidatorRewards, 0, len(ids))
+	for _, vid := range ids {
+		stake, err := sfc.GetEpochReceivedStake(nil, epoch, vid)
+		if err != nil {
+			nec.log.Errorf("stake of validator #%d in epoch #%d not available; %s", vid.Uint64(), id, err.Error())
+			return nil, err
+		}
+
+		rpt, err := nec.epochAccumulatedDiff(sfc.GetEpochAccumulatedRewardPerToken, epoch, prev, vid)
+		if err != nil {
+			return nil, err
+		}
+
+		fee, err := nec.epochAccumulatedDiff(sfc.GetEpochAccumulatedOriginatedTxsFee, epoch, prev, vid)
+		if err != nil {
+			return nil, err
+		}
+
+		upt, err := nec.epochAccumulatedDiff(sfc.GetEpochAccumulatedUptime, epoch, prev, vid)
+		if err != nil {
+			return nil, err
+		}
+
+		rew := new(big.Int).Mul(stake, rpt)
+		list = append(list, types.EpochValidatorRewards{
+			ValidatorId:      hexutil.Big(*vid),
+			ReceivedStake:    hexutil.Big(*stake),
+			RewardPerToken:   hexutil.Big(*rpt),
+			DelegatorRewards: hexutil.Big(*rew.Div(rew, sfcRewardPerTokenUnit)),
+			OriginatedFee:    hexutil.Big(*fee),
+			Uptime:           hexutil.Uint64(upt.Uint64()),
+		})
+	}
+	return list, nil
+}
+
+// epochAccumulatedDiff calculates the difference of an SFC accumulated value
+// of the validator between the given epoch and the previous one.
+func (nec *NecBridge) epochAccumulatedDiff(acc func(*bind.CallOpts, *big.Int, *big.Int) (*big.Int, error), epoch *big.Int, prev *big.Int, vid *big.Int) (*big.Int, error) {
+	cur, err := acc(nil, epoch, vid)
+	if err != nil {
+		nec.log.Errorf("accumulated value of validator #%d in epoch #%d not available; %s", vid.Uint64(), epoch.Uint64(), err.Error())
+		return nil, err
+	}
+
+	// there is nothing to compare the first epoch against
+	if prev.Sign() <= 0 {
+		return cur, nil
+	}
+
+	last, err := acc(nil, prev, vid)
+	if err != nil {
+		nec.log.Errorf("accumulated value of validator #%d in epoch #%d not available; %s", vid.Uint64(), prev.Uint64(), err.Error())
+		return nil, err
+	}
+
+	return cur.Sub(cur, last), nil
+}
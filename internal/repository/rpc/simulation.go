@@ -0,0 +1,148 @@
+/*
+Package rpc implements bridge to Forest full node API interface.
+
+We recommend using local IPC for fast and the most efficient inter-process communication between the API server
+and an Ncogearthchain/Forest node. Any remote RPC connection will work, but the performance may be significantly degraded
+by extra networking overhead of remote RPC calls.
+
+You should also consider security implications of opening Forest RPC interface for a remote access.
+If you considering it as your deployment strategy, you should establish encrypted channel between the API server
+and Forest RPC interface with connection limited to specified endpoints.
+
+We strongly discourage opening Forest RPC interface for unrestricted Internet access.
+*/
+package rpc
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/types"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// simulationTimeout represents the max duration of a simulated call.
+const simulationTimeout = 10 * time.Second
+
+// simulationTracer is the JS tracer collecting the result of a simulated call together with
+// the emitted logs and the native tokens transfers. Logs and transfers of reverted inner calls
+// are discarded when the call frame exits.
+const simulationTracer = `{
+	frames: [{logs: [], transfers: []}],
+	hex: function(v) { return '0x' + v.toString(16); },
+	word: function(v) { var h = v.toString(16); while (h.length < 64) { h = '0' + h; } return '0x' + h; },
+	step: function(log, db) {
+		var op = log.op.toNumber();
+		if (op < 0xa0 || op > 0xa4) { return; }
+		var off = log.stack.peek(0).valueOf(), size = log.stack.peek(1).valueOf(), topics = [];
+		for (var i = 0; i < op - 0xa0; i++) { topics.push(this.word(log.stack.peek(2 + i))); }
+		this.frames[this.frames.length - 1].logs.push({
+			address: toHex(log.contract.getAddress()), topics: topics, data: toHex(log.memory.slice(off, off + size))
+		});
+	},
+	enter: function(frame) {
+		var f = {logs: [], transfers: []}, type = frame.getType(), value = frame.getValue();
+		if (value !== undefined && value.gt(0) && (type === 'CALL' || type === 'CREATE' || type === 'CREATE2' || type === 'SELFDESTRUCT')) {
+			f.transfers.push({from: toHex(frame.getFrom()), to: toHex(frame.getTo()), value: this.hex(value)});
+		}
+		this.frames.push(f);
+	},
+	exit: function(res) {
+		var f = this.frames.pop();
+		if (res.getError() !== undefined) { return; }
+		var p = this.frames[this.frames.length - 1];
+		p.logs = p.logs.concat(f.logs);
+		p.transfers = p.transfers.concat(f.transfers);
+	},
+	fault: function(log, db) {},
+	result: function(ctx, db) {
+		var root = this.frames[0], failed = ctx.error !== undefined, transfers = [];
+		if (!failed && ctx.value.gt(0)) {
+			transfers.push({from: toHex(ctx.from), to: toHex(ctx.to), value: this.hex(ctx.value)});
+		}
+		return {
+			failed: failed,
+			error: failed ? ctx.error : '',
+			output: ctx.output ? toHex(ctx.output) : '0x',
+			gasUsed: '0x' + ctx.gasUsed.toString(16),
+			logs: failed ? [] : root.logs,
+			transfers: failed ? [] : transfers.concat(root.transfers)
+		};
+	}
+}`
+
+// SimulateTransaction executes the given call against the state of the given block, the latest block
+// if not specified, with the state overrides applied. The call is traced to collect the emitted logs
+// and native tokens transfers. If the node does not provide tracing, the call is executed only.
+func (nec *NecBridge) SimulateTransaction(call *types.SimulationCall, block *hexutil.Uint64, overrides map[common.Address]types.StateOverride) (*types.TransactionSimulation, error) {
+	// keep track of the operation
+	nec.log.Debugf("simulating call from %s", call.From.String())
+
+	var blk interface{} = BlockTypeLatest
+	if block != nil {
+		blk = *block
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), simulationTimeout)
+	defer cancel()
+
+	var sim types.TransactionSimulation
+	err := nec.rpc.CallContext(ctx, &sim, "debug_traceCall", call, blk, map[string]interface{}{
+		"tracer":         simulationTracer,
+		"timeout":        simulationTimeout.String(),
+		"stateOverrides": overrides,
+	})
+	if err == nil {
+		sim.Traced = true
+		return &sim, nil
+	}
+
+	// tracing not available? execute the call only
+	if !isMethodNotAvailable(err) && !isTracerNotAvailable(err) {
+		nec.log.Errorf("can not simulate call; %s", err.Error())
+		return nil, err
+	}
+	return nec.simulateCall(ctx, call, blk, overrides)
+}
+
+// simulateCall executes the given call without tracing. Calls refused by the node are reported
+// in the simulation result as failed, with the revert data, if the node provides them.
+func (nec *NecBridge) simulateCall(ctx context.Context, call *types.SimulationCall, blk interface{}, overrides map[common.Address]types.StateOverride) (*types.TransactionSimulation, error) {
+	var out hexutil.Bytes
+	err := nec.rpc.CallContext(ctx, &out, "eth_call", call, blk, overrides)
+	if err == nil {
+		return &types.TransactionSimulation{ReturnData: out}, nil
+	}
+
+	// errors not coming from the node mean the call could not be made
+	if _, ok := err.(interface{ ErrorCode() int }); !ok {
+		nec.log.Errorf("can not simulate call; %s", err.Error())
+		return nil, err
+	}
+
+	// reverted call carries the revert data attached to the error
+	sim := types.TransactionSimulation{Failed: true, Error: err.Error()}
+	if de, ok := err.(interface{ ErrorData() interface{} }); ok {
+		if s, is := de.ErrorData().(string); is {
+			sim.ReturnData, _ = hexutil.Decode(s)
+		}
+	}
+	return &sim, nil
+}
+
+// isTracerNotAvailable checks if the RPC error signals the node can not run the simulation tracer,
+// e.g. if the JS tracers are not built into the node.
+func isTracerNotAvailable(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "tracer not found") || strings.Contains(msg, "tracer not available")
+}
+
+// isMethodNotAvailable checks if the RPC error signals the called method is not provided by the node.
+func isMethodNotAvailable(err error) bool {
+	if ec, ok := err.(interface{ ErrorCode() int }); ok && ec.ErrorCode() == -32601 {
+		return true
+	}
+	return strings.Contains(err.Error(), "does not exist") || strings.Contains(err.Error(), "not available")
+}
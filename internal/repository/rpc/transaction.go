@@ -14,8 +14,10 @@ We strongly discourage opening Forest RPC interface for unrestricted Internet ac
 package rpc
 
 import (
+	"context"
 	"ncogearthchain-api-graphql/internal/types"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	retypes "github.com/ethereum/go-ethereum/core/types"
@@ -83,3 +85,34 @@ func (nec *NecBridge) SendTransaction(tx hexutil.Bytes) (*common.Hash, error) {
 	nec.log.Debugf("transaction has been accepted with hash %s", hash.String())
 	return &hash, nil
 }
+
+// DryRunTransaction executes the given signed transaction of the given sender as a call
+// against the pending state of the block chain. The transaction is not broadcast.
+func (nec *NecBridge) DryRunTransaction(tx *retypes.Transaction, from *common.Address) error {
+	// keep track of the operation
+	nec.log.Debugf("dry running transaction %s", tx.Hash().String())
+
+	msg := ethereum.CallMsg{
+		From:       *from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+
+	// dynamic fee transactions can not have the legacy gas price set
+	if tx.Type() == retypes.DynamicFeeTxType {
+		msg.GasFeeCap = tx.GasFeeCap()
+		msg.GasTipCap = tx.GasTipCap()
+	} else {
+		msg.GasPrice = tx.GasPrice()
+	}
+
+	_, err := nec.eth.PendingCallContract(context.Background(), msg)
+	if err != nil {
+		nec.log.Debugf("transaction %s dry run failed; %s", tx.Hash().String(), err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+/*
+Package rpc implements bridge to Forest full node API interface.
+
+We recommend using local IPC for fast and the most efficient inter-process communication between the API server
+and an Ncogearthchain/Forest node. Any remote RPC connection will work, but the performance may be significantly degraded
+by extra networking overhead of remote RPC calls.
+
+You should also consider security implications of opening Forest RPC interface for remote access.
+If you considering it as your deployment strategy, you should establish encrypted channel between the API server
+and Forest RPC interface with connection limited to specified endpoints.
+
+We strongly discourage opening Forest RPC interface for unrestricted Internet access.
+*/
+package rpc
+
+import (
+	"context"
+	"ncogearthchain-api-graphql/internal/types"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// txPoolContentTTL represents the max age of the cached node pool content.
+const txPoolContentTTL = 2 * time.Second
+
+// txPoolContent represents the content of the node pool as provided by txpool_content,
+// keyed by the section, the sender address and the nonce.
+type txPoolContent map[string]map[string]map[string]*types.Transaction
+
+// txPoolContentCache represents the node pool content shared by the address lookups.
+// The content is read only once loaded, so it's safe to be used concurrently.
+type txPoolContentCache struct {
+	sync.Mutex
+	content txPoolContent
+	loaded  time.Time
+}
+
+// observePending collects hashes of new transactions entering the node pool
+// and posts them into the proxy channel for processing.
+func (nec *NecBridge) observePending() {
+	var sub ethereum.Subscription
+	defer func() {
+		if sub != nil {
+			sub.Unsubscribe()
+		}
+		nec.log.Noticef("pending transactions observer done")
+		nec.wg.Done()
+	}()
+
+	sub = nec.pendingSubscription()
+	for {
+		// re-subscribe if the subscription ref is not valid
+		if sub == nil {
+			tm := time.NewTimer(necHeadsObserverSubscribeTick)
+			select {
+			case <-nec.sigClose:
+				return
+			case <-tm.C:
+				sub = nec.pendingSubscription()
+				continue
+			}
+		}
+
+		// use the subscriptions
+		select {
+		case <-nec.sigClose:
+			return
+		case err := <-sub.Err():
+			nec.log.Errorf("pending transactions subscription failed; %s", err.Error())
+			sub = nil
+		}
+	}
+}
+
+// pendingSubscription provides a subscription for new pending transactions
+// received by the connected blockchain node.
+func (nec *NecBridge) pendingSubscription() ethereum.Subscription {
+	sub, err := nec.rpc.EthSubscribe(context.Background(), nec.pending, "newPendingTransactions")
+	if err != nil {
+		nec.log.Criticalf("can not observe pending transactions; %s", err.Error())
+		return nil
+	}
+	return sub
+}
+
+// ObservedPendingProxy provides a channel fed with hashes of new transactions
+// entering the pool of the connected blockchain node.
+func (nec *NecBridge) ObservedPendingProxy() chan common.Hash {
+	return nec.pending
+}
+
+// TxPoolStatus provides the number of transactions in the pool of the connected node.
+func (nec *NecBridge) TxPoolStatus() (*types.TxPoolStatus, error) {
+	var st types.TxPoolStatus
+	if err := nec.rpc.Call(&st, "txpool_status"); err != nil {
+		nec.log.Errorf("txpool status not available; %s", err.Error())
+		return nil, err
+	}
+	return &st, nil
+}
+
+// TxPoolTransactions provides the list of transactions waiting in the pool
+// of the connected node sent from, or to the given address.
+func (nec *NecBridge) TxPoolTransactions(addr *common.Address) ([]*types.Transaction, error) {
+	content, err := nec.txPoolContent()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*types.Transaction, 0)
+	for _, section := range []string{"pending", "queued"} {
+		for sender, txs := range content[section] {
+			isSender := common.HexToAddress(sender) == *addr
+			for _, trx := range txs {
+				if trx != nil && (isSender || (trx.To != nil && *trx.To == *addr)) {
+					list = append(list, trx)
+				}
+			}
+		}
+	}
+	return list, nil
+}
+
+// TxPoolTransactionsFrom provides the list of transactions sent from the given address
+// waiting in the pool of the connected node. The pool is read directly from the node,
+// so transactions sent a moment ago are included.
+func (nec *NecBridge) TxPoolTransactionsFrom(addr *common.Address) ([]*types.Transaction, error) {
+	content, err := nec.loadTxPoolContent()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*types.Transaction, 0)
+	for _, section := range []string{"pending", "queued"} {
+		for sender, txs := range content[section] {
+			if common.HexToAddress(sender) != *addr {
+				continue
+			}
+			for _, trx := range txs {
+				if trx != nil {
+					list = append(list, trx)
+				}
+			}
+		}
+	}
+	return list, nil
+}
+
+// txPoolContent provides the content of the node pool. The full pool dump is expensive,
+// so it's cached shortly and concurrent requests share a single node call.
+func (nec *NecBridge) txPoolContent() (txPoolContent, error) {
+	nec.txPool.Lock()
+	if nec.txPool.content != nil && time.Since(nec.txPool.loaded) < txPoolContentTTL {
+		defer nec.txPool.Unlock()
+		return nec.txPool.content, nil
+	}
+	nec.txPool.Unlock()
+
+	c, err, _ := nec.cg.Do("txpool-content", func() (interface{}, error) {
+		return nec.loadTxPoolContent()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.(txPoolContent), nil
+}
+
+// loadTxPoolContent loads the content of the node pool from the node and refreshes the cached content.
+func (nec *NecBridge) loadTxPoolContent() (txPoolContent, error) {
+	var content txPoolContent
+	if err := nec.rpc.Call(&content, "txpool_content"); err != nil {
+		nec.log.Errorf("txpool content not available; %s", err.Error())
+		return nil, err
+	}
+
+	nec.txPool.Lock()
+	nec.txPool.content, nec.txPool.loaded = content, time.Now()
+	nec.txPool.Unlock()
+	return content, nil
+}
@@ -14,19 +14,30 @@ We strongly discourage opening Forest RPC interface for unrestricted Internet ac
 package rpc
 
 import (
+	"context"
 	"math/big"
 	"ncogearthchain-api-graphql/internal/repository/rpc/contracts"
 	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	etc "github.com/ethereum/go-ethereum/core/types"
 )
 
 //go:generate tools/abigen.sh --abi ./contracts/abi/uniswap-factory.abi --pkg contracts --type UniswapFactory --out ./contracts/uniswap_factory.go
 //go:generate tools/abigen.sh --abi ./contracts/abi/uniswap-pair.abi --pkg contracts --type UniswapPair --out ./contracts/uniswap_pair.go
 //go:generate tools/abigen.sh --abi ./contracts/abi/uniswap-router.abi --pkg contracts --type UniswapRouter --out ./contracts/uniswap_router.go
 
+// uniswapLogsRequestTimeout represents the max duration of a single event logs request.
+const uniswapLogsRequestTimeout = 30 * time.Second
+
+// uniswapPairCreatedTopic represents the topic of the Uniswap factory PairCreated event.
+// UniswapFactory::PairCreated(address indexed token0, address indexed token1, address pair, uint256)
+var uniswapPairCreatedTopic = common.HexToHash("0x0d3648bd0f6ba80134a33ba9275ac585d9d315f0ad8355cddefde31afa28d0e9")
+
 // NativeTokenAddress returns an address of native token.
 func (nec *NecBridge) NativeTokenAddress() (*common.Address, error) {
 	// get the router contract if possible
@@ -121,30 +132,6 @@ func (nec *NecBridge) isUniswapPairWhitelisted(pair *common.Address) bool {
 	return false
 }
 
-// UniswapQuoteInput calculates optimal input on sibling token based on input amount and
-// self reserves of the analyzed token.
-func (nec *NecBridge) UniswapQuoteInput(
-	amountA hexutil.Big,
-	reserveA hexutil.Big,
-	reserveB hexutil.Big,
-) (hexutil.Big, error) {
-	// get the router contract if possible
-	contract, err := contracts.NewUniswapRouter(nec.uniswapConfig.Router, nec.eth)
-	if err != nil {
-		nec.log.Errorf("Uniswap router contract not found; %s", err.Error())
-		return hexutil.Big{}, err
-	}
-
-	// get the quote amount
-	amount, err := contract.Quote(nil, amountA.ToInt(), reserveA.ToInt(), reserveB.ToInt())
-	if err != nil {
-		nec.log.Errorf("can not calculate Uniswap quote; %s", err.Error())
-		return hexutil.Big{}, err
-	}
-
-	return hexutil.Big(*amount), nil
-}
-
 // UniswapAmountsOut resolves a list of output amounts for the given
 // input amount and a list of tokens to be used to make the swap operation.
 func (nec *NecBridge) UniswapAmountsOut(amountIn hexutil.Big, tokens []common.Address) ([]hexutil.Big, error) {
@@ -344,3 +331,43 @@ func (nec *NecBridge) UniswapFactoryContract() (*contracts.UniswapFactory, error
 	}
 	return contract, nil
 }
+
+// IsUniswapPairFeatured checks if the given Uniswap pair is featured,
+// e.g. it's included in the configured pairs white list.
+func (nec *NecBridge) IsUniswapPairFeatured(pair *common.Address) bool {
+	return nec.isUniswapPairWhitelisted(pair)
+}
+
+// UniswapPairCreatedLogs loads PairCreated event logs emitted by the Uniswap factory
+// inside the given range of blocks.
+func (nec *NecBridge) UniswapPairCreatedLogs(from uint64, to uint64) ([]etc.Log, error) {
+	return nec.filterLogs(&ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{nec.uniswapConfig.Core},
+		Topics:    [][]common.Hash{{uniswapPairCreatedTopic}},
+	})
+}
+
+// UniswapPairLogs loads all the event logs emitted by the given Uniswap pair
+// inside the given range of blocks.
+func (nec *NecBridge) UniswapPairLogs(pair *common.Address, from uint64, to uint64) ([]etc.Log, error) {
+	return nec.filterLogs(&ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{*pair},
+	})
+}
+
+// filterLogs loads event logs matching the given filter query.
+func (nec *NecBridge) filterLogs(q *ethereum.FilterQuery) ([]etc.Log, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), uniswapLogsRequestTimeout)
+	defer cancel()
+
+	logs, err := nec.eth.FilterLogs(ctx, *q)
+	if err != nil {
+		nec.log.Errorf("can not load event logs from #%d to #%d; %s", q.FromBlock.Uint64(), q.ToBlock.Uint64(), err.Error())
+		return nil, err
+	}
+	return logs, nil
+}
@@ -14,6 +14,7 @@ We strongly discourage opening Forest RPC interface for unrestricted Internet ac
 package rpc
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"strings"
@@ -59,6 +60,18 @@ func (nec *NecBridge) GasPrice() (hexutil.Big, error) {
 	return price, nil
 }
 
+// ChainID returns the chain identifier of the connected block chain used to sign transactions.
+func (nec *NecBridge) ChainID() (*big.Int, error) {
+	id, err, _ := nec.cg.Do("chain-id", func() (interface{}, error) {
+		return nec.eth.ChainID(context.Background())
+	})
+	if err != nil {
+		nec.log.Errorf("chain id not available; %s", err.Error())
+		return nil, err
+	}
+	return id.(*big.Int), nil
+}
+
 // GasEstimate calculates the estimated amount of Gas required to perform
 // transaction described by the input params.
 func (nec *NecBridge) GasEstimate(trx *struct {
@@ -10,6 +10,7 @@ package repository
 
 import (
 	"bytes"
+	"fmt"
 	"math/big"
 	"ncogearthchain-api-graphql/internal/types"
 
@@ -95,6 +96,38 @@ func (p *proxy) Epoch(id *hexutil.Uint64) (*types.Epoch, error) {
 	return ep, nil
 }
 
+// EpochValidatorRewards returns the summary of rewards distributed to validators in the given sealed epoch.
+// Rewards of a sealed epoch never change, so they are cached once extracted.
+func (p *proxy) EpochValidatorRewards(id hexutil.Uint64) ([]types.EpochValidatorRewards, error) {
+	// try the cache first
+	if list := p.cache.PullEpochValidatorRewards(&id); list != nil {
+		return list, nil
+	}
+
+	val, err, _ := p.apiRequestGroup.Do("epoch-rewards-"+id.String(), func() (interface{}, error) {
+		sealed, err := p.rpc.CurrentSealedEpoch()
+		if err != nil {
+			return nil, err
+		}
+		if id > sealed {
+			return nil, fmt.Errorf("epoch #%d not sealed yet", id)
+		}
+
+		list, err := p.rpc.EpochValidatorRewards(id)
+		if err != nil {
+			return nil, err
+		}
+
+		// cache for future use
+		p.cache.PushEpochValidatorRewards(&id, list)
+		return list, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.([]types.EpochValidatorRewards), nil
+}
+
 // CurrentSealedEpoch returns the data of the latest sealed epoch.
 // This is used for reward estimation calculation and we don't need
 // real time data, but rather faster response time.
@@ -0,0 +1,310 @@
+/*
+Package repository implements repository for handling fast and efficient access to data required
+by the resolvers of the API server.
+
+Internally it utilizes RPC to access Ncogearthchain/Forest full node for blockchain interaction. Mongo database
+for fast, robust and scalable off-chain data storage, especially for aggregated and pre-calculated data mining
+results. BigCache for in-memory object storage to speed up loading of frequently accessed entities.
+*/
+package repository
+
+import (
+	"fmt"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/repository/rpc"
+	"ncogearthchain-api-graphql/internal/repository/rpc/contracts"
+	"ncogearthchain-api-graphql/internal/types"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	// simTopicTransfer represents the topic of the ERC20 and ERC721 Transfer event.
+	simTopicTransfer = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+	// simTopicTransferSingle represents the topic of the ERC1155 TransferSingle event.
+	simTopicTransferSingle = common.HexToHash("0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62")
+
+	// simTopicTransferBatch represents the topic of the ERC1155 TransferBatch event.
+	simTopicTransferBatch = common.HexToHash("0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb")
+)
+
+// simTokenAbi holds the parsed ABIs of the standard tokens used to decode
+// calls and logs of contracts without a validated ABI.
+var simTokenAbi struct {
+	once sync.Once
+	list []*abi.ABI
+}
+
+// SimulateTransaction executes the given call against the state of the given block, the latest block
+// if not specified, with the state overrides applied. The return data and the logs are decoded
+// by the ABI of the validated contracts, or the standard token ABIs, and the net balance changes
+// of the native and the standard tokens are calculated. The gas fee is not included.
+func (p *proxy) SimulateTransaction(call *types.SimulationCall, block *hexutil.Uint64, overrides map[common.Address]types.StateOverride) (*types.TransactionSimulation, error) {
+	sim, err := p.rpc.SimulateTransaction(call, block, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	// the untraced call still transfers the value, if it succeeded
+	if !sim.Traced && !sim.Failed && call.To != nil && call.Value != nil && call.Value.ToInt().Sign() > 0 {
+		sim.Transfers = []types.NativeTransfer{{From: call.From, To: *call.To, Value: *call.Value}}
+	}
+
+	abis := make(map[common.Address][]*abi.ABI)
+	if sim.Failed {
+		if reason, err := abi.UnpackRevert(sim.ReturnData); err == nil {
+			sim.RevertReason = reason
+		}
+	} else if call.To != nil && len(call.Data) >= 4 {
+		p.decodeSimulationReturn(sim, call, p.simulationAbi(abis, call.To))
+	}
+
+	for i := range sim.Logs {
+		p.decodeSimulationLog(&sim.Logs[i], p.simulationAbi(abis, &sim.Logs[i].Address))
+	}
+
+	sim.BalanceChanges = simulationBalanceChanges(sim)
+	return sim, nil
+}
+
+// simulationAbi provides the list of ABIs used to decode calls and logs of the given contract;
+// the validated ABI of the contract goes first, the standard token ABIs follow.
+func (p *proxy) simulationAbi(known map[common.Address][]*abi.ABI, addr *common.Address) []*abi.ABI {
+	if list, ok := known[*addr]; ok {
+		return list
+	}
+
+	simTokenAbi.once.Do(func() {
+		for _, def := range []string{contracts.ERCTwentyABI, contracts.ERC721ABI, contracts.ERC1155ABI} {
+			ab, err := abi.JSON(strings.NewReader(def))
+			if err != nil {
+				p.log.Criticalf("can not parse token ABI; %s", err.Error())
+				continue
+			}
+			simTokenAbi.list = append(simTokenAbi.list, &ab)
+		}
+	})
+
+	list := make([]*abi.ABI, 0, len(simTokenAbi.list)+1)
+	if sc, err := p.Contract(addr); err == nil && sc != nil && sc.Abi != "" {
+		if ab, err := abi.JSON(strings.NewReader(sc.Abi)); err == nil {
+			list = append(list, &ab)
+		}
+	}
+
+	list = append(list, simTokenAbi.list...)
+	known[*addr] = list
+	return list
+}
+
+// decodeSimulationReturn decodes the data returned by the simulated call using the ABI of the called function.
+func (p *proxy) decodeSimulationReturn(sim *types.TransactionSimulation, call *types.SimulationCall, abis []*abi.ABI) {
+	for _, ab := range abis {
+		method, err := ab.MethodById(call.Data[:4])
+		if err != nil {
+			continue
+		}
+
+		values, err := method.Outputs.Unpack(sim.ReturnData)
+		if err != nil {
+			p.log.Debugf("can not decode %s return data; %s", method.Name, err.Error())
+			continue
+		}
+
+		sim.Method = method.Name
+		sim.ReturnValues = decodedParams(method.Outputs, values)
+		return
+	}
+}
+
+// decodeSimulationLog decodes the given log by the ABI event of the log topic.
+// The event must match the number of the indexed arguments, so the ERC20 and the ERC721
+// Transfer events of the same topic are told apart.
+func (p *proxy) decodeSimulationLog(lg *types.SimulationLog, abis []*abi.ABI) {
+	if len(lg.Topics) == 0 {
+		return
+	}
+
+	for _, ab := range abis {
+		ev, err := ab.EventByID(lg.Topics[0])
+		if err != nil || len(lg.Topics)-1 != len(ev.Inputs)-len(ev.Inputs.NonIndexed()) {
+			continue
+		}
+
+		values := make(map[string]interface{})
+		if err := ev.Inputs.UnpackIntoMap(values, lg.Data); err != nil {
+			continue
+		}
+
+		indexed := make(abi.Arguments, 0, len(lg.Topics)-1)
+		for _, in := range ev.Inputs {
+			if in.Indexed {
+				indexed = append(indexed, in)
+			}
+		}
+		if err := abi.ParseTopicsIntoMap(values, indexed, lg.Topics[1:]); err != nil {
+			continue
+		}
+
+		lg.Event = ev.Name
+		lg.Params = make([]types.DecodedParam, len(ev.Inputs))
+		for i, in := range ev.Inputs {
+			lg.Params[i] = types.DecodedParam{Name: in.Name, Type: in.Type.String(), Value: formatAbiValue(values[in.Name])}
+		}
+		return
+	}
+}
+
+// decodedParams pairs the decoded values with their ABI arguments.
+func decodedParams(args abi.Arguments, values []interface{}) []types.DecodedParam {
+	list := make([]types.DecodedParam, 0, len(values))
+	for i, v := range values {
+		if i >= len(args) {
+			break
+		}
+		list = append(list, types.DecodedParam{Name: args[i].Name, Type: args[i].Type.String(), Value: formatAbiValue(v)})
+	}
+	return list
+}
+
+// formatAbiValue provides the text representation of a value decoded by ABI.
+// Numbers are decimal, addresses and bytes are hex encoded, arrays are listed in brackets.
+func formatAbiValue(v interface{}) string {
+	switch val := v.(type) {
+	case *big.Int:
+		return val.String()
+	case common.Address:
+		return val.String()
+	case common.Hash:
+		return val.String()
+	case []byte:
+		return hexutil.Encode(val)
+	case string:
+		return val
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice:
+		// fixed size bytes
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+
+		items := make([]string, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			items[i] = formatAbiValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
+// simulationBalanceChanges calculates the net balance changes of the accounts involved
+// in the native tokens transfers and the standard token transfer events of the simulated call.
+// The zero address of mints and burns is not included.
+func simulationBalanceChanges(sim *types.TransactionSimulation) []types.BalanceChange {
+	bc := balanceChanges{index: make(map[string]int)}
+
+	for _, tr := range sim.Transfers {
+		bc.move(types.AssetNative, nil, nil, tr.From, tr.To, tr.Value.ToInt())
+	}
+
+	for i := range sim.Logs {
+		lg := &sim.Logs[i]
+		if len(lg.Topics) != 4 && len(lg.Topics) != 3 {
+			continue
+		}
+
+		switch {
+		case lg.Topics[0] == simTopicTransfer && len(lg.Topics) == 3 && len(lg.Data) == 32:
+			from, to := common.BytesToAddress(lg.Topics[1].Bytes()), common.BytesToAddress(lg.Topics[2].Bytes())
+			bc.move(types.AssetERC20, &lg.Address, nil, from, to, new(big.Int).SetBytes(lg.Data))
+
+		case lg.Topics[0] == simTopicTransfer && len(lg.Topics) == 4 && len(lg.Data) == 0:
+			from, to := common.BytesToAddress(lg.Topics[1].Bytes()), common.BytesToAddress(lg.Topics[2].Bytes())
+			bc.move(types.AssetERC721, &lg.Address, new(big.Int).SetBytes(lg.Topics[3].Bytes()), from, to, big.NewInt(1))
+
+		case lg.Topics[0] == simTopicTransferSingle && len(lg.Topics) == 4 && len(lg.Data) == 64:
+			from, to := common.BytesToAddress(lg.Topics[2].Bytes()), common.BytesToAddress(lg.Topics[3].Bytes())
+			bc.move(types.AssetERC1155, &lg.Address, new(big.Int).SetBytes(lg.Data[:32]), from, to, new(big.Int).SetBytes(lg.Data[32:64]))
+
+		case lg.Topics[0] == simTopicTransferBatch && len(lg.Topics) == 4:
+			ids, values, err := rpc.Erc1155ParseTransferBatchData(lg.Data)
+			if err != nil || len(ids) != len(values) {
+				continue
+			}
+			from, to := common.BytesToAddress(lg.Topics[2].Bytes()), common.BytesToAddress(lg.Topics[3].Bytes())
+			for j := range ids {
+				bc.move(types.AssetERC1155, &lg.Address, ids[j], from, to, values[j])
+			}
+		}
+	}
+	return bc.result()
+}
+
+// balanceChanges aggregates balance changes per account and asset in the order of appearance.
+type balanceChanges struct {
+	list  []types.BalanceChange
+	index map[string]int
+}
+
+// move records the given amount of the asset leaving the sender and reaching the recipient.
+func (bc *balanceChanges) move(asset string, token *common.Address, id *big.Int, from common.Address, to common.Address, amount *big.Int) {
+	if amount.Sign() == 0 {
+		return
+	}
+	bc.add(asset, token, id, from, new(big.Int).Neg(amount))
+	bc.add(asset, token, id, to, amount)
+}
+
+// add adds the signed amount to the balance change of the account and asset.
+func (bc *balanceChanges) add(asset string, token *common.Address, id *big.Int, addr common.Address, amount *big.Int) {
+	if addr == (common.Address{}) {
+		return
+	}
+
+	key := addr.String() + "/" + asset
+	if token != nil {
+		key += "/" + token.String()
+	}
+	if id != nil {
+		key += "/" + id.String()
+	}
+
+	if i, ok := bc.index[key]; ok {
+		bc.list[i].Amount = hexutil.Big(*new(big.Int).Add(bc.list[i].Amount.ToInt(), amount))
+		return
+	}
+
+	ch := types.BalanceChange{Address: addr, Asset: asset, Amount: hexutil.Big(*new(big.Int).Set(amount))}
+	if token != nil {
+		t := *token
+		ch.Token = &t
+	}
+	if id != nil {
+		ch.TokenId = (*hexutil.Big)(new(big.Int).Set(id))
+	}
+
+	bc.index[key] = len(bc.list)
+	bc.list = append(bc.list, ch)
+}
+
+// result provides the list of the non-zero balance changes.
+func (bc *balanceChanges) result() []types.BalanceChange {
+	list := make([]types.BalanceChange, 0, len(bc.list))
+	for _, ch := range bc.list {
+		if ch.Amount.ToInt().Sign() != 0 {
+			list = append(list, ch)
+		}
+	}
+	return list
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"math/big"
+	"testing"
+
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/onsi/gomega"
+)
+
+var (
+	testSimAlice = common.HexToAddress("0xa1")
+	testSimBob   = common.HexToAddress("0xb0")
+	testSimToken = common.HexToAddress("0x70")
+)
+
+// testSimWord encodes the value as a 32 bytes word.
+func testSimWord(v int64) []byte {
+	return common.BigToHash(big.NewInt(v)).Bytes()
+}
+
+// testSimTopic encodes the address as an indexed event topic.
+func testSimTopic(adr common.Address) common.Hash {
+	return common.BytesToHash(adr.Bytes())
+}
+
+// testSimChange finds the balance change of the account and asset.
+func testSimChange(list []types.BalanceChange, adr common.Address, asset string) *types.BalanceChange {
+	for i := range list {
+		if list[i].Address == adr && list[i].Asset == asset {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func TestSimulationBalanceChangesNative(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// transfers are netted per account in the order of appearance
+	list := simulationBalanceChanges(&types.TransactionSimulation{Transfers: []types.NativeTransfer{
+		{From: testSimAlice, To: testSimBob, Value: hexutil.Big(*big.NewInt(100))},
+		{From: testSimBob, To: testSimAlice, Value: hexutil.Big(*big.NewInt(30))},
+		{From: testSimAlice, To: testSimBob, Value: hexutil.Big(*big.NewInt(0))},
+	}})
+
+	g.Expect(list).To(gomega.HaveLen(2))
+	g.Expect(list[0].Address).To(gomega.Equal(testSimAlice))
+	g.Expect(list[0].Asset).To(gomega.Equal(types.AssetNative))
+	g.Expect(list[0].Token).To(gomega.BeNil())
+	g.Expect(list[0].Amount.ToInt()).To(gomega.Equal(big.NewInt(-70)))
+	g.Expect(list[1].Address).To(gomega.Equal(testSimBob))
+	g.Expect(list[1].Amount.ToInt()).To(gomega.Equal(big.NewInt(70)))
+}
+
+func TestSimulationBalanceChangesTokens(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	single := append(testSimWord(7), testSimWord(5)...)
+	list := simulationBalanceChanges(&types.TransactionSimulation{Logs: []types.SimulationLog{
+		// ERC20 transfer
+		{Address: testSimToken, Topics: []common.Hash{simTopicTransfer, testSimTopic(testSimAlice), testSimTopic(testSimBob)}, Data: testSimWord(250)},
+		// ERC721 transfer of token #9
+		{Address: testSimToken, Topics: []common.Hash{simTopicTransfer, testSimTopic(testSimBob), testSimTopic(testSimAlice), common.BigToHash(big.NewInt(9))}},
+		// ERC1155 transfer of 5 tokens #7 by an operator
+		{Address: testSimToken, Topics: []common.Hash{simTopicTransferSingle, testSimTopic(testSimBob), testSimTopic(testSimAlice), testSimTopic(testSimBob)}, Data: single},
+	}})
+
+	erc20 := testSimChange(list, testSimAlice, types.AssetERC20)
+	g.Expect(erc20).ToNot(gomega.BeNil())
+	g.Expect(*erc20.Token).To(gomega.Equal(testSimToken))
+	g.Expect(erc20.TokenId).To(gomega.BeNil())
+	g.Expect(erc20.Amount.ToInt()).To(gomega.Equal(big.NewInt(-250)))
+	g.Expect(testSimChange(list, testSimBob, types.AssetERC20).Amount.ToInt()).To(gomega.Equal(big.NewInt(250)))
+
+	erc721 := testSimChange(list, testSimAlice, types.AssetERC721)
+	g.Expect(erc721).ToNot(gomega.BeNil())
+	g.Expect(erc721.TokenId.ToInt()).To(gomega.Equal(big.NewInt(9)))
+	g.Expect(erc721.Amount.ToInt()).To(gomega.Equal(big.NewInt(1)))
+	g.Expect(testSimChange(list, testSimBob, types.AssetERC721).Amount.ToInt()).To(gomega.Equal(big.NewInt(-1)))
+
+	erc1155 := testSimChange(list, testSimBob, types.AssetERC1155)
+	g.Expect(erc1155).ToNot(gomega.BeNil())
+	g.Expect(erc1155.TokenId.ToInt()).To(gomega.Equal(big.NewInt(7)))
+	g.Expect(erc1155.Amount.ToInt()).To(gomega.Equal(big.NewInt(5)))
+	g.Expect(testSimChange(list, testSimAlice, types.AssetERC1155).Amount.ToInt()).To(gomega.Equal(big.NewInt(-5)))
+}
+
+func TestSimulationBalanceChangesSkipped(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	list := simulationBalanceChanges(&types.TransactionSimulation{Logs: []types.SimulationLog{
+		// mint; the zero address is not included
+		{Address: testSimToken, Topics: []common.Hash{simTopicTransfer, testSimTopic(common.Address{}), testSimTopic(testSimBob)}, Data: testSimWord(10)},
+		// unknown event
+		{Address: testSimToken, Topics: []common.Hash{common.HexToHash("0x01"), testSimTopic(testSimAlice), testSimTopic(testSimBob)}, Data: testSimWord(10)},
+		// malformed transfer data
+		{Address: testSimToken, Topics: []common.Hash{simTopicTransfer, testSimTopic(testSimAlice), testSimTopic(testSimBob)}, Data: testSimWord(10)[:16]},
+		// no topics at all
+		{Address: testSimToken, Data: testSimWord(10)},
+	}})
+
+	g.Expect(list).To(gomega.HaveLen(1))
+	g.Expect(list[0].Address).To(gomega.Equal(testSimBob))
+	g.Expect(list[0].Amount.ToInt()).To(gomega.Equal(big.NewInt(10)))
+}
+
+func TestSimulationBalanceChangesNetZero(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// a round trip nets out, accounts without a change are left out
+	list := simulationBalanceChanges(&types.TransactionSimulation{Transfers: []types.NativeTransfer{
+		{From: testSimAlice, To: testSimBob, Value: hexutil.Big(*big.NewInt(5))},
+		{From: testSimBob, To: testSimAlice, Value: hexutil.Big(*big.NewInt(5))},
+	}})
+	g.Expect(list).To(gomega.BeEmpty())
+}
@@ -145,3 +145,9 @@ func (p *proxy) Transactions(cursor *string, count int32) (*types.TransactionLis
 func (p *proxy) StoreGasPricePeriod(gp *types.GasPricePeriod) error {
 	return p.db.AddGasPricePeriod(gp)
 }
+
+// TransactionsFrom returns up to the given number of transactions stored in the database
+// with the ordinal index at or above the given one, sorted from older to newer.
+func (p *proxy) TransactionsFrom(orx uint64, count int64) ([]*types.Transaction, error) {
+	return p.db.TransactionsFrom(orx, count)
+}
@@ -0,0 +1,71 @@
+/*
+Package repository implements repository for handling fast and efficient access to data required
+by the resolvers of the API server.
+
+Internally it utilizes RPC to access Ncogearthchain/Forest full node for blockchain interaction. Mongo database
+for fast, robust and scalable off-chain data storage, especially for aggregated and pre-calculated data mining
+results. BigCache for in-memory object storage to speed up loading of frequently accessed entities.
+*/
+package repository
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	etc "github.com/ethereum/go-ethereum/core/types"
+)
+
+// TransactionRevert represents an error of a transaction reverted during the dry run.
+type TransactionRevert struct {
+	// Reason represents the decoded revert reason, if the contract provided any.
+	Reason string
+
+	// Data represents the raw revert data.
+	Data hexutil.Bytes
+}
+
+// Error returns the text of the revert error.
+func (e *TransactionRevert) Error() string {
+	if e.Reason == "" {
+		return "execution reverted"
+	}
+	return fmt.Sprintf("execution reverted: %s", e.Reason)
+}
+
+// ChainID returns the chain identifier of the connected Ncogearthchain blockchain.
+func (p *proxy) ChainID() (*big.Int, error) {
+	return p.rpc.ChainID()
+}
+
+// DryRunTransaction executes the given signed transaction of the given sender against
+// the pending state of the blockchain without broadcasting it. Reverted execution is reported
+// by the TransactionRevert error with the revert reason decoded, if possible.
+func (p *proxy) DryRunTransaction(tx *etc.Transaction, from *common.Address) error {
+	err := p.rpc.DryRunTransaction(tx, from)
+	if err == nil {
+		return nil
+	}
+
+	// the revert data are attached to the RPC error, if the node provides them
+	var rev TransactionRevert
+	if de, ok := err.(interface{ ErrorData() interface{} }); ok {
+		if s, ok := de.ErrorData().(string); ok {
+			rev.Data, _ = hexutil.Decode(s)
+		}
+	}
+	if rev.Data == nil && !strings.HasPrefix(err.Error(), "execution reverted") {
+		return err
+	}
+
+	// decode the standard Error(string) revert, use the node message otherwise
+	reason, uerr := abi.UnpackRevert(rev.Data)
+	if uerr != nil {
+		reason = strings.TrimPrefix(strings.TrimPrefix(err.Error(), "execution reverted"), ": ")
+	}
+	rev.Reason = reason
+	return &rev
+}
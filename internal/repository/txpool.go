@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ObservedPending provides a channel fed with hashes of new transactions
+// entering the pool of the connected blockchain node.
+func (p *proxy) ObservedPending() chan common.Hash {
+	return p.rpc.ObservedPendingProxy()
+}
+
+// TxPoolStatus provides the number of transactions waiting in the pool of the connected node.
+func (p *proxy) TxPoolStatus() (*types.TxPoolStatus, error) {
+	return p.rpc.TxPoolStatus()
+}
+
+// PendingTransactions provides the list of transactions waiting in the pool
+// of the connected node sent from, or to the given address.
+func (p *proxy) PendingTransactions(addr *common.Address) ([]*types.Transaction, error) {
+	return p.rpc.TxPoolTransactions(addr)
+}
+
+// PoolTransactionsFrom provides the list of transactions sent from the given address
+// waiting in the pool of the connected node; the pool is read directly from the node.
+func (p *proxy) PoolTransactionsFrom(addr *common.Address) ([]*types.Transaction, error) {
+	return p.rpc.TxPoolTransactionsFrom(addr)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	etc "github.com/ethereum/go-ethereum/core/types"
 )
 
 // NativeTokenAddress returns address of the native token wrapper, if available.
@@ -14,6 +15,7 @@ func (p *proxy) NativeTokenAddress() (*common.Address, error) {
 }
 
 // UniswapPairs returns list of all token pairs managed by Uniswap core.
+// The list joins pairs of the factory with pairs discovered from the factory PairCreated events.
 // We use cache to store the list temporarily, the list is refreshed from RCP when the cache record expires.
 func (p *proxy) UniswapPairs() ([]common.Address, error) {
 	// try the cache first
@@ -28,15 +30,93 @@ func (p *proxy) UniswapPairs() ([]common.Address, error) {
 		log.Errorf("uniswap pairs not available; %s", err.Error())
 		return nil, err
 	}
+
+	// add pairs we discovered, but the factory list doesn't have yet
+	dl, err := p.db.UniswapPairsAddresses()
+	if err != nil {
+		log.Errorf("discovered uniswap pairs not available; %s", err.Error())
+	}
+	l = mergeAddresses(l, dl)
+
 	p.cache.PushAllPairsList(l)
 	return l, nil
 }
 
+// mergeAddresses adds addresses of the second list to the first one, if not already included.
+func mergeAddresses(l []common.Address, add []common.Address) []common.Address {
+	known := make(map[common.Address]bool, len(l))
+	for _, a := range l {
+		known[a] = true
+	}
+	for _, a := range add {
+		if !known[a] {
+			l = append(l, a)
+			known[a] = true
+		}
+	}
+	return l
+}
+
 // UniswapKnownPairs returns list of all known and whitelisted token pairs managed by Uniswap core.
 func (p *proxy) UniswapKnownPairs() ([]common.Address, error) {
 	return p.rpc.UniswapPairs(true)
 }
 
+// UniswapIsFeaturedPair checks if the given Uniswap pair is featured by the configured white list.
+func (p *proxy) UniswapIsFeaturedPair(pair *common.Address) bool {
+	return p.rpc.IsUniswapPairFeatured(pair)
+}
+
+// UniswapAddPair stores a new Uniswap pair discovered from the factory PairCreated event.
+// The cached list of all pairs is dropped so the new pair is picked up immediately.
+func (p *proxy) UniswapAddPair(pair *types.UniswapPairInfo) error {
+	if err := p.db.UniswapAddPair(pair); err != nil {
+		return err
+	}
+	p.cache.EvictAllPairsList()
+	return nil
+}
+
+// UniswapPairInfo returns details of a discovered Uniswap pair, if available.
+func (p *proxy) UniswapPairInfo(pair *common.Address) (*types.UniswapPairInfo, error) {
+	return p.db.UniswapPairInfo(pair)
+}
+
+// UniswapPairsToBackfill returns list of discovered Uniswap pairs with historical events not loaded yet.
+func (p *proxy) UniswapPairsToBackfill() ([]*types.UniswapPairInfo, error) {
+	return p.db.UniswapPairsToBackfill()
+}
+
+// UniswapPairBackfilled marks the given Uniswap pair as having historical events loaded.
+func (p *proxy) UniswapPairBackfilled(pair *common.Address) error {
+	return p.db.UniswapPairBackfilled(pair)
+}
+
+// UniswapPairsToRebuildPositions returns list of backfilled Uniswap pairs with liquidity positions not built yet.
+func (p *proxy) UniswapPairsToRebuildPositions() ([]*types.UniswapPairInfo, error) {
+	return p.db.UniswapPairsToRebuildPositions()
+}
+
+// UniswapPairPositionsRebuilt marks the given Uniswap pair as having liquidity positions built.
+func (p *proxy) UniswapPairPositionsRebuilt(pair *common.Address) error {
+	return p.db.UniswapPairPositionsRebuilt(pair)
+}
+
+// UniswapLastPairBlock returns the creation block of the most recently discovered Uniswap pair.
+func (p *proxy) UniswapLastPairBlock() (uint64, error) {
+	return p.db.UniswapLastPairBlock()
+}
+
+// UniswapPairCreatedLogs loads PairCreated events of the Uniswap factory in the given block range.
+func (p *proxy) UniswapPairCreatedLogs(from uint64, to uint64) ([]etc.Log, error) {
+	return p.rpc.UniswapPairCreatedLogs(from, to)
+}
+
+// UniswapPairLogs loads all the events of the given Uniswap pair in the given block range.
+func (p *proxy) UniswapPairLogs(pair *common.Address, from uint64, to uint64) ([]etc.Log, error) {
+	return p.rpc.UniswapPairLogs(pair, from, to)
+}
+
 // UniswapPair returns an address of an Uniswap pair for the given tokens.
 func (p *proxy) UniswapPair(tokenA *common.Address, tokenB *common.Address) (*common.Address, error) {
 	return p.rpc.UniswapPair(tokenA, tokenB)
@@ -54,16 +134,6 @@ func (p *proxy) UniswapAmountsIn(amountOut hexutil.Big, tokens []common.Address)
 	return p.rpc.UniswapAmountsIn(amountOut, tokens)
 }
 
-// UniswapQuoteInput calculates optimal input on sibling token based on input amount and
-// self reserves of the analyzed token.
-func (p *proxy) UniswapQuoteInput(
-	amountIn hexutil.Big,
-	reserveMy hexutil.Big,
-	reserveSibling hexutil.Big,
-) (hexutil.Big, error) {
-	return p.rpc.UniswapQuoteInput(amountIn, reserveMy, reserveSibling)
-}
-
 // UniswapTokens returns list of addresses of tokens involved in a Uniswap pair.
 func (p *proxy) UniswapTokens(pair *common.Address) ([]common.Address, error) {
 	var err error
@@ -81,8 +151,28 @@ func (p *proxy) UniswapTokens(pair *common.Address) ([]common.Address, error) {
 }
 
 // UniswapReserves returns list of token reserve amounts in a Uniswap pair.
+// The reserves are cached shortly, or until the pair emits a Sync event.
 func (p *proxy) UniswapReserves(pair *common.Address) ([]hexutil.Big, error) {
-	return p.rpc.UniswapReserves(pair)
+	// try cache first
+	rs := p.cache.PullUniswapReserves(pair)
+	if rs != nil {
+		return rs, nil
+	}
+
+	// load the hard way
+	rs, err := p.rpc.UniswapReserves(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	// push to cache for future use
+	p.cache.PushUniswapReserves(pair, rs)
+	return rs, nil
+}
+
+// UniswapReservesChanged notifies the repository about reserves of the given pair being changed.
+func (p *proxy) UniswapReservesChanged(pair *common.Address) {
+	p.cache.EvictUniswapReserves(pair)
 }
 
 // UniswapReservesTimeStamp returns the timestamp of the reserves of a Uniswap pair.
@@ -149,6 +239,16 @@ func (p *proxy) UniswapTimeReserves(pairAddress *common.Address, resolution stri
 	return p.db.UniswapTimeReserves(pairAddress, resolution, fromTime, toTime)
 }
 
+// UniswapCandlesRebuild builds the Uniswap candle rollups from stored swaps and replaces the live rollups.
+func (p *proxy) UniswapCandlesRebuild() error {
+	return p.db.UniswapCandlesRebuild()
+}
+
+// UniswapCandlesReady checks if the Uniswap candle rollups have been fully built from stored swaps.
+func (p *proxy) UniswapCandlesReady() bool {
+	return p.db.UniswapCandlesReady()
+}
+
 // UniswapActions provides list of uniswap actions stored in the persistent storage.
 func (p *proxy) UniswapActions(pairAddress *common.Address, cursor *string, count int32, actionType int32) (*types.UniswapActionList, error) {
 	return p.db.UniswapActions(pairAddress, cursor, count, actionType)
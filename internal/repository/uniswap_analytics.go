@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"math"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// uniswapAnalyticsDay represents the length of the analytics period in seconds.
+const uniswapAnalyticsDay = int64(24 * 60 * 60)
+
+// UniswapPairAnalytics provides the pre-calculated statistics of the given Uniswap pair.
+// The statistics are calculated on demand if the cache does not have them.
+func (p *proxy) UniswapPairAnalytics(pair *common.Address) (*types.UniswapPairAnalytics, error) {
+	// try cache first
+	if upa := p.cache.PullUniswapPairAnalytics(pair); upa != nil {
+		return upa, nil
+	}
+	return p.UniswapUpdatePairAnalytics(pair)
+}
+
+// UniswapUpdatePairAnalytics calculates the statistics of the given Uniswap pair
+// and stores them in the cache.
+func (p *proxy) UniswapUpdatePairAnalytics(pair *common.Address) (*types.UniswapPairAnalytics, error) {
+	upa, err := p.uniswapPairAnalytics(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.cache.PushUniswapPairAnalytics(upa); err != nil {
+		p.log.Errorf("can not cache uniswap pair %s analytics; %s", pair.String(), err.Error())
+	}
+	return upa, nil
+}
+
+// uniswapPairAnalytics calculates the statistics of the given Uniswap pair.
+func (p *proxy) uniswapPairAnalytics(pair *common.Address) (*types.UniswapPairAnalytics, error) {
+	tl, err := p.UniswapTokens(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := p.UniswapReserves(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Unix()
+	upa := types.UniswapPairAnalytics{
+		Pair:     *pair,
+		Reserves: rs,
+		Updated:  hexutil.Uint64(now),
+	}
+
+	// volumes of the last 24 hours and the 24 hours before
+	vol, err := p.db.UniswapVolume(pair, now-uniswapAnalyticsDay, now)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := p.db.UniswapVolume(pair, now-2*uniswapAnalyticsDay, now-uniswapAnalyticsDay)
+	if err != nil {
+		return nil, err
+	}
+	upa.Volume24h = hexutil.Big(*vol.Volume)
+	upa.VolumePrevious24h = hexutil.Big(*prev.Volume)
+	upa.VolumeChange24h = uniswapChange(uniswapTokenValue(prev.Volume, 0), uniswapTokenValue(vol.Volume, 0))
+
+	// transactions counts
+	tx, err := p.db.UniswapTransactionsCount(pair, now-uniswapAnalyticsDay, now)
+	if err != nil {
+		return nil, err
+	}
+	upa.TxCount24h = hexutil.Uint64(tx)
+
+	if tx, err = p.db.UniswapTransactionsCount(pair, 0, 0); err != nil {
+		return nil, err
+	}
+	upa.TxCount = hexutil.Uint64(tx)
+
+	// current price and the price 24 hours ago
+	dec := make(map[common.Address]int32)
+	d0, d1 := p.uniswapRouteDecimals(&tl[0], dec), p.uniswapRouteDecimals(&tl[1], dec)
+	upa.Price = uniswapMidPrice(rs[0].ToInt(), rs[1].ToInt(), d0, d1)
+
+	old, err := p.db.UniswapReservesAt(pair, now-uniswapAnalyticsDay)
+	if err != nil {
+		return nil, err
+	}
+	if old != nil {
+		upa.PriceChange24h = uniswapChange(uniswapMidPrice(old[0].ToInt(), old[1].ToInt(), d0, d1), upa.Price)
+	}
+
+	// the LP fee collected on the volume relative to the pool value, both in token0
+	if r0 := uniswapTokenValue(rs[0].ToInt(), d0); r0 > 0 {
+		fee := 1 - uniswapTokenValue(uniswapFeeNumerator, 0)/uniswapTokenValue(uniswapFeeDenominator, 0)
+		upa.FeeApr = uniswapTokenValue(vol.Volume, d0) * fee * 365 / (2 * r0) * 100
+	}
+
+	// values in fUSD by the price oracle
+	v0, ok0 := p.uniswapTokenFUSD(&tl[0], rs[0].ToInt(), d0)
+	v1, ok1 := p.uniswapTokenFUSD(&tl[1], rs[1].ToInt(), d1)
+	switch {
+	case ok0 && ok1:
+		upa.TotalValueLocked = v0 + v1
+	case ok0:
+		upa.TotalValueLocked = 2 * v0
+	case ok1:
+		upa.TotalValueLocked = 2 * v1
+	}
+	upa.IsInFUSD = ok0 || ok1
+
+	if r0 := uniswapTokenValue(rs[0].ToInt(), d0); r0 > 0 && upa.IsInFUSD {
+		upa.VolumeValue24h = uniswapTokenValue(vol.Volume, d0) * upa.TotalValueLocked / (2 * r0)
+	}
+	return &upa, nil
+}
+
+// uniswapTokenFUSD calculates the value of the given amount of a token in fUSD
+// using the price oracle. False is returned if the oracle does not know the token.
+func (p *proxy) uniswapTokenFUSD(token *common.Address, amount *big.Int, decimals int32) (float64, bool) {
+	price, ok := p.defiTokenPriceValue(token)
+	if !ok {
+		return 0, false
+	}
+	return uniswapTokenValue(amount, decimals) * price, true
+}
+
+// uniswapChange calculates the percentage change between the given values.
+func uniswapChange(from float64, to float64) float64 {
+	if from == 0 || math.IsNaN(from) || math.IsNaN(to) {
+		return 0
+	}
+	return (to - from) / from * 100
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"fmt"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// uniswapMinimumLiquidity represents the amount of liquidity tokens locked forever by the first mint of a pair.
+var uniswapMinimumLiquidity = big.NewInt(1000)
+
+// UniswapQuoteInput calculates optimal input on sibling token based on input amount and
+// self reserves of the analyzed token.
+func (p *proxy) UniswapQuoteInput(
+	amountIn hexutil.Big,
+	reserveMy hexutil.Big,
+	reserveSibling hexutil.Big,
+) (hexutil.Big, error) {
+	val := uniswapQuote(amountIn.ToInt(), reserveMy.ToInt(), reserveSibling.ToInt())
+	if val == nil {
+		return hexutil.Big{}, fmt.Errorf("insufficient amount or liquidity")
+	}
+	return hexutil.Big(*val), nil
+}
+
+// UniswapAddLiquidityQuote calculates the preview of adding the given amounts of tokens to a pair.
+// The amounts are expected in the order of the pair tokens, the slippage tolerance is given in percent.
+func (p *proxy) UniswapAddLiquidityQuote(pair *common.Address, amounts []hexutil.Big, slippage float64) (*types.UniswapLiquidityQuote, error) {
+	if len(amounts) != 2 || amounts[0].ToInt().Sign() <= 0 || amounts[1].ToInt().Sign() <= 0 {
+		return nil, fmt.Errorf("invalid input amounts pair given")
+	}
+
+	rs, supply, err := p.uniswapLiquidityState(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	var liquidity, total *big.Int
+	if supply.Sign() == 0 {
+		// the first provider gets the geometric mean of the amounts minus the locked part
+		liquidity = new(big.Int).Sqrt(new(big.Int).Mul(amounts[0].ToInt(), amounts[1].ToInt()))
+		liquidity.Sub(liquidity, uniswapMinimumLiquidity)
+		total = new(big.Int).Add(liquidity, uniswapMinimumLiquidity)
+
+		// the new pool will be priced by the amounts added
+		rs = []hexutil.Big{amounts[0], amounts[1]}
+	} else {
+		liquidity = uniswapQuote(amounts[0].ToInt(), rs[0].ToInt(), supply)
+		if l1 := uniswapQuote(amounts[1].ToInt(), rs[1].ToInt(), supply); l1 != nil && (liquidity == nil || l1.Cmp(liquidity) < 0) {
+			liquidity = l1
+		}
+		if liquidity != nil {
+			total = new(big.Int).Add(supply, liquidity)
+		}
+	}
+
+	if liquidity == nil || liquidity.Sign() <= 0 {
+		return nil, fmt.Errorf("insufficient liquidity minted")
+	}
+	return p.uniswapLiquidityQuote(pair, amounts, rs, liquidity, total, slippage), nil
+}
+
+// UniswapRemoveLiquidityQuote calculates the preview of removing the given amount of liquidity tokens from a pair.
+// The slippage tolerance is given in percent.
+func (p *proxy) UniswapRemoveLiquidityQuote(pair *common.Address, liquidity *big.Int, slippage float64) (*types.UniswapLiquidityQuote, error) {
+	rs, supply, err := p.uniswapLiquidityState(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	if liquidity == nil || liquidity.Sign() <= 0 || liquidity.Cmp(supply) > 0 {
+		return nil, fmt.Errorf("invalid liquidity amount")
+	}
+
+	// the provider gets the share of both reserves
+	amounts := make([]hexutil.Big, 2)
+	for i := range amounts {
+		val := uniswapQuote(liquidity, supply, rs[i].ToInt())
+		if val == nil || val.Sign() <= 0 {
+			return nil, fmt.Errorf("insufficient liquidity burned")
+		}
+		amounts[i] = hexutil.Big(*val)
+	}
+	return p.uniswapLiquidityQuote(pair, amounts, rs, liquidity, supply, slippage), nil
+}
+
+// uniswapLiquidityState loads the current reserves and the liquidity tokens supply of a pair.
+func (p *proxy) uniswapLiquidityState(pair *common.Address) ([]hexutil.Big, *big.Int, error) {
+	rs, err := p.UniswapReserves(pair)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rs) < 2 {
+		return nil, nil, fmt.Errorf("invalid pair %s", pair.String())
+	}
+
+	supply, err := p.Erc20TotalSupply(pair)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rs, supply.ToInt(), nil
+}
+
+// uniswapLiquidityQuote builds the liquidity change preview.
+func (p *proxy) uniswapLiquidityQuote(pair *common.Address, amounts []hexutil.Big, reserves []hexutil.Big, liquidity *big.Int, total *big.Int, slippage float64) *types.UniswapLiquidityQuote {
+	lq := types.UniswapLiquidityQuote{
+		Pair:           *pair,
+		Amounts:        amounts,
+		MinimumAmounts: make([]hexutil.Big, len(amounts)),
+		Liquidity:      hexutil.Big(*liquidity),
+		Prices:         make([]float64, 2),
+		Slippage:       slippage,
+	}
+
+	for i := range amounts {
+		lq.MinimumAmounts[i] = hexutil.Big(*uniswapMinimumAmount(amounts[i].ToInt(), slippage))
+	}
+
+	if total.Sign() > 0 {
+		lq.ShareOfPool = uniswapTokenValue(liquidity, 0) / uniswapTokenValue(total, 0) * 100
+	}
+
+	// prices are adjusted to tokens decimals
+	tl, err := p.UniswapTokens(pair)
+	if err != nil || len(tl) < 2 {
+		return &lq
+	}
+
+	dec := make(map[common.Address]int32)
+	d0, d1 := p.uniswapRouteDecimals(&tl[0], dec), p.uniswapRouteDecimals(&tl[1], dec)
+	lq.Prices[0] = uniswapMidPrice(reserves[0].ToInt(), reserves[1].ToInt(), d0, d1)
+	lq.Prices[1] = uniswapMidPrice(reserves[1].ToInt(), reserves[0].ToInt(), d1, d0)
+	return &lq
+}
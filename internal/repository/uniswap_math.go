@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"math"
+	"math/big"
+)
+
+// Uniswap pairs charge 0.3% fee on the input amount of each swap.
+var (
+	uniswapFeeNumerator   = big.NewInt(997)
+	uniswapFeeDenominator = big.NewInt(1000)
+)
+
+// uniswapAmountOut calculates the output amount of a swap for the given input amount
+// and pair reserves; it mirrors getAmountOut of the Uniswap router library.
+// Nil is returned if the swap is not possible.
+func uniswapAmountOut(amountIn *big.Int, reserveIn *big.Int, reserveOut *big.Int) *big.Int {
+	if amountIn.Sign() <= 0 || reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		return nil
+	}
+
+	inWithFee := new(big.Int).Mul(amountIn, uniswapFeeNumerator)
+	num := new(big.Int).Mul(inWithFee, reserveOut)
+	den := new(big.Int).Add(new(big.Int).Mul(reserveIn, uniswapFeeDenominator), inWithFee)
+	out := num.Div(num, den)
+	if out.Sign() <= 0 {
+		return nil
+	}
+	return out
+}
+
+// uniswapAmountIn calculates the input amount of a swap required to get the given output amount
+// from a pair with the given reserves; it mirrors getAmountIn of the Uniswap router library.
+// Nil is returned if the pair does not have enough liquidity.
+func uniswapAmountIn(amountOut *big.Int, reserveIn *big.Int, reserveOut *big.Int) *big.Int {
+	if amountOut.Sign() <= 0 || reserveIn.Sign() <= 0 || amountOut.Cmp(reserveOut) >= 0 {
+		return nil
+	}
+
+	num := new(big.Int).Mul(new(big.Int).Mul(reserveIn, amountOut), uniswapFeeDenominator)
+	den := new(big.Int).Mul(new(big.Int).Sub(reserveOut, amountOut), uniswapFeeNumerator)
+	in := num.Div(num, den)
+	return in.Add(in, big.NewInt(1))
+}
+
+// uniswapTokenValue converts the given raw token amount into token units using the token decimals.
+func uniswapTokenValue(amount *big.Int, decimals int32) float64 {
+	val, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(math.Pow10(int(decimals)))).Float64()
+	return val
+}
+
+// uniswapMidPrice calculates the price of the input token in output tokens
+// given by the pair reserves.
+func uniswapMidPrice(reserveIn *big.Int, reserveOut *big.Int, decimalsIn int32, decimalsOut int32) float64 {
+	in := uniswapTokenValue(reserveIn, decimalsIn)
+	if in == 0 {
+		return 0
+	}
+	return uniswapTokenValue(reserveOut, decimalsOut) / in
+}
+
+// uniswapPriceImpact calculates the percentage of the price change caused by a swap
+// of the given amounts on a pair with the given reserves before the swap. The LP fee
+// is deducted from the input, so the impact reflects the pool depth only.
+func uniswapPriceImpact(amountIn *big.Int, amountOut *big.Int, reserveIn *big.Int, reserveOut *big.Int) float64 {
+	if amountIn.Sign() <= 0 || reserveIn.Sign() <= 0 {
+		return 0
+	}
+
+	// the output we would get with no slippage, e.g. at the mid price
+	quoted := new(big.Float).SetInt(new(big.Int).Mul(new(big.Int).Mul(amountIn, uniswapFeeNumerator), reserveOut))
+	quoted.Quo(quoted, new(big.Float).SetInt(new(big.Int).Mul(reserveIn, uniswapFeeDenominator)))
+	if quoted.Sign() <= 0 {
+		return 0
+	}
+
+	diff := new(big.Float).Sub(quoted, new(big.Float).SetInt(amountOut))
+	impact, _ := diff.Quo(diff, quoted).Float64()
+	return impact * 100
+}
+
+// uniswapQuote calculates the amount of the sibling token equivalent to the given amount
+// at the current reserves ratio; it mirrors quote of the Uniswap router library.
+// Nil is returned if the pair does not have any liquidity.
+func uniswapQuote(amountA *big.Int, reserveA *big.Int, reserveB *big.Int) *big.Int {
+	if amountA.Sign() <= 0 || reserveA.Sign() <= 0 || reserveB.Sign() <= 0 {
+		return nil
+	}
+
+	val := new(big.Int).Mul(amountA, reserveB)
+	return val.Div(val, reserveA)
+}
+
+// uniswapLpFee calculates the fee paid to liquidity providers of a pair on a swap of the given input amount.
+func uniswapLpFee(amountIn *big.Int) *big.Int {
+	fee := new(big.Int).Mul(amountIn, new(big.Int).Sub(uniswapFeeDenominator, uniswapFeeNumerator))
+	return fee.Div(fee, uniswapFeeDenominator)
+}
+
+// uniswapSlippageBps converts the slippage tolerance in percent into basis points.
+func uniswapSlippageBps(slippage float64) *big.Int {
+	bps := int64(math.Round(slippage * 100))
+	if bps < 0 {
+		bps = 0
+	}
+	if bps > 10000 {
+		bps = 10000
+	}
+	return big.NewInt(bps)
+}
+
+// uniswapMinimumAmount calculates the lowest amount acceptable for the given slippage tolerance in percent.
+func uniswapMinimumAmount(amount *big.Int, slippage float64) *big.Int {
+	val := new(big.Int).Mul(amount, new(big.Int).Sub(big.NewInt(10000), uniswapSlippageBps(slippage)))
+	return val.Div(val, big.NewInt(10000))
+}
+
+// uniswapMaximumAmount calculates the highest amount acceptable for the given slippage tolerance in percent.
+func uniswapMaximumAmount(amount *big.Int, slippage float64) *big.Int {
+	val := new(big.Int).Mul(amount, new(big.Int).Add(big.NewInt(10000), uniswapSlippageBps(slippage)))
+	return val.Div(val, big.NewInt(10000))
+}
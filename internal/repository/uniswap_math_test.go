@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestUniswapAmountOut(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// 0.3% fee is deducted from the input
+	g.Expect(uniswapAmountOut(big.NewInt(1000), big.NewInt(100000), big.NewInt(100000))).To(gomega.Equal(big.NewInt(987)))
+
+	// no swap without liquidity, or input
+	g.Expect(uniswapAmountOut(big.NewInt(1000), big.NewInt(0), big.NewInt(100000))).To(gomega.BeNil())
+	g.Expect(uniswapAmountOut(big.NewInt(0), big.NewInt(100000), big.NewInt(100000))).To(gomega.BeNil())
+
+	// output rounded down to zero is not a swap
+	g.Expect(uniswapAmountOut(big.NewInt(1), big.NewInt(100000), big.NewInt(10))).To(gomega.BeNil())
+}
+
+func TestUniswapAmountIn(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// the input required for the output is rounded up
+	g.Expect(uniswapAmountIn(big.NewInt(987), big.NewInt(100000), big.NewInt(100000))).To(gomega.Equal(big.NewInt(1000)))
+
+	// the output can not drain the pair
+	g.Expect(uniswapAmountIn(big.NewInt(100000), big.NewInt(100000), big.NewInt(100000))).To(gomega.BeNil())
+	g.Expect(uniswapAmountIn(big.NewInt(0), big.NewInt(100000), big.NewInt(100000))).To(gomega.BeNil())
+}
+
+func TestUniswapAmountRoundTrip(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	rIn, _ := new(big.Int).SetString("1234567890123456789012", 10)
+	rOut, _ := new(big.Int).SetString("987654321098765432", 10)
+	for _, amount := range []int64{1e9, 1e12, 1e15, 1e18} {
+		in := big.NewInt(amount)
+		out := uniswapAmountOut(in, rIn, rOut)
+		g.Expect(out).NotTo(gomega.BeNil())
+
+		// the input calculated back never exceeds the original input
+		back := uniswapAmountIn(out, rIn, rOut)
+		g.Expect(back.Cmp(in)).To(gomega.BeNumerically("<=", 0))
+	}
+}
+
+func TestUniswapMidPrice(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// 2 units of 18 decimals token against 4 units of 6 decimals token
+	rIn, _ := new(big.Int).SetString("2000000000000000000", 10)
+	g.Expect(uniswapMidPrice(rIn, big.NewInt(4000000), 18, 6)).To(gomega.BeNumerically("~", 2.0, 1e-9))
+	g.Expect(uniswapMidPrice(big.NewInt(0), big.NewInt(4000000), 18, 6)).To(gomega.BeZero())
+}
+
+func TestUniswapPriceImpact(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// 1000 in for 987 out, while the mid price after fee promises 997
+	g.Expect(uniswapPriceImpact(big.NewInt(1000), big.NewInt(987), big.NewInt(100000), big.NewInt(100000))).To(gomega.BeNumerically("~", 1.003, 0.001))
+
+	// the impact grows with the swap size
+	small := uniswapPriceImpact(big.NewInt(10), uniswapAmountOut(big.NewInt(10), big.NewInt(100000), big.NewInt(100000)), big.NewInt(100000), big.NewInt(100000))
+	large := uniswapPriceImpact(big.NewInt(50000), uniswapAmountOut(big.NewInt(50000), big.NewInt(100000), big.NewInt(100000)), big.NewInt(100000), big.NewInt(100000))
+	g.Expect(small).To(gomega.BeNumerically("<", large))
+	g.Expect(large).To(gomega.BeNumerically("~", 33.3, 0.1))
+}
+
+func TestUniswapQuote(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// the amount keeps the reserves ratio, rounded down
+	g.Expect(uniswapQuote(big.NewInt(1000), big.NewInt(100000), big.NewInt(250000))).To(gomega.Equal(big.NewInt(2500)))
+	g.Expect(uniswapQuote(big.NewInt(1), big.NewInt(3), big.NewInt(2)).Sign()).To(gomega.BeZero())
+
+	// no quote without liquidity, or amount
+	g.Expect(uniswapQuote(big.NewInt(1000), big.NewInt(0), big.NewInt(250000))).To(gomega.BeNil())
+	g.Expect(uniswapQuote(big.NewInt(1000), big.NewInt(100000), big.NewInt(0))).To(gomega.BeNil())
+	g.Expect(uniswapQuote(big.NewInt(0), big.NewInt(100000), big.NewInt(250000))).To(gomega.BeNil())
+}
+
+func TestUniswapLpFee(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// 0.3% of the input, rounded down
+	g.Expect(uniswapLpFee(big.NewInt(1000))).To(gomega.Equal(big.NewInt(3)))
+	g.Expect(uniswapLpFee(big.NewInt(999))).To(gomega.Equal(big.NewInt(2)))
+	g.Expect(uniswapLpFee(big.NewInt(0)).Sign()).To(gomega.BeZero())
+
+	// the fee and the input used by the swap make up the whole input
+	in := big.NewInt(123456789)
+	used := new(big.Int).Div(new(big.Int).Mul(in, uniswapFeeNumerator), uniswapFeeDenominator)
+	g.Expect(new(big.Int).Add(used, uniswapLpFee(in)).Cmp(in)).To(gomega.BeNumerically("<=", 0))
+}
+
+func TestUniswapSlippageBps(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(uniswapSlippageBps(0.5)).To(gomega.Equal(big.NewInt(50)))
+	g.Expect(uniswapSlippageBps(0.125)).To(gomega.Equal(big.NewInt(13)))
+
+	// the tolerance is clamped between 0% and 100%
+	g.Expect(uniswapSlippageBps(-1).Sign()).To(gomega.BeZero())
+	g.Expect(uniswapSlippageBps(250)).To(gomega.Equal(big.NewInt(10000)))
+}
+
+func TestUniswapSlippageLimits(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(uniswapMinimumAmount(big.NewInt(10000), 0.5)).To(gomega.Equal(big.NewInt(9950)))
+	g.Expect(uniswapMaximumAmount(big.NewInt(10000), 0.5)).To(gomega.Equal(big.NewInt(10050)))
+
+	// zero tolerance keeps the amount
+	g.Expect(uniswapMinimumAmount(big.NewInt(10000), 0)).To(gomega.Equal(big.NewInt(10000)))
+	g.Expect(uniswapMaximumAmount(big.NewInt(10000), 0)).To(gomega.Equal(big.NewInt(10000)))
+
+	// the minimum never goes negative, the maximum is at most doubled
+	g.Expect(uniswapMinimumAmount(big.NewInt(10000), 150).Sign()).To(gomega.BeZero())
+	g.Expect(uniswapMaximumAmount(big.NewInt(10000), 150)).To(gomega.Equal(big.NewInt(20000)))
+
+	// both limits are rounded down
+	g.Expect(uniswapMinimumAmount(big.NewInt(999), 1)).To(gomega.Equal(big.NewInt(989)))
+	g.Expect(uniswapMaximumAmount(big.NewInt(999), 1)).To(gomega.Equal(big.NewInt(1008)))
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// UniswapPosition loads the liquidity position of the given owner on the given pair.
+// Nil is returned if the position does not exist.
+func (p *proxy) UniswapPosition(pair *common.Address, owner *common.Address) (*types.UniswapPosition, error) {
+	return p.db.UniswapPosition(pair, owner)
+}
+
+// UniswapStorePosition stores the given liquidity position changed by the pair event of the given ordinal.
+// It returns false if the event, or any later one, has already been applied to the position before.
+func (p *proxy) UniswapStorePosition(pos *types.UniswapPosition, ord uint64) (bool, error) {
+	return p.db.UniswapStorePosition(pos, ord)
+}
+
+// UniswapDropPositions removes all the liquidity positions of the given pair.
+func (p *proxy) UniswapDropPositions(pair *common.Address) error {
+	return p.db.UniswapDropPositions(pair)
+}
+
+// UniswapPositionsByOwner loads all the open liquidity positions of the given owner.
+func (p *proxy) UniswapPositionsByOwner(owner *common.Address) ([]*types.UniswapPosition, error) {
+	return p.db.UniswapPositionsByOwner(owner)
+}
+
+// UniswapPositionsByPair loads a list of open liquidity positions of the given pair.
+func (p *proxy) UniswapPositionsByPair(pair *common.Address, cursor *string, count int32) (*types.UniswapPositionList, error) {
+	return p.db.UniswapPositionsByPair(pair, cursor, count)
+}
+
+// UniswapPositionValue calculates the current value of the given liquidity position
+// from the pair reserves and the liquidity tokens supply.
+func (p *proxy) UniswapPositionValue(pos *types.UniswapPosition) (*types.UniswapPositionValue, error) {
+	rs, supply, err := p.uniswapLiquidityState(&pos.Pair)
+	if err != nil {
+		return nil, err
+	}
+
+	val := types.UniswapPositionValue{
+		Amounts:    []hexutil.Big{{}, {}},
+		FeesEarned: []hexutil.Big{{}, {}},
+	}
+
+	liquidity := pos.Liquidity.ToInt()
+	if liquidity.Sign() <= 0 || supply.Sign() <= 0 || rs[0].ToInt().Sign() <= 0 {
+		return &val, nil
+	}
+
+	// the share of the reserves owned by the position
+	for i := range val.Amounts {
+		val.Amounts[i] = hexutil.Big(*new(big.Int).Div(new(big.Int).Mul(liquidity, rs[i].ToInt()), supply))
+	}
+	val.ShareOfPool = uniswapTokenValue(liquidity, 0) / uniswapTokenValue(supply, 0) * 100
+
+	// the pool grows its sqrt(k) only by collecting fees, so the growth
+	// of the position sqrt(k) since the deposit estimates fees earned
+	rootK := new(big.Int).Sqrt(new(big.Int).Mul(val.Amounts[0].ToInt(), val.Amounts[1].ToInt()))
+	growth := 1.0
+	if rk := uniswapTokenValue(pos.RootK.ToInt(), 0); rk > 0 {
+		growth = uniswapTokenValue(rootK, 0) / rk
+	}
+
+	if growth > 1 {
+		for i := range val.FeesEarned {
+			fee, _ := new(big.Float).Mul(new(big.Float).SetInt(val.Amounts[i].ToInt()), big.NewFloat(1-1/growth)).Int(nil)
+			val.FeesEarned[i] = hexutil.Big(*fee)
+		}
+	} else {
+		growth = 1
+	}
+
+	// compare the position without fees against holding the deposited tokens, both in token1
+	price := uniswapTokenValue(rs[1].ToInt(), 0) / uniswapTokenValue(rs[0].ToInt(), 0)
+	hold := uniswapTokenValue(pos.Deposited[0].ToInt(), 0)*price + uniswapTokenValue(pos.Deposited[1].ToInt(), 0)
+	pool := (uniswapTokenValue(val.Amounts[0].ToInt(), 0)*price + uniswapTokenValue(val.Amounts[1].ToInt(), 0)) / growth
+	if hold > 0 {
+		val.ImpermanentLoss = (hold - pool) / hold * 100
+	}
+	return &val, nil
+}
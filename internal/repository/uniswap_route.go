@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"fmt"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// uniswapRouteMaxHops represents the max number of swaps allowed on a single route.
+const uniswapRouteMaxHops = 4
+
+// uniswapRouteMaxSteps represents the max number of graph edges explored by a single route search.
+const uniswapRouteMaxSteps = 50000
+
+// uniswapRouteMaxPaths represents the max number of routes evaluated by a single route search.
+const uniswapRouteMaxPaths = 1000
+
+// uniswapRouteGraphTTL represents the max age of the cached routing graph.
+const uniswapRouteGraphTTL = 10 * time.Second
+
+// uniswapRouteEdge represents a pair connecting a token to another token in the routing graph.
+type uniswapRouteEdge struct {
+	pair       common.Address
+	token      common.Address
+	reserveIn  *big.Int
+	reserveOut *big.Int
+}
+
+// uniswapRouteGraph represents the graph of tokens connected by Uniswap pairs.
+type uniswapRouteGraph map[common.Address][]uniswapRouteEdge
+
+// uniswapRouteGraphCache represents the routing graph shared by route searches.
+// The graph is read only once built, so it's safe to be used concurrently.
+type uniswapRouteGraphCache struct {
+	sync.Mutex
+	graph uniswapRouteGraph
+	built time.Time
+}
+
+// UniswapBestRoutes finds the best routes for swapping tokenIn for tokenOut across known Uniswap pairs.
+// If exactOut is false, the amount is the input amount and routes are ranked by the output amount;
+// otherwise the amount is the output amount and routes are ranked by the required input amount.
+// The slippage tolerance is given in percent.
+func (p *proxy) UniswapBestRoutes(tokenIn *common.Address, tokenOut *common.Address, amount *big.Int, exactOut bool, maxHops int, count int, slippage float64) ([]*types.UniswapRoute, error) {
+	if *tokenIn == *tokenOut {
+		return nil, fmt.Errorf("can not route token %s to itself", tokenIn.String())
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid swap amount")
+	}
+
+	// sanitize the search depth
+	if maxHops < 1 {
+		maxHops = 1
+	}
+	if maxHops > uniswapRouteMaxHops {
+		maxHops = uniswapRouteMaxHops
+	}
+
+	graph, err := p.uniswapRouteGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	// collect and evaluate all the simple paths
+	list := make([]*types.UniswapRoute, 0)
+	dec := make(map[common.Address]int32)
+	for _, path := range graph.paths(*tokenIn, *tokenOut, maxHops, uniswapRouteMaxSteps, uniswapRouteMaxPaths) {
+		if r := p.uniswapRouteEvaluate(path, *tokenIn, amount, exactOut, slippage, dec); r != nil {
+			list = append(list, r)
+		}
+	}
+
+	// rank the routes
+	sort.SliceStable(list, func(i, j int) bool {
+		if exactOut {
+			return list[i].AmountIn.ToInt().Cmp(list[j].AmountIn.ToInt()) < 0
+		}
+		return list[i].AmountOut.ToInt().Cmp(list[j].AmountOut.ToInt()) > 0
+	})
+
+	if count > 0 && len(list) > count {
+		list = list[:count]
+	}
+	return list, nil
+}
+
+// uniswapRouteGraph provides the routing graph from all the known pairs and their recent reserves.
+// The graph is cached shortly; concurrent requests share a single build.
+func (p *proxy) uniswapRouteGraph() (uniswapRouteGraph, error) {
+	p.routeGraph.Lock()
+	if p.routeGraph.graph != nil && time.Since(p.routeGraph.built) < uniswapRouteGraphTTL {
+		defer p.routeGraph.Unlock()
+		return p.routeGraph.graph, nil
+	}
+	p.routeGraph.Unlock()
+
+	g, err, _ := p.apiRequestGroup.Do("uniswap_route_graph", func() (interface{}, error) {
+		graph, err := p.uniswapBuildRouteGraph()
+		if err != nil {
+			return nil, err
+		}
+
+		p.routeGraph.Lock()
+		p.routeGraph.graph, p.routeGraph.built = graph, time.Now()
+		p.routeGraph.Unlock()
+		return graph, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g.(uniswapRouteGraph), nil
+}
+
+// uniswapBuildRouteGraph builds the routing graph from all the known pairs and their current reserves.
+func (p *proxy) uniswapBuildRouteGraph() (uniswapRouteGraph, error) {
+	pairs, err := p.UniswapPairs()
+	if err != nil {
+		return nil, err
+	}
+
+	graph := make(uniswapRouteGraph)
+	for i := range pairs {
+		tl, err := p.UniswapTokens(&pairs[i])
+		if err != nil || len(tl) < 2 {
+			p.log.Errorf("tokens of pair %s not available", pairs[i].String())
+			continue
+		}
+
+		rs, err := p.UniswapReserves(&pairs[i])
+		if err != nil || len(rs) < 2 {
+			p.log.Errorf("reserves of pair %s not available", pairs[i].String())
+			continue
+		}
+
+		// empty pairs can not be used for routing
+		if rs[0].ToInt().Sign() <= 0 || rs[1].ToInt().Sign() <= 0 {
+			continue
+		}
+
+		graph[tl[0]] = append(graph[tl[0]], uniswapRouteEdge{pair: pairs[i], token: tl[1], reserveIn: rs[0].ToInt(), reserveOut: rs[1].ToInt()})
+		graph[tl[1]] = append(graph[tl[1]], uniswapRouteEdge{pair: pairs[i], token: tl[0], reserveIn: rs[1].ToInt(), reserveOut: rs[0].ToInt()})
+	}
+	return graph, nil
+}
+
+// paths collects the simple paths between the given tokens not longer than the given number of hops.
+// The search stops when the given number of edges has been explored, or the given number of paths found,
+// so a densely connected graph can not make it run for too long.
+func (g uniswapRouteGraph) paths(from common.Address, to common.Address, maxHops int, maxSteps int, maxPaths int) [][]uniswapRouteEdge {
+	res := make([][]uniswapRouteEdge, 0)
+	visited := map[common.Address]bool{from: true}
+	path := make([]uniswapRouteEdge, 0, maxHops)
+	steps := 0
+
+	var walk func(token common.Address)
+	walk = func(token common.Address) {
+		for _, e := range g[token] {
+			if steps >= maxSteps || len(res) >= maxPaths {
+				return
+			}
+
+			steps++
+			if visited[e.token] {
+				continue
+			}
+
+			path = append(path, e)
+			if e.token == to {
+				res = append(res, append([]uniswapRouteEdge{}, path...))
+			} else if len(path) < maxHops {
+				visited[e.token] = true
+				walk(e.token)
+				visited[e.token] = false
+			}
+			path = path[:len(path)-1]
+		}
+	}
+
+	walk(from)
+	return res
+}
+
+// UniswapQuote calculates the swap of the given amount along the given list of tokens
+// with the price impact, fees and limits for the slippage tolerance given in percent.
+// If exactOut is false, the amount is the input amount; otherwise it's the output amount.
+func (p *proxy) UniswapQuote(tokens []common.Address, amount *big.Int, exactOut bool, slippage float64) (*types.UniswapRoute, error) {
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("at least two tokens are needed for a swap")
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid swap amount")
+	}
+
+	// collect pairs of the path
+	path := make([]uniswapRouteEdge, len(tokens)-1)
+	for i := 0; i < len(tokens)-1; i++ {
+		e, err := p.uniswapRouteStep(&tokens[i], &tokens[i+1])
+		if err != nil {
+			return nil, err
+		}
+		path[i] = *e
+	}
+
+	r := p.uniswapRouteEvaluate(path, tokens[0], amount, exactOut, slippage, make(map[common.Address]int32))
+	if r == nil {
+		return nil, fmt.Errorf("insufficient liquidity for the swap")
+	}
+	return r, nil
+}
+
+// uniswapRouteStep builds the routing graph edge for a swap from tokenIn to tokenOut.
+func (p *proxy) uniswapRouteStep(tokenIn *common.Address, tokenOut *common.Address) (*uniswapRouteEdge, error) {
+	pair, err := p.UniswapPair(tokenIn, tokenOut)
+	if err != nil {
+		return nil, err
+	}
+	if *pair == (common.Address{}) {
+		return nil, fmt.Errorf("no pair for tokens %s and %s", tokenIn.String(), tokenOut.String())
+	}
+
+	tl, err := p.UniswapTokens(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := p.UniswapReserves(pair)
+	if err != nil {
+		return nil, err
+	}
+	if len(tl) < 2 || len(rs) < 2 {
+		return nil, fmt.Errorf("invalid pair %s", pair.String())
+	}
+
+	if tl[0] == *tokenIn {
+		return &uniswapRouteEdge{pair: *pair, token: *tokenOut, reserveIn: rs[0].ToInt(), reserveOut: rs[1].ToInt()}, nil
+	}
+	return &uniswapRouteEdge{pair: *pair, token: *tokenOut, reserveIn: rs[1].ToInt(), reserveOut: rs[0].ToInt()}, nil
+}
+
+// uniswapRouteEvaluate calculates amounts swapped along the given path.
+// Nil is returned if the path can not be used for the swap.
+func (p *proxy) uniswapRouteEvaluate(path []uniswapRouteEdge, tokenIn common.Address, amount *big.Int, exactOut bool, slippage float64, dec map[common.Address]int32) *types.UniswapRoute {
+	amounts := make([]*big.Int, len(path)+1)
+
+	// exact output is calculated backwards from the last step
+	if exactOut {
+		amounts[len(path)] = amount
+		for i := len(path) - 1; i >= 0; i-- {
+			amounts[i] = uniswapAmountIn(amounts[i+1], path[i].reserveIn, path[i].reserveOut)
+			if amounts[i] == nil {
+				return nil
+			}
+		}
+		return p.uniswapRoute(path, tokenIn, amounts, exactOut, slippage, dec)
+	}
+
+	amounts[0] = amount
+	for i, e := range path {
+		amounts[i+1] = uniswapAmountOut(amounts[i], e.reserveIn, e.reserveOut)
+		if amounts[i+1] == nil {
+			return nil
+		}
+	}
+	return p.uniswapRoute(path, tokenIn, amounts, exactOut, slippage, dec)
+}
+
+// uniswapRoute builds the route description from the path and the amounts swapped on it.
+func (p *proxy) uniswapRoute(path []uniswapRouteEdge, tokenIn common.Address, amounts []*big.Int, exactOut bool, slippage float64, dec map[common.Address]int32) *types.UniswapRoute {
+	r := types.UniswapRoute{
+		Hops:            make([]types.UniswapRouteHop, len(path)),
+		AmountIn:        hexutil.Big(*amounts[0]),
+		AmountOut:       hexutil.Big(*amounts[len(path)]),
+		MidPrice:        1,
+		Slippage:        slippage,
+		MinimumReceived: hexutil.Big(*amounts[len(path)]),
+		MaximumSold:     hexutil.Big(*amounts[0]),
+	}
+
+	// the side given by the caller is exact, the other one is limited by the slippage
+	if exactOut {
+		r.MaximumSold = hexutil.Big(*uniswapMaximumAmount(amounts[0], slippage))
+	} else {
+		r.MinimumReceived = hexutil.Big(*uniswapMinimumAmount(amounts[len(path)], slippage))
+	}
+
+	keep, fee := 1.0, 1.0
+	token := tokenIn
+	for i, e := range path {
+		hop := types.UniswapRouteHop{
+			Pair:        e.pair,
+			TokenIn:     token,
+			TokenOut:    e.token,
+			AmountIn:    hexutil.Big(*amounts[i]),
+			AmountOut:   hexutil.Big(*amounts[i+1]),
+			MidPrice:    uniswapMidPrice(e.reserveIn, e.reserveOut, p.uniswapRouteDecimals(&token, dec), p.uniswapRouteDecimals(&e.token, dec)),
+			PriceImpact: uniswapPriceImpact(amounts[i], amounts[i+1], e.reserveIn, e.reserveOut),
+			LpFee:       hexutil.Big(*uniswapLpFee(amounts[i])),
+		}
+
+		r.Hops[i] = hop
+		r.MidPrice *= hop.MidPrice
+		keep *= 1 - hop.PriceImpact/100
+		fee *= 0.997
+		token = e.token
+	}
+
+	r.PriceImpact = (1 - keep) * 100
+	r.LpFeePercent = (1 - fee) * 100
+	in := uniswapTokenValue(amounts[0], p.uniswapRouteDecimals(&tokenIn, dec))
+	if in > 0 {
+		r.ExecutionPrice = uniswapTokenValue(amounts[len(path)], p.uniswapRouteDecimals(&token, dec)) / in
+	}
+	return &r
+}
+
+// uniswapRouteDecimals provides decimals of the given token using the local lookup map.
+func (p *proxy) uniswapRouteDecimals(token *common.Address, dec map[common.Address]int32) int32 {
+	if d, ok := dec[*token]; ok {
+		return d
+	}
+
+	d, err := p.Erc20Decimals(token)
+	if err != nil {
+		p.log.Errorf("decimals of token %s not available; %s", token.String(), err.Error())
+		d = 18
+	}
+
+	dec[*token] = d
+	return d
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/onsi/gomega"
+)
+
+// testRouteGraph builds a routing graph of the given token pairs; pairs are numbered from 0x100.
+func testRouteGraph(pairs [][2]int64) uniswapRouteGraph {
+	g := make(uniswapRouteGraph)
+	for i, p := range pairs {
+		pair := common.BigToAddress(big.NewInt(0x100 + int64(i)))
+		a, b := common.BigToAddress(big.NewInt(p[0])), common.BigToAddress(big.NewInt(p[1]))
+		g[a] = append(g[a], uniswapRouteEdge{pair: pair, token: b, reserveIn: big.NewInt(1000), reserveOut: big.NewInt(1000)})
+		g[b] = append(g[b], uniswapRouteEdge{pair: pair, token: a, reserveIn: big.NewInt(1000), reserveOut: big.NewInt(1000)})
+	}
+	return g
+}
+
+func TestUniswapRouteGraphPaths(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// 1-2 direct, 1-3-2, 1-3-4-2
+	graph := testRouteGraph([][2]int64{{1, 2}, {1, 3}, {3, 2}, {3, 4}, {4, 2}})
+	from, to := common.BigToAddress(big.NewInt(1)), common.BigToAddress(big.NewInt(2))
+
+	g.Expect(graph.paths(from, to, 1, 1000, 1000)).To(gomega.HaveLen(1))
+	g.Expect(graph.paths(from, to, 2, 1000, 1000)).To(gomega.HaveLen(2))
+
+	// no token is visited twice on a path
+	all := graph.paths(from, to, 4, 1000, 1000)
+	g.Expect(all).To(gomega.HaveLen(3))
+	for _, path := range all {
+		seen := map[common.Address]bool{from: true}
+		for _, e := range path {
+			g.Expect(seen[e.token]).To(gomega.BeFalse())
+			seen[e.token] = true
+		}
+		g.Expect(path[len(path)-1].token).To(gomega.Equal(to))
+	}
+}
+
+func TestUniswapRouteGraphPathsBounded(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// fully connected graph of 30 tokens has a huge number of simple paths
+	pairs := make([][2]int64, 0)
+	for i := int64(1); i <= 30; i++ {
+		for j := i + 1; j <= 30; j++ {
+			pairs = append(pairs, [2]int64{i, j})
+		}
+	}
+	graph := testRouteGraph(pairs)
+	from, to := common.BigToAddress(big.NewInt(1)), common.BigToAddress(big.NewInt(2))
+
+	g.Expect(graph.paths(from, to, 4, 1000000, 25)).To(gomega.HaveLen(25))
+	g.Expect(len(graph.paths(from, to, 4, 100, 1000000))).To(gomega.BeNumerically("<=", 100))
+}
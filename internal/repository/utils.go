@@ -9,12 +9,8 @@ results. BigCache for in-memory object storage to speed up loading of frequently
 package repository
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"math"
 	"ncogearthchain-api-graphql/internal/types"
-	"net/http"
 	"strings"
 	"time"
 
@@ -45,14 +41,7 @@ func (p *proxy) GasPriceExtended() (*types.GasPrice, error) {
 		return nil, err
 	}
 
-	// calculate the gas price in Gwei units
-	gWei := math.Round(float64(gp.ToInt().Int64())/float64(10000000)) / 10.0
-	return &types.GasPrice{
-		Fast:    gWei,
-		Fastest: gWei,
-		SafeLow: gWei,
-		Average: gWei,
-	}, nil
+	return types.NewGasPrice(gp.ToInt()), nil
 }
 
 // GasPriceTicks provides a list of gas price ticks for the given time period.
@@ -137,26 +126,11 @@ func priceRequestName(sym string) string {
 	return sb.String()
 }
 
-// getPriceApiUrl builds REST API endpoint URL for the given target symbol.
-func getPriceApiUrl(sym string) string {
-	// use the builder
-	var sb strings.Builder
-
-	sb.WriteString(priceApiAddress)
-	sb.WriteString(priceApiSourceSymbolVar)
-	sb.WriteString(ownPriceSymbol)
-	sb.WriteString("&")
-	sb.WriteString(priceApiTargetSymbolVar)
-	sb.WriteString(sym)
-
-	return sb.String()
-}
-
-// requestRemotePrice pulls the price for given symbol from an external API
+// requestRemotePrice pulls the price for given symbol from the configured price sources
 // and ensures the result, if valid, is stored in cache for future use
 func (p *proxy) requestRemotePrice(sym string) (types.Price, error) {
-	// make the request tpo remote API
-	pri, err := p.makePriceRequest(sym)
+	// aggregate the price from the price sources
+	pri, err := p.aggregatePrice(sym)
 	if err != nil {
 		return types.Price{}, err
 	}
@@ -171,66 +145,3 @@ func (p *proxy) requestRemotePrice(sym string) (types.Price, error) {
 	p.log.Infof("price loaded: %s -> %s = %f", pri.FromSymbol, pri.ToSymbol, pri.Price)
 	return pri, nil
 }
-
-// makePriceRequest executes a request to remote API to pull the price
-// and return the result from the pull.
-func (p *proxy) makePriceRequest(sym string) (types.Price, error) {
-	// prep the request
-	req, err := http.NewRequest("GET", getPriceApiUrl(sym), nil)
-	if err != nil {
-		return types.Price{}, fmt.Errorf("can not create HTTP request for price API; %s", err.Error())
-	}
-
-	// do the request
-	client := &http.Client{Timeout: time.Second * pricePullRequestTimeout}
-	resp, err := client.Do(req)
-	if err != nil {
-		return types.Price{}, fmt.Errorf("can not query price API; %s", err.Error())
-	}
-
-	// don't forget to close
-	defer func() {
-		// log the HTTP request
-		p.log.Debugf("finished HTTP request to pull [%s] price", sym)
-
-		// close the connection
-		err := resp.Body.Close()
-		if err != nil {
-			p.log.Errorf("error closing price API request; %s", err.Error())
-		}
-
-		// is this a panic?
-		if r := recover(); r != nil {
-			p.log.Errorf("error parsing price API response; %s", r)
-		}
-	}()
-
-	// read the data
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return types.Price{}, fmt.Errorf("can not read price API response; %s", err.Error())
-	}
-
-	// we need to be able to read the data
-	var data map[string]map[string]map[string]map[string]interface{}
-	err = json.Unmarshal(body, &data)
-	if err != nil {
-		return types.Price{}, fmt.Errorf("can not decode price API response; %s", err.Error())
-	}
-
-	return types.Price{
-		FromSymbol:    (data["RAW"][ownPriceSymbol][sym]["FROMSYMBOL"]).(string),
-		ToSymbol:      (data["RAW"][ownPriceSymbol][sym]["TOSYMBOL"]).(string),
-		Price:         (data["RAW"][ownPriceSymbol][sym]["PRICE"]).(float64),
-		Open24:        (data["RAW"][ownPriceSymbol][sym]["OPEN24HOUR"]).(float64),
-		High24:        (data["RAW"][ownPriceSymbol][sym]["HIGH24HOUR"]).(float64),
-		Low24:         (data["RAW"][ownPriceSymbol][sym]["LOW24HOUR"]).(float64),
-		Volume24:      (data["RAW"][ownPriceSymbol][sym]["VOLUME24HOUR"]).(float64),
-		Change24:      (data["RAW"][ownPriceSymbol][sym]["CHANGE24HOUR"]).(float64),
-		ChangePct24:   (data["RAW"][ownPriceSymbol][sym]["CHANGEPCT24HOUR"]).(float64),
-		TotalVolume24: (data["RAW"][ownPriceSymbol][sym]["TOTALVOLUME24H"]).(float64),
-		Supply:        (data["RAW"][ownPriceSymbol][sym]["SUPPLY"]).(float64),
-		MarketCap:     (data["RAW"][ownPriceSymbol][sym]["MKTCAP"]).(float64),
-		LastUpdate:    hexutil.Uint64(uint64((data["RAW"][ownPriceSymbol][sym]["LASTUPDATE"]).(float64))),
-	}, nil
-}
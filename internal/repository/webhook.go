@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"ncogearthchain-api-graphql/internal/types"
+	"time"
+)
+
+// AddWebhook stores the given webhook registration.
+func (p *proxy) AddWebhook(wh *types.Webhook) error {
+	return p.db.AddWebhook(wh)
+}
+
+// RemoveWebhook removes the webhook registration of the given id.
+// False is returned if the webhook is not known.
+func (p *proxy) RemoveWebhook(id string) (bool, error) {
+	return p.db.RemoveWebhook(id)
+}
+
+// Webhook loads the webhook registration of the given id.
+// Nil is returned if the webhook is not known.
+func (p *proxy) Webhook(id string) (*types.Webhook, error) {
+	return p.db.Webhook(id)
+}
+
+// Webhooks loads all the registered webhooks.
+func (p *proxy) Webhooks() ([]*types.Webhook, error) {
+	return p.db.Webhooks()
+}
+
+// StoreWebhookDelivery stores the given webhook delivery, an existing delivery is updated.
+func (p *proxy) StoreWebhookDelivery(wd *types.WebhookDelivery) error {
+	return p.db.StoreWebhookDelivery(wd)
+}
+
+// WebhookDeliveries loads the most recent deliveries of the given webhook,
+// optionally limited to the deliveries of the given status.
+func (p *proxy) WebhookDeliveries(hook string, status *string, count int64) ([]*types.WebhookDelivery, error) {
+	return p.db.WebhookDeliveries(hook, status, count)
+}
+
+// DueWebhookDeliveries loads pending webhook deliveries scheduled to be attempted by the given time.
+func (p *proxy) DueWebhookDeliveries(due time.Time, count int64) ([]*types.WebhookDelivery, error) {
+	return p.db.DueWebhookDeliveries(due, count)
+}
+
+// PurgeWebhookDeliveries removes the finished webhook deliveries, both delivered and dead,
+// last attempted before the given time.
+func (p *proxy) PurgeWebhookDeliveries(before time.Time) (int64, error) {
+	return p.db.PurgeWebhookDeliveries(before)
+}
@@ -0,0 +1,64 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"math/big"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// publishAccountActivity publishes the activity to the topics of all the given accounts.
+// Each account receives the activity once; the zero address is never notified.
+func publishAccountActivity(act types.AccountActivity, addr ...common.Address) {
+	for i, adr := range addr {
+		if adr == (common.Address{}) || isAddressListed(addr[:i], &adr) {
+			continue
+		}
+
+		evt := act
+		evt.Address = adr
+		broker.B().Publish(broker.TopicAccountActivity(&adr), &evt)
+	}
+}
+
+// isAddressListed checks if the address is in the given list.
+func isAddressListed(list []common.Address, adr *common.Address) bool {
+	for _, a := range list {
+		if a == *adr {
+			return true
+		}
+	}
+	return false
+}
+
+// publishTrxActivity publishes the native transaction to the accounts involved.
+func publishTrxActivity(trx *types.Transaction) {
+	addr := []common.Address{trx.From}
+	if trx.To != nil {
+		addr = append(addr, *trx.To)
+	}
+	if trx.ContractAddress != nil {
+		addr = append(addr, *trx.ContractAddress)
+	}
+
+	publishAccountActivity(types.AccountActivity{
+		Type:        types.AccountActivityTransaction,
+		Transaction: trx.Hash,
+		TimeStamp:   hexutil.Uint64(trx.TimeStamp.Unix()),
+		Amount:      &trx.Value,
+	}, addr...)
+}
+
+// publishStakingActivity publishes a staking event of the given account.
+func publishStakingActivity(lr *types.LogRecord, typ string, addr common.Address, valID *big.Int, amo *big.Int) {
+	publishAccountActivity(types.AccountActivity{
+		Type:        typ,
+		Transaction: lr.TxHash,
+		TimeStamp:   lr.Block.TimeStamp,
+		Amount:      (*hexutil.Big)(amo),
+		ValidatorId: (*hexutil.Big)(valID),
+	}, addr)
+}
@@ -3,6 +3,7 @@ package svc
 
 import (
 	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
 	"ncogearthchain-api-graphql/internal/types"
 	"time"
 
@@ -22,7 +23,6 @@ type eventTrx struct {
 // blockDispatcher implements a service responsible for processing new blocks on the blockchain.
 type blockDispatcher struct {
 	service
-	onBlock        chan *types.Block
 	inBlock        chan *types.Block
 	outTransaction chan *eventTrx
 	outDispatched  chan uint64
@@ -85,11 +85,8 @@ func (bld *blockDispatcher) execute() {
 				continue
 			}
 
-			// broadcast the block event
-			select {
-			case bld.onBlock <- blk:
-			case <-time.After(200 * time.Millisecond):
-			}
+			// publish the block to the broker subscribers
+			broker.B().Publish(broker.TopicBlocks, blk)
 
 			// add the block to the ring
 			repo.CacheBlock(blk)
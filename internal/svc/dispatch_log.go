@@ -3,6 +3,7 @@ package svc
 
 import (
 	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
 	"ncogearthchain-api-graphql/internal/types"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -104,8 +105,17 @@ func (lgd *logDispatcher) init() {
 		/* ERC1155::TransferBatch(address indexed operator, address indexed from, address indexed to, uint256[] ids, uint256[] values) */
 		common.HexToHash("0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb"): handleErc1155TransferBatch,
 
+		/* wNEC::Deposit(address indexed dst, uint256 wad) */
+		common.HexToHash("0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c"): handleWrappedNativeDeposit,
+
+		/* wNEC::Withdrawal(address indexed src, uint256 wad) */
+		common.HexToHash("0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65"): handleWrappedNativeWithdrawal,
+
 		/* --------------------- Uniswap contract related event hooks below this line --------------------- */
 
+		/* UniswapFactory::PairCreated(address indexed token0, address indexed token1, address pair, uint256) */
+		common.HexToHash("0x0d3648bd0f6ba80134a33ba9275ac585d9d315f0ad8355cddefde31afa28d0e9"): handleUniswapPairCreated,
+
 		/* UniswapPair::Swap(address indexed sender, uint256 amount0In, uint256 amount1In, uint256 amount0Out, uint256 amount1Out, address indexed to) */
 		common.HexToHash("0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822"): handleUniswapSwap,
 
@@ -179,6 +189,11 @@ func (lgd *logDispatcher) execute() {
 				}
 			}
 
+			// publish the log to the broker subscribers
+			if nil != lr && broker.B().HasSubscribers(broker.TopicLogs) {
+				broker.B().Publish(broker.TopicLogs, lr)
+			}
+
 			// mark the processing of this log record as finished
 			lr.WatchDog.Done()
 		}
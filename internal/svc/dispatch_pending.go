@@ -0,0 +1,112 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// pendingLoadWorkers represents the number of parallel pending transaction loaders.
+	pendingLoadWorkers = 4
+
+	// pendingLoadQueueLength represents the capacity of the pending transaction load queue.
+	pendingLoadQueueLength = 1000
+)
+
+// pendingDispatcher implements dispatcher of new transactions entering the node pool.
+// Pending transactions are loaded and published only if anybody subscribed for them.
+// The transactions are loaded from the node by a limited number of workers; hashes
+// not fitting into the load queue are skipped, so a slow node never stalls the dispatch.
+type pendingDispatcher struct {
+	service
+	queue chan common.Hash
+	done  chan struct{}
+}
+
+// name returns the name of the service used by orchestrator.
+func (pnd *pendingDispatcher) name() string {
+	return "pending transactions dispatcher"
+}
+
+// init prepares the pending transactions dispatcher to perform its function.
+func (pnd *pendingDispatcher) init() {
+	pnd.sigStop = make(chan bool, 1)
+	pnd.queue = make(chan common.Hash, pendingLoadQueueLength)
+	pnd.done = make(chan struct{})
+}
+
+// run starts the pending transactions dispatcher job.
+func (pnd *pendingDispatcher) run() {
+	// make sure we are orchestrated
+	if pnd.mgr == nil {
+		panic(fmt.Errorf("no svc manager set on %s", pnd.name()))
+	}
+
+	for i := 0; i < pendingLoadWorkers; i++ {
+		pnd.mgr.started(pnd)
+		go pnd.work()
+	}
+
+	// signal orchestrator we started and go
+	pnd.mgr.started(pnd)
+	go pnd.execute()
+}
+
+// execute implements the dispatcher reader and publisher routine.
+func (pnd *pendingDispatcher) execute() {
+	// don't forget to sign off after we are done
+	defer func() {
+		close(pnd.done)
+		close(pnd.sigStop)
+		pnd.mgr.finished(pnd)
+	}()
+
+	in := repo.ObservedPending()
+	for {
+		select {
+		case <-pnd.sigStop:
+			return
+		case hash, ok := <-in:
+			if !ok {
+				log.Noticef("pending transactions channel closed, terminating %s", pnd.name())
+				return
+			}
+
+			// tracked transactions are now known to be in the pool
+			pnd.mgr.trt.seen(&hash)
+
+			// nobody listens, no need to load the transaction
+			if !broker.B().HasSubscribers(broker.TopicPendingTransactions) {
+				continue
+			}
+
+			select {
+			case pnd.queue <- hash:
+			default:
+				log.Debugf("pending transaction load queue full, %s skipped", hash.String())
+			}
+		}
+	}
+}
+
+// work loads the queued pending transactions and publishes them.
+func (pnd *pendingDispatcher) work() {
+	defer pnd.mgr.finished(pnd)
+
+	for {
+		select {
+		case <-pnd.done:
+			return
+		case hash := <-pnd.queue:
+			trx, err := repo.LoadTransaction(&hash)
+			if err != nil {
+				log.Debugf("pending transaction %s not available; %s", hash.String(), err.Error())
+				continue
+			}
+			broker.B().Publish(broker.TopicPendingTransactions, trx)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package svc
 
 import (
 	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
 	"ncogearthchain-api-graphql/internal/types"
 	"sync"
 	"time"
@@ -35,13 +36,15 @@ type eventAcc struct {
 // trxDispatcher implements dispatcher of new transactions in the blockchain.
 type trxDispatcher struct {
 	service
-	onTransaction  chan *types.Transaction
 	bot            *time.Ticker
 	blkObserver    *atomic.Uint64
 	inTransaction  chan *eventTrx
 	outTransaction chan *eventTrx
 	outAccount     chan *eventAcc
 	outLog         chan *types.LogRecord
+
+	// published is closed once the last dispatched transaction is published
+	published chan struct{}
 }
 
 // name returns the name of the service used by orchestrator.
@@ -153,17 +156,18 @@ func (trd *trxDispatcher) process(evt *eventTrx) {
 
 	// store the transaction into the database once the processing is done
 	// we spawn a lot of go-routines here, so we should test the optimal queue length above
-	go trd.waitAndStore(evt, &wg)
-
-	// broadcast new transaction; if it can not be broadcast quickly, skip
-	select {
-	case trd.onTransaction <- evt.trx:
-	case <-time.After(200 * time.Millisecond):
-	}
+	prev, done := trd.published, make(chan struct{})
+	trd.published = done
+	go trd.waitAndStore(evt, &wg, prev, done)
 }
 
 // waitAndStore waits for the transaction processing to finish and stores the transaction into db.
-func (trd *trxDispatcher) waitAndStore(evt *eventTrx, wg *sync.WaitGroup) {
+// The transaction is published to the broker subscribers once it's stored, so the subscribers
+// can load it, or replay it, right after the event arrives. Transactions are published
+// in the order of dispatching; the previous transaction closes the prev channel once published.
+func (trd *trxDispatcher) waitAndStore(evt *eventTrx, wg *sync.WaitGroup, prev <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
 	// wait until all the sub-processors finish their job
 	wg.Wait()
 	if err := repo.StoreTransaction(evt.blk, evt.trx); err != nil {
@@ -173,6 +177,17 @@ func (trd *trxDispatcher) waitAndStore(evt *eventTrx, wg *sync.WaitGroup) {
 	repo.IncTrxCountEstimate(1)
 	repo.CacheTransaction(evt.trx)
 	trd.blkObserver.Store(uint64(evt.blk.Number))
+
+	// wait for the previous transaction to be published
+	if prev != nil {
+		<-prev
+	}
+
+	// notify the accounts involved
+	publishTrxActivity(evt.trx)
+
+	// publish the new transaction to the broker subscribers
+	broker.B().Publish(broker.TopicTransactions, evt.trx)
 }
 
 // pushAccounts pushes given transaction accounts on both sides observing terminate signal on process.
@@ -3,6 +3,7 @@ package svc
 
 import (
 	"math/big"
+	"ncogearthchain-api-graphql/internal/broker"
 	"ncogearthchain-api-graphql/internal/config"
 	"ncogearthchain-api-graphql/internal/repository/rpc"
 	"ncogearthchain-api-graphql/internal/types"
@@ -21,6 +22,9 @@ func handleErcTokenApproval(lr *types.LogRecord) {
 // event Transfer(address indexed from, address indexed to, uint256 value)
 func handleErcTokenTransfer(lr *types.LogRecord) {
 	handleErcTransaction(lr, types.TokenTrxTypeTransfer)
+
+	// liquidity tokens of Uniswap pairs are tracked per provider
+	handleUniswapLiquidityTransfer(lr)
 }
 
 // handleErcTransaction handles Approval and/or Transfer event on an ERC20/ERC721 token.
@@ -101,7 +105,7 @@ func tokenTrxType(trxType int32, from common.Address, to common.Address) int32 {
 
 // storeTokenTransaction handles general token (ERC20/ERC721/ERC1155) transaction.
 func storeTokenTransaction(lr *types.LogRecord, tokenType string, eventType int32, from common.Address, to common.Address, amount big.Int, tokenId big.Int, seq uint16) {
-	if err := repo.StoreTokenTransaction(&types.TokenTransaction{
+	trx := types.TokenTransaction{
 		Transaction:  lr.TxHash,
 		TrxIndex:     hexutil.Uint64(uint64(lr.TxIndex)),
 		TokenAddress: lr.Address,
@@ -115,7 +119,23 @@ func storeTokenTransaction(lr *types.LogRecord, tokenType string, eventType int3
 		LogIndex:     lr.Index,
 		BlockNumber:  lr.BlockNumber,
 		Seq:          seq, // sequence of erc transactions emitted by one log event - non-zero only for batch transfer events
-	}); err != nil {
+	}
+	if err := repo.StoreTokenTransaction(&trx); err != nil {
 		log.Errorf("can not store token %s trx for call %s; %s", tokenType, lr.TxHash.String(), err.Error())
+		return
+	}
+
+	// approvals do not move any tokens
+	if eventType != types.TokenTrxTypeApproval && eventType != types.TokenTrxTypeApprovalForAll {
+		broker.B().Publish(broker.TopicTokenTransfer(&lr.Address), &trx)
+		broker.B().Publish(broker.TopicTokenTransfer(nil), &trx)
+
+		publishAccountActivity(types.AccountActivity{
+			Type:             types.AccountActivityTokenTransfer,
+			Transaction:      lr.TxHash,
+			TimeStamp:        lr.Block.TimeStamp,
+			Amount:           &trx.Amount,
+			TokenTransaction: &trx,
+		}, from, to)
 	}
 }
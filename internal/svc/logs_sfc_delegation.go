@@ -39,7 +39,9 @@ func handleNewDelegation(lr *types.LogRecord, stakerID *big.Int, addr common.Add
 	// store the delegation
 	if err := repo.StoreDelegation(&dl); err != nil {
 		log.Errorf("failed to store delegation; %s", err.Error())
+		return
 	}
+	publishStakingActivity(lr, types.AccountActivityDelegation, addr, stakerID, amo)
 }
 
 // handleSfcCreatedDelegation handles a new delegation event from SFC v1 and SFC v2 contract
@@ -118,6 +120,8 @@ func handleNewWithdrawRequest(wrt string, adr common.Address, valID *big.Int, re
 	// store the request
 	if err := repo.StoreWithdrawRequest(&wr); err != nil {
 		log.Errorf("failed to store new withdraw request; %s", err.Error())
+	} else {
+		publishStakingActivity(lr, types.AccountActivityUndelegation, adr, valID, amo)
 	}
 
 	// check active amount on the delegation
@@ -163,7 +167,9 @@ func handleFinishedWithdrawRequest(adr common.Address, valID *big.Int, reqID *bi
 	// store the updated request
 	if err := repo.UpdateWithdrawRequest(req); err != nil {
 		log.Errorf("failed to store finalized withdraw request; %s", err.Error())
+		return
 	}
+	publishStakingActivity(lr, types.AccountActivityWithdrawal, adr, valID, req.Amount.ToInt())
 }
 
 // handleSfc1DeactivatedDelegation handles SFC1 delegation deactivation request.
@@ -26,6 +26,7 @@ func handleSfcRewardClaim(lr *types.LogRecord, addr common.Address, valID *hexut
 		log.Criticalf("can not store rewards claim; %s", err.Error())
 		return
 	}
+	publishStakingActivity(lr, types.AccountActivityRewardClaim, addr, valID.ToInt(), amo)
 
 	// check active amount on the delegation
 	if err := repo.UpdateDelegationBalance(&addr, valID, func(amo *big.Int) error {
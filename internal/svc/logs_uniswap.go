@@ -2,16 +2,24 @@
 package svc
 
 import (
+	"bytes"
 	"math/big"
+	"ncogearthchain-api-graphql/internal/broker"
 	"ncogearthchain-api-graphql/internal/types"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	etc "github.com/ethereum/go-ethereum/core/types"
 )
 
 // uniswapKnownPairs represents a map of known pairs and their participation in our approved Uniswap instance.
 var uniswapKnownPairs = make(map[common.Address]bool, 1000)
 
+// uniswapKnownPairsLock guards the map of known pairs since the pairs backfill
+// processes events outside the log dispatcher thread.
+var uniswapKnownPairsLock sync.RWMutex
+
 // uniswapOrdinalIndex calculates ordinal index of the given Uniswap transaction.
 func uniswapOrdinalIndex(lr *types.LogRecord) uint64 {
 	return ((uint64(lr.Block.Number) << 14) & 0x7FFFFFFFFFFFFFFF) | ((uint64(lr.TxIndex) << 8) & 0x3fff) | (uint64(lr.Index) & 0xff)
@@ -21,7 +29,10 @@ func uniswapOrdinalIndex(lr *types.LogRecord) uint64 {
 // belongs to our approved Uniswap instance.
 func isKnownUniswapPair(pair *common.Address) bool {
 	// do we know the address already?
+	uniswapKnownPairsLock.RLock()
 	apr, ok := uniswapKnownPairs[*pair]
+	uniswapKnownPairsLock.RUnlock()
+
 	if !ok {
 		apr = false
 
@@ -39,11 +50,96 @@ func isKnownUniswapPair(pair *common.Address) bool {
 		}
 
 		// store the value locally for future use
+		uniswapKnownPairsLock.Lock()
 		uniswapKnownPairs[*pair] = apr
+		uniswapKnownPairsLock.Unlock()
 	}
 	return apr
 }
 
+// uniswapPairInfo builds the Uniswap pair information from the factory PairCreated event lr
+// including the basic metadata of the pair tokens.
+// UniswapFactory::PairCreated(address indexed token0, address indexed token1, address pair, uint256)
+func uniswapPairInfo(lr *etc.Log, ts hexutil.Uint64) *types.UniswapPairInfo {
+	// sanity check for data (1 x address + 1 x uint256 = 2x32 bytes = 64 bytes), (1 x subject topic + 2 x address = 3 topics)
+	if len(lr.Data) != 64 || len(lr.Topics) != 3 {
+		log.Errorf("%s invalid data length; expected 64 bytes, %d bytes given; expected 3 topics, %d given",
+			lr.TxHash.String(),
+			len(lr.Data),
+			len(lr.Topics),
+		)
+		return nil
+	}
+
+	pi := types.UniswapPairInfo{
+		Pair:      common.BytesToAddress(lr.Data[12:32]),
+		Index:     new(big.Int).SetBytes(lr.Data[32:]).Uint64(),
+		Token0:    common.BytesToAddress(lr.Topics[1].Bytes()),
+		Token1:    common.BytesToAddress(lr.Topics[2].Bytes()),
+		Block:     hexutil.Uint64(lr.BlockNumber),
+		TimeStamp: ts,
+		Trx:       lr.TxHash,
+	}
+
+	// load tokens metadata; missing metadata does not prevent the pair from being known
+	var err error
+	if pi.Token0Symbol, err = repo.Erc20Symbol(&pi.Token0); err != nil {
+		log.Debugf("uniswap token %s symbol not available; %s", pi.Token0.String(), err.Error())
+	}
+	if pi.Token1Symbol, err = repo.Erc20Symbol(&pi.Token1); err != nil {
+		log.Debugf("uniswap token %s symbol not available; %s", pi.Token1.String(), err.Error())
+	}
+	if pi.Token0Decimals, err = repo.Erc20Decimals(&pi.Token0); err != nil {
+		log.Debugf("uniswap token %s decimals not available; %s", pi.Token0.String(), err.Error())
+	}
+	if pi.Token1Decimals, err = repo.Erc20Decimals(&pi.Token1); err != nil {
+		log.Debugf("uniswap token %s decimals not available; %s", pi.Token1.String(), err.Error())
+	}
+	return &pi
+}
+
+// addUniswapPair stores the given newly discovered Uniswap pair and marks it as known.
+func addUniswapPair(pi *types.UniswapPairInfo) {
+	// log what we got
+	log.Noticef("uniswap pair %s of %s/%s created at block #%d",
+		pi.Pair.String(),
+		pi.Token0.String(),
+		pi.Token1.String(),
+		pi.Block,
+	)
+
+	if err := repo.UniswapAddPair(pi); err != nil {
+		log.Errorf("could not store uniswap pair %s; %s", pi.Pair.String(), err.Error())
+		return
+	}
+
+	// the pair is known from now on
+	uniswapKnownPairsLock.Lock()
+	uniswapKnownPairs[pi.Pair] = true
+	uniswapKnownPairsLock.Unlock()
+}
+
+// handleUniswapPairCreated processes Uniswap factory PairCreated event lr emitted
+// when a new pair is deployed by the factory.
+// UniswapFactory::PairCreated(address indexed token0, address indexed token1, address pair, uint256)
+func handleUniswapPairCreated(lr *types.LogRecord) {
+	// we accept new pairs only from our configured factory
+	if !bytes.Equal(lr.Address.Bytes(), cfg.DeFi.Uniswap.Core.Bytes()) {
+		return
+	}
+
+	pi := uniswapPairInfo(&lr.Log, lr.Block.TimeStamp)
+	if pi == nil {
+		return
+	}
+
+	// we process the blocks sequentially, so all the pair events will be dispatched
+	// after this one; there is no history to be loaded
+	pi.Backfilled = true
+	pi.Positions = true
+	addUniswapPair(pi)
+}
+
 // handleUniswapSwap processes Uniswap Swap event lr emitted when a sender trades
 // input tokens to gain output tokens, this is the basic type of trade on an Uniswap pair.
 // UniswapPair::Swap(address indexed sender, uint256 amount0In, uint256 amount1In, uint256 amount0Out, uint256 amount1Out, address indexed to)
@@ -83,7 +179,7 @@ func handleUniswapSwap(lr *types.LogRecord) {
 	)
 
 	// store the swap to repository
-	err := repo.UniswapAdd(&types.Swap{
+	swap := types.Swap{
 		OrdIndex:    uniswapOrdinalIndex(lr),
 		BlockNumber: &lr.Block.Number,
 		Type:        types.SwapMint,
@@ -97,9 +193,15 @@ func handleUniswapSwap(lr *types.LogRecord) {
 		Amount1Out:  out1,
 		Reserve0:    z,
 		Reserve1:    z,
-	})
-	if err != nil {
+	}
+	if err := repo.UniswapAdd(&swap); err != nil {
 		log.Errorf("%s could not store uniswap event #%d; %s", lr.TxHash.String(), lr.Index, err.Error())
+		return
+	}
+
+	// notify subscribers of the pair about the swap
+	if topic := broker.TopicUniswapSwap(&lr.Address); broker.B().HasSubscribers(topic) {
+		broker.B().Publish(topic, &swap)
 	}
 }
 
@@ -156,6 +258,8 @@ func handleUniswapMint(lr *types.LogRecord) {
 	if err != nil {
 		log.Errorf("%s could not store uniswap event #%d; %s", lr.TxHash.String(), lr.Index, err.Error())
 	}
+	// attribute the new liquidity to the provider
+	uniswapPositionApply(lr, uniswapPositionDeposit(lr))
 }
 
 // handleUniswapBurn processes Uniswap Burn event lr emitted when a sender claims liquidity
@@ -211,6 +315,8 @@ func handleUniswapBurn(lr *types.LogRecord) {
 	if err != nil {
 		log.Errorf("%s could not store uniswap event #%d; %s", lr.TxHash.String(), lr.Index, err.Error())
 	}
+	// remove the liquidity from the provider
+	uniswapPositionApply(lr, uniswapPositionWithdraw(lr))
 }
 
 // handleUniswapSync processes Uniswap Sync event lr.
@@ -244,6 +350,20 @@ func handleUniswapSync(lr *types.LogRecord) {
 		((*hexutil.Big)(r1)).String(),
 	)
 
+	// cached reserves of the pair are no longer valid
+	repo.UniswapReservesChanged(&lr.Address)
+
+	// notify subscribers of the pair about the new price
+	if topic := broker.TopicUniswapPairPrice(&lr.Address); broker.B().HasSubscribers(topic) {
+		broker.B().Publish(topic, &types.UniswapPairPrice{
+			PairAddress:     lr.Address,
+			BlockNr:         lr.Block.Number,
+			Time:            lr.Block.TimeStamp,
+			TransactionHash: lr.Trx.Hash,
+			Reserves:        []hexutil.Big{hexutil.Big(*r0), hexutil.Big(*r1)},
+		})
+	}
+
 	// store the swap to repository
 	err := repo.UniswapAdd(&types.Swap{
 		OrdIndex:    uniswapOrdinalIndex(lr),
@@ -0,0 +1,269 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// uniswapTransferTopic represents the topic of the liquidity token Transfer event.
+var uniswapTransferTopic = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+// uniswapPositionsLock serializes liquidity positions updates since the pairs backfill
+// processes events outside the log dispatcher thread.
+var uniswapPositionsLock sync.Mutex
+
+// uniswapPositionsRebuild keeps changes of liquidity positions of pairs being rebuilt
+// from their history; the changes are applied once the rebuild reaches the dispatcher.
+var uniswapPositionsRebuild = make(map[common.Address][]func())
+
+// handleUniswapLiquidityTransfer processes the Transfer event lr of a pair liquidity token
+// moving the liquidity between providers. Minting and burning of the liquidity
+// is processed with the pair Mint and Burn events.
+// UniswapPair::Transfer(address indexed from, address indexed to, uint256 value)
+func handleUniswapLiquidityTransfer(lr *types.LogRecord) {
+	if !isKnownUniswapPair(&lr.Address) {
+		return
+	}
+	uniswapPositionApply(lr, uniswapPositionTransfer(lr))
+}
+
+// uniswapPositionApply applies the given change of liquidity positions made by the event lr.
+// Changes of pairs being rebuilt are postponed until the rebuild is done.
+func uniswapPositionApply(lr *types.LogRecord, change func()) {
+	if change == nil {
+		return
+	}
+
+	uniswapPositionsLock.Lock()
+	defer uniswapPositionsLock.Unlock()
+
+	if late, ok := uniswapPositionsRebuild[lr.Address]; ok {
+		uniswapPositionsRebuild[lr.Address] = append(late, change)
+		return
+	}
+	change()
+}
+
+// uniswapPositionReplay applies the given change of liquidity positions made by a historical event
+// of a pair being rebuilt.
+func uniswapPositionReplay(change func()) {
+	if change == nil {
+		return
+	}
+
+	uniswapPositionsLock.Lock()
+	defer uniswapPositionsLock.Unlock()
+	change()
+}
+
+// uniswapPositionTransfer prepares the change of liquidity positions made by the Transfer event lr.
+// Nil is returned if the transfer does not move the liquidity between providers.
+func uniswapPositionTransfer(lr *types.LogRecord) func() {
+	if len(lr.Topics) != 3 || len(lr.Data) != 32 {
+		return nil
+	}
+
+	from := common.BytesToAddress(lr.Topics[1].Bytes())
+	to := common.BytesToAddress(lr.Topics[2].Bytes())
+	if from == (common.Address{}) || to == (common.Address{}) || to == lr.Address {
+		return nil
+	}
+
+	return func() {
+		// take the cost basis of the liquidity from the sender, if it's a known provider
+		liquidity := new(big.Int).SetBytes(lr.Data)
+		dep, rk := []*big.Int{new(big.Int), new(big.Int)}, new(big.Int)
+
+		sender := uniswapPositionLoad(lr, &from, false)
+		if sender != nil {
+			dep, rk = uniswapPositionReduce(sender, liquidity)
+		}
+
+		// the receiver goes first, so an interrupted transfer is finished on the sender side only
+		uniswapPositionUpdate(lr, &to, true, func(pos *types.UniswapPosition) {
+			uniswapPositionAdd(pos, liquidity, dep, rk)
+		})
+		if sender != nil {
+			uniswapPositionStore(lr, sender)
+		}
+	}
+}
+
+// uniswapPositionDeposit prepares the change of liquidity positions made by the Mint event lr
+// attributing the minted liquidity to the liquidity provider.
+func uniswapPositionDeposit(lr *types.LogRecord) func() {
+	if len(lr.Data) != 64 {
+		return nil
+	}
+
+	// find the liquidity minted to the provider; the protocol fee may be minted before it
+	_, owner, liquidity := uniswapLiquidityTransferBefore(lr, func(from common.Address, to common.Address) bool {
+		return from == common.Address{} && to != common.Address{}
+	})
+	if liquidity == nil {
+		return nil
+	}
+
+	amount0 := new(big.Int).SetBytes(lr.Data[:32])
+	amount1 := new(big.Int).SetBytes(lr.Data[32:])
+	return func() {
+		rk := new(big.Int).Sqrt(new(big.Int).Mul(amount0, amount1))
+		uniswapPositionUpdate(lr, &owner, true, func(pos *types.UniswapPosition) {
+			uniswapPositionAdd(pos, liquidity, []*big.Int{amount0, amount1}, rk)
+		})
+	}
+}
+
+// uniswapPositionWithdraw prepares the change of liquidity positions made by the Burn event lr
+// removing the burned liquidity from the liquidity provider.
+func uniswapPositionWithdraw(lr *types.LogRecord) func() {
+	if len(lr.Data) != 64 {
+		return nil
+	}
+
+	// the liquidity burned by the pair
+	_, _, liquidity := uniswapLiquidityTransferBefore(lr, func(from common.Address, to common.Address) bool {
+		return from == lr.Address && to == common.Address{}
+	})
+
+	// the provider sent the liquidity to the pair before it was burned
+	owner, _, _ := uniswapLiquidityTransferBefore(lr, func(from common.Address, to common.Address) bool {
+		return to == lr.Address && from != common.Address{}
+	})
+	if liquidity == nil || owner == (common.Address{}) {
+		return nil
+	}
+
+	amount0 := new(big.Int).SetBytes(lr.Data[:32])
+	amount1 := new(big.Int).SetBytes(lr.Data[32:])
+	return func() {
+		uniswapPositionUpdate(lr, &owner, false, func(pos *types.UniswapPosition) {
+			uniswapPositionReduce(pos, liquidity)
+			pos.Withdrawn[0] = hexutil.Big(*new(big.Int).Add(pos.Withdrawn[0].ToInt(), amount0))
+			pos.Withdrawn[1] = hexutil.Big(*new(big.Int).Add(pos.Withdrawn[1].ToInt(), amount1))
+		})
+	}
+}
+
+// uniswapLiquidityTransferBefore finds the last liquidity token transfer of the pair matching the given filter
+// emitted by the transaction of the event lr before the event itself.
+func uniswapLiquidityTransferBefore(lr *types.LogRecord, match func(common.Address, common.Address) bool) (common.Address, common.Address, *big.Int) {
+	var from, to common.Address
+	var amount *big.Int
+
+	if lr.Trx == nil {
+		return from, to, nil
+	}
+
+	for _, lg := range lr.Trx.Logs {
+		if lg.Index >= lr.Index || lg.Address != lr.Address || len(lg.Topics) != 3 || len(lg.Data) != 32 || lg.Topics[0] != uniswapTransferTopic {
+			continue
+		}
+
+		f, t := common.BytesToAddress(lg.Topics[1].Bytes()), common.BytesToAddress(lg.Topics[2].Bytes())
+		if match(f, t) {
+			from, to, amount = f, t, new(big.Int).SetBytes(lg.Data)
+		}
+	}
+	return from, to, amount
+}
+
+// uniswapPositionEvent provides the ordinal of the pair event lr applied to liquidity positions.
+func uniswapPositionEvent(lr *types.LogRecord) uint64 {
+	return types.UniswapPositionEventOrdinal(uint64(lr.Block.Number), lr.Index)
+}
+
+// uniswapPositionLoad loads the liquidity position of the owner on the pair of the event lr.
+// A missing position is created only if the create flag is set. Nil is returned if there is
+// no position, or if the event, or any later one, has already been applied to it.
+func uniswapPositionLoad(lr *types.LogRecord, owner *common.Address, create bool) *types.UniswapPosition {
+	pos, err := repo.UniswapPosition(&lr.Address, owner)
+	if err != nil {
+		return nil
+	}
+
+	if pos == nil {
+		if !create {
+			return nil
+		}
+
+		pos = types.NewUniswapPosition(&lr.Address, owner)
+		pos.Index = uniswapOrdinalIndex(lr)
+		pos.Opened = lr.Block.TimeStamp
+	}
+
+	if pos.Applied(uniswapPositionEvent(lr)) {
+		return nil
+	}
+	return pos
+}
+
+// uniswapPositionStore stores the liquidity position changed by the event lr
+// and marks the event as the last one applied to the position.
+func uniswapPositionStore(lr *types.LogRecord, pos *types.UniswapPosition) {
+	pos.Updated = lr.Block.TimeStamp
+
+	ok, err := repo.UniswapStorePosition(pos, uniswapPositionEvent(lr))
+	if err != nil {
+		log.Errorf("can not update uniswap position of %s on %s; %s", pos.Owner.String(), lr.Address.String(), err.Error())
+		return
+	}
+	if !ok {
+		log.Debugf("uniswap position event #%d already applied on %s", uniswapPositionEvent(lr), pos.Pk())
+	}
+}
+
+// uniswapPositionUpdate applies the given change to the liquidity position of the owner on the pair of the event lr.
+// A missing position is created only if the create flag is set.
+func uniswapPositionUpdate(lr *types.LogRecord, owner *common.Address, create bool, change func(*types.UniswapPosition)) {
+	pos := uniswapPositionLoad(lr, owner, create)
+	if pos == nil {
+		return
+	}
+
+	change(pos)
+	uniswapPositionStore(lr, pos)
+}
+
+// uniswapPositionAdd adds the given liquidity with its cost basis to the position.
+func uniswapPositionAdd(pos *types.UniswapPosition, liquidity *big.Int, deposited []*big.Int, rootK *big.Int) {
+	pos.Liquidity = hexutil.Big(*new(big.Int).Add(pos.Liquidity.ToInt(), liquidity))
+	pos.RootK = hexutil.Big(*new(big.Int).Add(pos.RootK.ToInt(), rootK))
+	for i := range pos.Deposited {
+		pos.Deposited[i] = hexutil.Big(*new(big.Int).Add(pos.Deposited[i].ToInt(), deposited[i]))
+	}
+}
+
+// uniswapPositionReduce removes the given liquidity from the position and returns the cost basis
+// of the removed part, e.g. the proportional part of the deposited amounts and the RootK.
+func uniswapPositionReduce(pos *types.UniswapPosition, liquidity *big.Int) ([]*big.Int, *big.Int) {
+	held := pos.Liquidity.ToInt()
+	if held.Sign() <= 0 {
+		return []*big.Int{new(big.Int), new(big.Int)}, new(big.Int)
+	}
+
+	// we can not remove more than we know about
+	if liquidity.Cmp(held) > 0 {
+		liquidity = held
+	}
+
+	part := func(v *big.Int) *big.Int {
+		return new(big.Int).Div(new(big.Int).Mul(v, liquidity), held)
+	}
+
+	dep := make([]*big.Int, len(pos.Deposited))
+	for i := range pos.Deposited {
+		dep[i] = part(pos.Deposited[i].ToInt())
+		pos.Deposited[i] = hexutil.Big(*new(big.Int).Sub(pos.Deposited[i].ToInt(), dep[i]))
+	}
+
+	rk := part(pos.RootK.ToInt())
+	pos.RootK = hexutil.Big(*new(big.Int).Sub(pos.RootK.ToInt(), rk))
+	pos.Liquidity = hexutil.Big(*new(big.Int).Sub(held, liquidity))
+	return dep, rk
+}
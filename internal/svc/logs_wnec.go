@@ -0,0 +1,64 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"bytes"
+	"math/big"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// wrappedNativeToken represents the address of the native token wrapper (wNEC).
+// The address is resolved on the first wrapper event received.
+var wrappedNativeToken *common.Address
+
+// isWrappedNativeToken checks if the given address belongs to the native token wrapper.
+// The Deposit/Withdrawal signature is shared by many contracts, we track only the known wrapper.
+func isWrappedNativeToken(addr *common.Address) bool {
+	if wrappedNativeToken == nil {
+		adr, err := repo.NativeTokenAddress()
+		if err != nil {
+			log.Errorf("native token wrapper address not available; %s", err.Error())
+			return false
+		}
+		wrappedNativeToken = adr
+	}
+	return bytes.Equal(addr.Bytes(), wrappedNativeToken.Bytes())
+}
+
+// handleWrappedNativeDeposit handles native tokens wrapped into wNEC.
+// The wrapped tokens are credited to the depositor, the same way a mint does.
+// event Deposit(address indexed dst, uint256 wad)
+func handleWrappedNativeDeposit(lr *types.LogRecord) {
+	// sanity check for data (1 uint256 = 32 bytes); call + dst = 2 topics
+	if len(lr.Data) != 32 || len(lr.Topics) != 2 {
+		log.Debugf("%s not a wrapper deposit; %d data bytes, %d topics", lr.TxHash.String(), len(lr.Data), len(lr.Topics))
+		return
+	}
+	if !isWrappedNativeToken(&lr.Address) {
+		return
+	}
+
+	amount := new(big.Int).SetBytes(lr.Data)
+	storeTokenTransaction(lr, types.AccountTypeERC20Token, types.TokenTrxTypeWrap,
+		common.Address{}, common.BytesToAddress(lr.Topics[1].Bytes()), *amount, *big.NewInt(0), 0)
+}
+
+// handleWrappedNativeWithdrawal handles wNEC unwrapped back into native tokens.
+// The wrapped tokens are debited from the owner, the same way a burn does.
+// event Withdrawal(address indexed src, uint256 wad)
+func handleWrappedNativeWithdrawal(lr *types.LogRecord) {
+	// sanity check for data (1 uint256 = 32 bytes); call + src = 2 topics
+	if len(lr.Data) != 32 || len(lr.Topics) != 2 {
+		log.Debugf("%s not a wrapper withdrawal; %d data bytes, %d topics", lr.TxHash.String(), len(lr.Data), len(lr.Topics))
+		return
+	}
+	if !isWrappedNativeToken(&lr.Address) {
+		return
+	}
+
+	amount := new(big.Int).SetBytes(lr.Data)
+	storeTokenTransaction(lr, types.AccountTypeERC20Token, types.TokenTrxTypeUnwrap,
+		common.BytesToAddress(lr.Topics[1].Bytes()), common.Address{}, *amount, *big.NewInt(0), 0)
+}
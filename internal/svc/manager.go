@@ -7,6 +7,8 @@ import (
 	"ncogearthchain-api-graphql/internal/repository"
 	"ncogearthchain-api-graphql/internal/types"
 	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // ServiceManager implements service manager.
@@ -21,6 +23,8 @@ type ServiceManager struct {
 	lgd *logDispatcher
 	bls *blkScanner
 	bud *burnDispatcher
+	whd *webhookDispatcher
+	trt *trxTracker
 
 	// collection of all the managed services
 	svc []Svc
@@ -81,14 +85,33 @@ func (mgr *ServiceManager) Close() {
 	log.Notice("svc manager closed")
 }
 
-// SetBlockChannel registers a channel for notifying new block events.
-func (mgr *ServiceManager) SetBlockChannel(ch chan *types.Block) {
-	mgr.bld.onBlock = ch
+// RegisterWebhook subscribes the given stored webhook to the events of its filter.
+func (mgr *ServiceManager) RegisterWebhook(wh *types.Webhook) {
+	if mgr.whd != nil {
+		mgr.whd.add(wh)
+	}
+}
+
+// UnregisterWebhook stops delivering events to the webhook of the given id.
+func (mgr *ServiceManager) UnregisterWebhook(id string) {
+	if mgr.whd != nil {
+		mgr.whd.remove(id)
+	}
 }
 
-// SetTrxChannel registers a channel for notifying new transaction events.
-func (mgr *ServiceManager) SetTrxChannel(ch chan *types.Transaction) {
-	mgr.trd.onTransaction = ch
+// TrackTransaction starts tracking the lifecycle of the given submitted transaction.
+func (mgr *ServiceManager) TrackTransaction(trx *types.Transaction) {
+	if mgr.trt != nil {
+		mgr.trt.track(trx)
+	}
+}
+
+// TransactionStatus provides the lifecycle state of the given tracked transaction, nil if not tracked.
+func (mgr *ServiceManager) TransactionStatus(hash *common.Hash) *types.TransactionStatus {
+	if mgr.trt == nil {
+		return nil
+	}
+	return mgr.trt.status(hash)
 }
 
 // Init the svc manager.
@@ -113,6 +136,13 @@ func (mgr *ServiceManager) init() {
 	mgr.bud = &burnDispatcher{service: service{mgr: mgr}}
 	mgr.svc = append(mgr.svc, mgr.bud)
 
+	// make pending transactions dispatcher
+	mgr.svc = append(mgr.svc, &pendingDispatcher{service: service{mgr: mgr}})
+
+	// make submitted transactions tracker
+	mgr.trt = &trxTracker{service: service{mgr: mgr}}
+	mgr.svc = append(mgr.svc, mgr.trt)
+
 	// make block scanner
 	mgr.bls = &blkScanner{service: service{mgr: mgr}, cfg: cfg.RepoCommand}
 	mgr.svc = append(mgr.svc, mgr.bls)
@@ -131,6 +161,27 @@ func (mgr *ServiceManager) init() {
 	// make transaction flow monitor
 	mgr.svc = append(mgr.svc, &trxFlowMonitor{service: service{mgr: mgr}})
 
+	// make uniswap pairs discovery scanner
+	mgr.svc = append(mgr.svc, &uniswapPairScanner{service: service{mgr: mgr}})
+
+	// make uniswap pairs analytics updater
+	mgr.svc = append(mgr.svc, &uniswapAnalyticsUpdater{service: service{mgr: mgr}})
+
+	// make native token price sampler
+	mgr.svc = append(mgr.svc, &priceSampler{service: service{mgr: mgr}})
+
+	// make on-chain price sources observer
+	mgr.svc = append(mgr.svc, &priceObserver{service: service{mgr: mgr}})
+
+	// make uniswap candles builder
+	mgr.svc = append(mgr.svc, &uniswapCandlesBuilder{service: service{mgr: mgr}})
+
+	// make webhook dispatcher only if the webhooks are enabled
+	if cfg.Webhooks.Enabled {
+		mgr.whd = &webhookDispatcher{service: service{mgr: mgr}}
+		mgr.svc = append(mgr.svc, mgr.whd)
+	}
+
 	// add orchestrator as the last service, so it can safely operate on all the other
 	mgr.ora = &orchestrator{service: service{mgr: mgr}}
 	mgr.svc = append(mgr.svc, mgr.ora)
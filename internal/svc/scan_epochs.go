@@ -3,6 +3,7 @@ package svc
 
 import (
 	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
 	"ncogearthchain-api-graphql/internal/types"
 	"time"
 
@@ -18,6 +19,10 @@ const epsObserverTickerDuration = 10 * time.Second
 // epsStoreQueueLength represents the capacity of the epoch scanner store queue.
 const epsStoreQueueLength = 100
 
+// epsSealedNotifyWindow represents the max age of a sealed epoch to be broadcast to subscribers.
+// Older epochs are processed while catching up with the chain and nobody waits for them.
+const epsSealedNotifyWindow = 10 * time.Minute
+
 // epochScanner implements blockchain epochs scanner.
 // We can not scan anything before epoch #5574 (migration); full data available after #5577
 type epochScanner struct {
@@ -174,5 +179,11 @@ func (eps *epochScanner) store(ep *types.Epoch) {
 	err := repo.AddEpoch(ep)
 	if err != nil {
 		log.Errorf("can not store epoch #%d; %s", ep.Id, err.Error())
+		return
+	}
+
+	// notify subscribers about a freshly sealed epoch
+	if time.Since(time.Unix(int64(ep.EndTime), 0)) < epsSealedNotifyWindow {
+		broker.B().Publish(broker.TopicSealedEpoch, ep)
 	}
 }
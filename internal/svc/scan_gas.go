@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"ncogearthchain-api-graphql/internal/broker"
 	"ncogearthchain-api-graphql/internal/types"
 	"time"
 )
@@ -51,6 +52,9 @@ type gpsMonitor struct {
 
 	// logTicker controls the current state logging
 	logTicker *time.Ticker
+
+	// estimate represents the last extended gas price estimation published to subscribers
+	estimate *types.GasPrice
 }
 
 // name returns a human-readable name of the service used by the manager.
@@ -132,6 +136,9 @@ func (gps *gpsMonitor) read() {
 		return
 	}
 
+	// notify subscribers if the estimation changed
+	gps.publish(gs.ToInt())
+
 	// adjust to desired decimals
 	val := new(big.Int).Div(gs.ToInt(), types.TransactionGasCorrection).Int64()
 
@@ -150,6 +157,17 @@ func (gps *gpsMonitor) read() {
 	}
 }
 
+// publish sends the extended gas price estimation to subscribers if it changed since the last reading.
+func (gps *gpsMonitor) publish(wei *big.Int) {
+	gp := types.NewGasPrice(wei)
+	if gps.estimate != nil && *gps.estimate == *gp {
+		return
+	}
+
+	gps.estimate = gp
+	broker.B().Publish(broker.TopicGasPrice, gp)
+}
+
 // flip closes the current reading period and starts a new one.
 // The same function is also used to initialize the first period.
 func (gps *gpsMonitor) flip() {
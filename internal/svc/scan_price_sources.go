@@ -0,0 +1,65 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"fmt"
+	"time"
+)
+
+// priceObserverPeriod represents the period in which the on-chain price sources are observed.
+// It limits the resolution of the Uniswap TWAP window and the age of the fMint oracle price.
+const priceObserverPeriod = 30 * time.Second
+
+// priceObserver represents a service letting the configured price sources
+// observe their on-chain state independently of the price requests.
+type priceObserver struct {
+	service
+	ticker *time.Ticker
+}
+
+// name returns a human-readable name of the service used by the manager.
+func (po *priceObserver) name() string {
+	return "price sources observer"
+}
+
+// run starts the price sources observer.
+func (po *priceObserver) run() {
+	// make sure we are orchestrated
+	if po.mgr == nil {
+		panic(fmt.Errorf("no svc manager set on %s", po.name()))
+	}
+
+	// start go routine for processing
+	po.mgr.started(po)
+	go po.execute()
+}
+
+// close terminates the price sources observer.
+func (po *priceObserver) close() {
+	if po.ticker != nil {
+		po.ticker.Stop()
+	}
+	if po.sigStop != nil {
+		po.sigStop <- true
+	}
+}
+
+// execute observes the price sources periodically.
+func (po *priceObserver) execute() {
+	defer func() {
+		close(po.sigStop)
+		po.mgr.finished(po)
+	}()
+
+	po.ticker = time.NewTicker(priceObserverPeriod)
+	repo.ObservePriceSources()
+
+	for {
+		select {
+		case <-po.sigStop:
+			return
+		case <-po.ticker.C:
+			repo.ObservePriceSources()
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"fmt"
+	"time"
+)
+
+// priceSamplerPeriod represents the period in which the native token prices are sampled.
+const priceSamplerPeriod = 5 * time.Minute
+
+// priceSampler represents a service storing the native token prices
+// in all the configured symbols into the price history.
+type priceSampler struct {
+	service
+	ticker *time.Ticker
+}
+
+// name returns a human-readable name of the service used by the manager.
+func (ps *priceSampler) name() string {
+	return "price sampler"
+}
+
+// run starts the price sampler.
+func (ps *priceSampler) run() {
+	// make sure we are orchestrated
+	if ps.mgr == nil {
+		panic(fmt.Errorf("no svc manager set on %s", ps.name()))
+	}
+
+	// start go routine for processing
+	ps.mgr.started(ps)
+	go ps.execute()
+}
+
+// close terminates the price sampler.
+func (ps *priceSampler) close() {
+	if ps.ticker != nil {
+		ps.ticker.Stop()
+	}
+	if ps.sigStop != nil {
+		ps.sigStop <- true
+	}
+}
+
+// execute samples the prices periodically.
+func (ps *priceSampler) execute() {
+	defer func() {
+		close(ps.sigStop)
+		ps.mgr.finished(ps)
+	}()
+
+	ps.ticker = time.NewTicker(priceSamplerPeriod)
+	ps.sample()
+
+	for {
+		select {
+		case <-ps.sigStop:
+			return
+		case <-ps.ticker.C:
+			ps.sample()
+		}
+	}
+}
+
+// sample stores the current prices in all the configured symbols.
+func (ps *priceSampler) sample() {
+	for _, sym := range cfg.DeFi.PriceSymbols {
+		if err := repo.StorePriceSample(sym); err != nil {
+			log.Errorf("can not sample price [%s]; %s", sym, err.Error())
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"fmt"
+	"time"
+)
+
+// uniswapAnalyticsPeriod represents the period in which the Uniswap pairs analytics are updated.
+// It must stay well below the cache eviction time so the API is served from the cache.
+const uniswapAnalyticsPeriod = 5 * time.Minute
+
+// uniswapAnalyticsUpdater represents a service calculating statistics of Uniswap pairs
+// in the background, so the API can serve them from the cache.
+type uniswapAnalyticsUpdater struct {
+	service
+	ticker *time.Ticker
+}
+
+// name returns a human-readable name of the service used by the manager.
+func (uau *uniswapAnalyticsUpdater) name() string {
+	return "uniswap analytics updater"
+}
+
+// run starts the Uniswap pairs analytics updater.
+func (uau *uniswapAnalyticsUpdater) run() {
+	// make sure we are orchestrated
+	if uau.mgr == nil {
+		panic(fmt.Errorf("no svc manager set on %s", uau.name()))
+	}
+
+	// start go routine for processing
+	uau.mgr.started(uau)
+	go uau.execute()
+}
+
+// close terminates the Uniswap pairs analytics updater.
+func (uau *uniswapAnalyticsUpdater) close() {
+	if uau.ticker != nil {
+		uau.ticker.Stop()
+	}
+	if uau.sigStop != nil {
+		uau.sigStop <- true
+	}
+}
+
+// execute updates the Uniswap pairs analytics periodically.
+func (uau *uniswapAnalyticsUpdater) execute() {
+	defer func() {
+		close(uau.sigStop)
+		uau.mgr.finished(uau)
+	}()
+
+	uau.ticker = time.NewTicker(uniswapAnalyticsPeriod)
+	uau.update()
+
+	for {
+		select {
+		case <-uau.sigStop:
+			return
+		case <-uau.ticker.C:
+			uau.update()
+		}
+	}
+}
+
+// update calculates analytics of all the known Uniswap pairs.
+func (uau *uniswapAnalyticsUpdater) update() {
+	pairs, err := repo.UniswapPairs()
+	if err != nil {
+		log.Errorf("can not get the list of uniswap pairs; %s", err.Error())
+		return
+	}
+
+	for i := range pairs {
+		// terminate early if requested
+		select {
+		case <-uau.sigStop:
+			uau.sigStop <- true
+			return
+		default:
+		}
+
+		if _, err := repo.UniswapUpdatePairAnalytics(&pairs[i]); err != nil {
+			log.Errorf("can not update uniswap pair %s analytics; %s", pairs[i].String(), err.Error())
+		}
+	}
+	log.Debugf("uniswap analytics of %d pairs updated", len(pairs))
+}
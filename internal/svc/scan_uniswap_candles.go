@@ -0,0 +1,49 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import "fmt"
+
+// uniswapCandlesBuilder implements the rebuild of the Uniswap candle rollups.
+// The rollups are updated as the swaps are stored; the full rebuild runs
+// if the rollups have never been built, or if it has been requested.
+type uniswapCandlesBuilder struct {
+	service
+}
+
+// name returns the name of the service used by orchestrator.
+func (ucb *uniswapCandlesBuilder) name() string {
+	return "uniswap candles builder"
+}
+
+// init prepares the candles builder to perform its function.
+func (ucb *uniswapCandlesBuilder) init() {
+	ucb.sigStop = make(chan bool, 1)
+}
+
+// run starts the rollups rebuild.
+func (ucb *uniswapCandlesBuilder) run() {
+	// make sure we are orchestrated
+	if ucb.mgr == nil {
+		panic(fmt.Errorf("no svc manager set on %s", ucb.name()))
+	}
+
+	// signal orchestrator we started and go
+	ucb.mgr.started(ucb)
+	go ucb.execute()
+}
+
+// execute rebuilds the Uniswap candle rollups from the stored swaps.
+func (ucb *uniswapCandlesBuilder) execute() {
+	defer ucb.mgr.finished(ucb)
+
+	if repo.UniswapCandlesReady() && !cfg.RepoCommand.RebuildCandles {
+		return
+	}
+
+	log.Notice("rebuilding uniswap candles")
+	if err := repo.UniswapCandlesRebuild(); err != nil {
+		log.Criticalf("uniswap candles rebuild failed; %s", err.Error())
+		return
+	}
+	log.Notice("uniswap candles rebuild finished")
+}
@@ -0,0 +1,376 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"fmt"
+	"ncogearthchain-api-graphql/internal/types"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	etc "github.com/ethereum/go-ethereum/core/types"
+)
+
+// upsTickerDuration represents the frequency of the Uniswap pairs scanner.
+const upsTickerDuration = 1 * time.Minute
+
+// upsLogsBlockRange represents the number of blocks covered by a single event logs request.
+const upsLogsBlockRange = 10000
+
+// uniswapPairScanner implements Uniswap pairs discovery and historical events backfill.
+// Pairs created before the block scanner reached them are discovered from the factory
+// PairCreated events and their swaps and reserves are loaded from the creation block.
+// The scanner never goes past the last block processed by the transaction dispatcher;
+// events of the newer blocks are handled by the dispatcher itself.
+// Liquidity positions of backfilled pairs are rebuilt from the whole pair history once,
+// so pairs known before the positions were tracked get their positions as well.
+type uniswapPairScanner struct {
+	service
+	ticker     *time.Ticker
+	discovered uint64
+	topics     map[common.Hash]func(*types.LogRecord)
+	positions  map[common.Hash]func(*types.LogRecord) func()
+}
+
+// name returns the name of the service used by orchestrator.
+func (ups *uniswapPairScanner) name() string {
+	return "uniswap pairs scanner"
+}
+
+// init prepares the Uniswap pairs scanner to perform its function.
+func (ups *uniswapPairScanner) init() {
+	ups.sigStop = make(chan bool, 1)
+	ups.topics = map[common.Hash]func(*types.LogRecord){
+		/* UniswapPair::Swap(address indexed sender, uint256 amount0In, uint256 amount1In, uint256 amount0Out, uint256 amount1Out, address indexed to) */
+		common.HexToHash("0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822"): handleUniswapSwap,
+
+		/* UniswapPair::Mint(address indexed sender, uint256 amount0, uint256 amount1) */
+		common.HexToHash("0x4c209b5fc8ad50758f13e2e1088ba56a560dff690a1c6fef26394f4c03821c4f"): handleUniswapMint,
+
+		/* UniswapPair::Burn(address indexed sender, uint256 amount0, uint256 amount1, address indexed to) */
+		common.HexToHash("0xdccd412f0b1252819cb1fd330b93224ca42612892bb3f4f789976e6d81936496"): handleUniswapBurn,
+
+		/* UniswapPair::Sync(uint112 reserve0, uint112 reserve1) */
+		common.HexToHash("0x1c411e9a96e071241c2f21f7726b17ae89e3cab4c78be50e062b03a9fffbbad1"): handleUniswapSync,
+
+		/* UniswapPair::Transfer(address indexed from, address indexed to, uint256 value) */
+		uniswapTransferTopic: handleUniswapLiquidityTransfer,
+	}
+	ups.positions = map[common.Hash]func(*types.LogRecord) func(){
+		/* UniswapPair::Mint(address indexed sender, uint256 amount0, uint256 amount1) */
+		common.HexToHash("0x4c209b5fc8ad50758f13e2e1088ba56a560dff690a1c6fef26394f4c03821c4f"): uniswapPositionDeposit,
+
+		/* UniswapPair::Burn(address indexed sender, uint256 amount0, uint256 amount1, address indexed to) */
+		common.HexToHash("0xdccd412f0b1252819cb1fd330b93224ca42612892bb3f4f789976e6d81936496"): uniswapPositionWithdraw,
+
+		/* UniswapPair::Transfer(address indexed from, address indexed to, uint256 value) */
+		uniswapTransferTopic: uniswapPositionTransfer,
+	}
+}
+
+// run starts the Uniswap pairs scanner.
+func (ups *uniswapPairScanner) run() {
+	// make sure we are orchestrated
+	if ups.mgr == nil {
+		panic(fmt.Errorf("no svc manager set on %s", ups.name()))
+	}
+
+	// start the discovery where we left off, or at the factory deployment
+	var err error
+	ups.discovered, err = repo.UniswapLastPairBlock()
+	if err != nil {
+		log.Criticalf("can not get the last discovered uniswap pair; %s", err.Error())
+	}
+	if ups.discovered == 0 {
+		ups.discovered = cfg.DeFi.Uniswap.FactoryBlock
+	}
+
+	// signal orchestrator we started and go
+	ups.mgr.started(ups)
+	go ups.execute()
+}
+
+// close terminates the Uniswap pairs scanner.
+func (ups *uniswapPairScanner) close() {
+	if ups.ticker != nil {
+		ups.ticker.Stop()
+	}
+	if ups.sigStop != nil {
+		ups.sigStop <- true
+	}
+}
+
+// execute runs the pairs discovery and backfill periodically.
+func (ups *uniswapPairScanner) execute() {
+	// make sure to clean up
+	defer func() {
+		close(ups.sigStop)
+		ups.mgr.finished(ups)
+	}()
+
+	ups.ticker = time.NewTicker(upsTickerDuration)
+	ups.scan()
+
+	for {
+		select {
+		case <-ups.sigStop:
+			return
+		case <-ups.ticker.C:
+			ups.scan()
+		}
+	}
+}
+
+// scan discovers new pairs and loads history of pairs not yet backfilled
+// up to the last block processed by the transaction dispatcher.
+func (ups *uniswapPairScanner) scan() {
+	// the dispatcher did not process any block yet
+	head := ups.mgr.trd.blkObserver.Load()
+	if head <= 1 {
+		return
+	}
+
+	// no factory block configured; older pairs are known from the factory list
+	if ups.discovered == 0 {
+		ups.discovered = head + 1
+	}
+
+	if !ups.discover(head) {
+		return
+	}
+	if !ups.backfill(head) {
+		return
+	}
+	ups.rebuildPositions()
+}
+
+// discover scans the factory PairCreated events up to the given head.
+// It returns false if the scanner has been terminated in the meantime.
+func (ups *uniswapPairScanner) discover(head uint64) bool {
+	for ups.discovered <= head {
+		to := ups.discovered + upsLogsBlockRange - 1
+		if to > head {
+			to = head
+		}
+
+		logs, err := repo.UniswapPairCreatedLogs(ups.discovered, to)
+		if err != nil {
+			return true
+		}
+
+		for i := range logs {
+			ts, err := ups.timeStamp(logs[i].BlockNumber)
+			if err != nil {
+				return true
+			}
+
+			pi := uniswapPairInfo(&logs[i], ts)
+			if pi == nil {
+				continue
+			}
+
+			// the dispatcher already handles events of the pairs known to it
+			if isKnownUniswapPair(&pi.Pair) {
+				pi.Backfilled = true
+			}
+			addUniswapPair(pi)
+		}
+
+		ups.discovered = to + 1
+		if ups.terminated() {
+			return false
+		}
+	}
+	return true
+}
+
+// backfill loads historical events of all the discovered pairs not yet backfilled.
+// It returns false if the backfill has not been finished.
+func (ups *uniswapPairScanner) backfill(head uint64) bool {
+	list, err := repo.UniswapPairsToBackfill()
+	if err != nil {
+		return false
+	}
+
+	for _, pi := range list {
+		log.Noticef("loading uniswap pair %s history since #%d", pi.Pair.String(), pi.Block)
+
+		for from := uint64(pi.Block); from <= head; from += upsLogsBlockRange {
+			to := from + upsLogsBlockRange - 1
+			if to > head {
+				to = head
+			}
+
+			logs, err := repo.UniswapPairLogs(&pi.Pair, from, to)
+			if err != nil {
+				return false
+			}
+
+			if !ups.process(logs) {
+				return false
+			}
+
+			if ups.terminated() {
+				return false
+			}
+		}
+
+		if err := repo.UniswapPairBackfilled(&pi.Pair); err != nil {
+			return false
+		}
+		log.Noticef("uniswap pair %s history loaded", pi.Pair.String())
+	}
+	return true
+}
+
+// rebuildPositions builds liquidity positions of the backfilled pairs from their whole history.
+func (ups *uniswapPairScanner) rebuildPositions() {
+	list, err := repo.UniswapPairsToRebuildPositions()
+	if err != nil {
+		return
+	}
+
+	for _, pi := range list {
+		if !ups.rebuildPairPositions(pi) {
+			return
+		}
+	}
+}
+
+// rebuildPairPositions drops liquidity positions of the given pair and replays the pair history
+// up to the last block processed by the transaction dispatcher. Position changes dispatched
+// in the meantime are postponed and applied on top of the replayed history; events replayed
+// already are skipped by the positions themselves. It returns false if the rebuild has not been finished.
+func (ups *uniswapPairScanner) rebuildPairPositions(pi *types.UniswapPairInfo) bool {
+	log.Noticef("rebuilding uniswap pair %s liquidity positions since #%d", pi.Pair.String(), pi.Block)
+
+	uniswapPositionsLock.Lock()
+	uniswapPositionsRebuild[pi.Pair] = make([]func(), 0)
+	uniswapPositionsLock.Unlock()
+
+	// apply the postponed changes on leave; an unfinished rebuild is repeated later anyway
+	defer func() {
+		uniswapPositionsLock.Lock()
+		defer uniswapPositionsLock.Unlock()
+
+		for _, change := range uniswapPositionsRebuild[pi.Pair] {
+			change()
+		}
+		delete(uniswapPositionsRebuild, pi.Pair)
+	}()
+
+	if err := repo.UniswapDropPositions(&pi.Pair); err != nil {
+		return false
+	}
+
+	head := ups.mgr.trd.blkObserver.Load()
+	for from := uint64(pi.Block); from <= head; from += upsLogsBlockRange {
+		to := from + upsLogsBlockRange - 1
+		if to > head {
+			to = head
+		}
+
+		logs, err := repo.UniswapPairLogs(&pi.Pair, from, to)
+		if err != nil {
+			return false
+		}
+
+		if !ups.replay(logs) {
+			return false
+		}
+
+		if ups.terminated() {
+			return false
+		}
+	}
+
+	if err := repo.UniswapPairPositionsRebuilt(&pi.Pair); err != nil {
+		return false
+	}
+	log.Noticef("uniswap pair %s liquidity positions rebuilt", pi.Pair.String())
+	return true
+}
+
+// process routes historical pair events to their handlers.
+func (ups *uniswapPairScanner) process(logs []etc.Log) bool {
+	return ups.dispatch(logs, func(lg *etc.Log) func(*types.LogRecord) {
+		return ups.topics[lg.Topics[0]]
+	})
+}
+
+// replay applies historical pair events to the liquidity positions of the pair being rebuilt.
+func (ups *uniswapPairScanner) replay(logs []etc.Log) bool {
+	return ups.dispatch(logs, func(lg *etc.Log) func(*types.LogRecord) {
+		change, ok := ups.positions[lg.Topics[0]]
+		if !ok {
+			return nil
+		}
+		return func(lr *types.LogRecord) {
+			uniswapPositionReplay(change(lr))
+		}
+	})
+}
+
+// dispatch routes historical pair events to the handlers provided by the given router. The logs are ordered
+// by block, so the block and its transactions are loaded only once for all the events of the block.
+func (ups *uniswapPairScanner) dispatch(logs []etc.Log, route func(*etc.Log) func(*types.LogRecord)) bool {
+	var blk *types.Block
+	var trx map[common.Hash]*types.Transaction
+
+	for i := range logs {
+		lg := &logs[i]
+		if len(lg.Topics) == 0 {
+			continue
+		}
+
+		handler := route(lg)
+		if handler == nil {
+			continue
+		}
+
+		// load the block context, if we moved to the next block
+		if blk == nil || uint64(blk.Number) != lg.BlockNumber {
+			var err error
+			bn := hexutil.Uint64(lg.BlockNumber)
+			if blk, err = repo.BlockByNumber(&bn); err != nil {
+				log.Errorf("block #%d not available; %s", bn, err.Error())
+				return false
+			}
+			trx = make(map[common.Hash]*types.Transaction)
+		}
+
+		t, ok := trx[lg.TxHash]
+		if !ok {
+			var err error
+			if t, err = repo.Transaction(&lg.TxHash); err != nil {
+				log.Errorf("transaction %s not available; %s", lg.TxHash.String(), err.Error())
+				return false
+			}
+			trx[lg.TxHash] = t
+		}
+
+		handler(&types.LogRecord{Block: blk, Trx: t, Log: *lg})
+	}
+	return true
+}
+
+// timeStamp provides the time stamp of the given block.
+func (ups *uniswapPairScanner) timeStamp(bn uint64) (hexutil.Uint64, error) {
+	blk, err := repo.BlockByNumber((*hexutil.Uint64)(&bn))
+	if err != nil {
+		log.Errorf("block #%d not available; %s", bn, err.Error())
+		return 0, err
+	}
+	return blk.TimeStamp, nil
+}
+
+// terminated checks if the scanner received the terminate signal.
+func (ups *uniswapPairScanner) terminated() bool {
+	select {
+	case <-ups.sigStop:
+		ups.sigStop <- true
+		return true
+	default:
+		return false
+	}
+}
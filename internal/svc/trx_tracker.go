@@ -0,0 +1,376 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"context"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/types"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// trxTrackerPollInterval represents the interval of the node pool check of the tracked transactions.
+	trxTrackerPollInterval = 5 * time.Second
+
+	// trxTrackerConfirmations represents the number of confirmations after which a mined transaction is final.
+	trxTrackerConfirmations = 12
+
+	// trxTrackerDropTimeout represents the time after which a transaction missing in the node pool is considered dropped.
+	trxTrackerDropTimeout = 30 * time.Minute
+
+	// trxTrackerRetention represents the time the final state of a transaction is kept available.
+	trxTrackerRetention = time.Hour
+
+	// trxTrackerCapacity represents the max number of transactions being tracked at once.
+	trxTrackerCapacity = 50000
+)
+
+// trxTracker implements lifecycle tracking of transactions submitted through the API.
+// Transactions go through submitted, pending (seen in the node pool) and mined states,
+// or they end up dropped, or replaced by another transaction of the same sender and nonce.
+// Changes of the state are published to the broker topic of the transaction.
+// The tracking is kept in memory only.
+type trxTracker struct {
+	service
+	pollTick *time.Ticker
+
+	// tracked transactions by hash, and by sender and nonce
+	mu      sync.RWMutex
+	list    map[common.Hash]*types.TransactionStatus
+	byNonce map[trxTrackerKey][]common.Hash
+	head    uint64
+}
+
+// trxTrackerKey represents the sender and nonce pair of a tracked transaction.
+type trxTrackerKey struct {
+	from  common.Address
+	nonce hexutil.Uint64
+}
+
+// name returns the name of the service used by orchestrator.
+func (trt *trxTracker) name() string {
+	return "transaction tracker"
+}
+
+// init prepares the transaction tracker to perform its function.
+func (trt *trxTracker) init() {
+	trt.sigStop = make(chan bool, 1)
+	trt.list = make(map[common.Hash]*types.TransactionStatus)
+	trt.byNonce = make(map[trxTrackerKey][]common.Hash)
+}
+
+// run starts the transaction tracker job.
+func (trt *trxTracker) run() {
+	// make sure we are orchestrated
+	if trt.mgr == nil {
+		panic(fmt.Errorf("no svc manager set on %s", trt.name()))
+	}
+
+	// signal orchestrator we started and go
+	trt.mgr.started(trt)
+	go trt.execute()
+}
+
+// close terminates the transaction tracker.
+func (trt *trxTracker) close() {
+	if trt.pollTick != nil {
+		trt.pollTick.Stop()
+	}
+	if trt.sigStop != nil {
+		trt.sigStop <- true
+	}
+}
+
+// execute follows mined blocks and transactions and checks the node pool
+// for the tracked transactions not mined yet.
+func (trt *trxTracker) execute() {
+	// the tracker is not bound to a client connection, it's never disconnected
+	blocks, err := broker.B().SubscribeWithPolicy(context.Background(), broker.TopicBlocks, broker.PolicyDrop)
+	if err != nil {
+		log.Criticalf("%s can not follow blocks; %s", trt.name(), err.Error())
+	}
+	trxs, err := broker.B().SubscribeWithPolicy(context.Background(), broker.TopicTransactions, broker.PolicyDrop)
+	if err != nil {
+		log.Criticalf("%s can not follow transactions; %s", trt.name(), err.Error())
+	}
+
+	// don't forget to sign off after we are done
+	defer func() {
+		if blocks != nil {
+			blocks.Close()
+		}
+		if trxs != nil {
+			trxs.Close()
+		}
+
+		close(trt.sigStop)
+		trt.mgr.finished(trt)
+	}()
+
+	var inBlock, inTrx <-chan interface{}
+	if blocks != nil {
+		inBlock = blocks.Events()
+	}
+	if trxs != nil {
+		inTrx = trxs.Events()
+	}
+
+	trt.pollTick = time.NewTicker(trxTrackerPollInterval)
+	for {
+		select {
+		case <-trt.sigStop:
+			return
+		case evt, ok := <-inBlock:
+			if !ok {
+				inBlock = nil
+				continue
+			}
+			if blk, is := evt.(*types.Block); is {
+				trt.confirm(uint64(blk.Number))
+			}
+		case evt, ok := <-inTrx:
+			if !ok {
+				inTrx = nil
+				continue
+			}
+			if trx, is := evt.(*types.Transaction); is {
+				trt.mined(trx)
+			}
+		case <-trt.pollTick.C:
+			trt.poll()
+		}
+	}
+}
+
+// track starts tracking of the given submitted transaction.
+func (trt *trxTracker) track(trx *types.Transaction) {
+	trt.mu.Lock()
+	defer trt.mu.Unlock()
+
+	if _, ok := trt.list[trx.Hash]; ok {
+		return
+	}
+	if len(trt.list) >= trxTrackerCapacity {
+		log.Warningf("transaction tracker is full, %s not tracked", trx.Hash.String())
+		return
+	}
+
+	now := time.Now().UTC()
+	ts := types.TransactionStatus{
+		Hash:      trx.Hash,
+		Status:    types.TransactionStatusSubmitted,
+		From:      trx.From,
+		Nonce:     trx.Nonce,
+		Submitted: now,
+		Updated:   now,
+	}
+	trt.list[trx.Hash] = &ts
+
+	key := trxTrackerKey{from: trx.From, nonce: trx.Nonce}
+	trt.byNonce[key] = append(trt.byNonce[key], trx.Hash)
+
+	// the node may have mined it already
+	if trx.BlockNumber != nil {
+		trt.setMined(&ts, trx.BlockNumber)
+	}
+	trt.publish(&ts)
+}
+
+// status provides a copy of the current state of the given tracked transaction, nil if not tracked.
+func (trt *trxTracker) status(hash *common.Hash) *types.TransactionStatus {
+	trt.mu.RLock()
+	defer trt.mu.RUnlock()
+
+	ts, ok := trt.list[*hash]
+	if !ok {
+		return nil
+	}
+	cp := *ts
+	return &cp
+}
+
+// seen marks the given transaction as observed in the node pool, if tracked.
+func (trt *trxTracker) seen(hash *common.Hash) {
+	trt.mu.Lock()
+	defer trt.mu.Unlock()
+
+	ts, ok := trt.list[*hash]
+	if !ok || ts.Status != types.TransactionStatusSubmitted {
+		return
+	}
+	trt.update(ts, types.TransactionStatusPending)
+}
+
+// mined updates the tracked transaction of the given mined transaction, and the tracked
+// transactions replaced by it, i.e. those of the same sender and nonce, but a different hash.
+func (trt *trxTracker) mined(trx *types.Transaction) {
+	trt.mu.Lock()
+	defer trt.mu.Unlock()
+
+	for _, hash := range trt.byNonce[trxTrackerKey{from: trx.From, nonce: trx.Nonce}] {
+		ts := trt.list[hash]
+		if ts.Final {
+			continue
+		}
+
+		if hash == trx.Hash {
+			if ts.Status != types.TransactionStatusMined {
+				trt.setMined(ts, trx.BlockNumber)
+				trt.publish(ts)
+			}
+			continue
+		}
+
+		rh := trx.Hash
+		ts.ReplacedBy = &rh
+		ts.BlockNumber = nil
+		ts.Confirmations = 0
+		trt.update(ts, types.TransactionStatusReplaced)
+	}
+}
+
+// confirm updates confirmations of the mined tracked transactions with the new head block.
+func (trt *trxTracker) confirm(head uint64) {
+	trt.mu.Lock()
+	defer trt.mu.Unlock()
+
+	if head > trt.head {
+		trt.head = head
+	}
+	for _, ts := range trt.list {
+		if ts.Status != types.TransactionStatusMined || ts.Final {
+			continue
+		}
+		if c := trt.confirmations(ts.BlockNumber); c != ts.Confirmations {
+			ts.Confirmations = c
+			trt.update(ts, types.TransactionStatusMined)
+		}
+	}
+}
+
+// poll checks the node for the tracked transactions not mined yet
+// and removes expired final states.
+func (trt *trxTracker) poll() {
+	trt.mu.RLock()
+	check := make([]common.Hash, 0)
+	for hash, ts := range trt.list {
+		if ts.Status == types.TransactionStatusSubmitted || ts.Status == types.TransactionStatusPending {
+			check = append(check, hash)
+		}
+	}
+	trt.mu.RUnlock()
+
+	// we don't want to hold the lock while talking to the node
+	for i := range check {
+		trt.check(&check[i])
+	}
+	trt.purge()
+}
+
+// check loads the given tracked transaction from the node and updates its state.
+// A transaction the node does not know is dropped after the drop timeout.
+func (trt *trxTracker) check(hash *common.Hash) {
+	trx, err := repo.LoadTransaction(hash)
+	if err != nil {
+		log.Debugf("tracked transaction %s not available; %s", hash.String(), err.Error())
+		return
+	}
+
+	trt.mu.Lock()
+	defer trt.mu.Unlock()
+
+	// the state may have changed while we were loading it
+	ts, ok := trt.list[*hash]
+	if !ok || (ts.Status != types.TransactionStatusSubmitted && ts.Status != types.TransactionStatusPending) {
+		return
+	}
+
+	switch {
+	case trx != nil && trx.Hash == *hash && trx.BlockNumber != nil:
+		trt.setMined(ts, trx.BlockNumber)
+		trt.publish(ts)
+	case trx != nil && trx.Hash == *hash:
+		if ts.Status == types.TransactionStatusSubmitted {
+			trt.update(ts, types.TransactionStatusPending)
+		}
+	case time.Since(ts.Submitted) > trxTrackerDropTimeout:
+		trt.update(ts, types.TransactionStatusDropped)
+	}
+}
+
+// purge removes the final states kept longer than the retention time.
+func (trt *trxTracker) purge() {
+	trt.mu.Lock()
+	defer trt.mu.Unlock()
+
+	for hash, ts := range trt.list {
+		if !ts.Final || time.Since(ts.Updated) < trxTrackerRetention {
+			continue
+		}
+
+		delete(trt.list, hash)
+		trt.forget(trxTrackerKey{from: ts.From, nonce: ts.Nonce}, hash)
+	}
+}
+
+// forget removes the given hash from the sender and nonce index.
+// The caller is responsible for holding the lock.
+func (trt *trxTracker) forget(key trxTrackerKey, hash common.Hash) {
+	list := trt.byNonce[key]
+	for i, h := range list {
+		if h == hash {
+			list = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+
+	if len(list) == 0 {
+		delete(trt.byNonce, key)
+		return
+	}
+	trt.byNonce[key] = list
+}
+
+// setMined marks the tracked transaction mined in the given block.
+// The caller is responsible for holding the lock and publishing the change.
+func (trt *trxTracker) setMined(ts *types.TransactionStatus, block *hexutil.Uint64) {
+	bn := *block
+	ts.BlockNumber = &bn
+	ts.Confirmations = trt.confirmations(&bn)
+	ts.Status = types.TransactionStatusMined
+	ts.Final = ts.Confirmations >= trxTrackerConfirmations
+	ts.Updated = time.Now().UTC()
+}
+
+// confirmations calculates the number of confirmations of the given block
+// against the last known head; the block itself is the first confirmation.
+func (trt *trxTracker) confirmations(block *hexutil.Uint64) uint64 {
+	if block == nil || uint64(*block) > trt.head {
+		return 1
+	}
+	return trt.head - uint64(*block) + 1
+}
+
+// update sets the new state of the tracked transaction and publishes the change.
+// The caller is responsible for holding the lock.
+func (trt *trxTracker) update(ts *types.TransactionStatus, status string) {
+	ts.Status = status
+	ts.Final = status == types.TransactionStatusDropped ||
+		status == types.TransactionStatusReplaced ||
+		(status == types.TransactionStatusMined && ts.Confirmations >= trxTrackerConfirmations)
+	ts.Updated = time.Now().UTC()
+	trt.publish(ts)
+}
+
+// publish sends a copy of the tracked transaction state to its broker topic.
+func (trt *trxTracker) publish(ts *types.TransactionStatus) {
+	log.Debugf("transaction %s is %s", ts.Hash.String(), ts.Status)
+
+	cp := *ts
+	broker.B().Publish(broker.TopicTransactionStatus(&cp.Hash), &cp)
+}
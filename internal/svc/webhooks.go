@@ -0,0 +1,562 @@
+// Package svc implements blockchain data processing services.
+package svc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"ncogearthchain-api-graphql/internal/broker"
+	"ncogearthchain-api-graphql/internal/types"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// webhookWorkers represents the number of parallel delivery workers.
+	webhookWorkers = 4
+
+	// webhookQueueLength represents the capacity of the delivery queue.
+	webhookQueueLength = 1000
+
+	// webhookRequestTimeout represents the max duration of a single delivery attempt.
+	webhookRequestTimeout = 10 * time.Second
+
+	// webhookRetryTickerInterval represents the interval of the pending deliveries check.
+	webhookRetryTickerInterval = 5 * time.Second
+
+	// webhookPurgeTickerInterval represents the interval of the finished deliveries removal.
+	webhookPurgeTickerInterval = time.Hour
+
+	// webhookRetryBatchSize represents the max number of pending deliveries loaded at once.
+	webhookRetryBatchSize = 100
+
+	// webhookRetryBaseDelay represents the delay after the first failed attempt;
+	// the delay doubles with each failed attempt.
+	webhookRetryBaseDelay = 10 * time.Second
+
+	// webhookRetryMaxDelay represents the max delay between two delivery attempts.
+	webhookRetryMaxDelay = time.Hour
+
+	// webhookMaxAttempts represents the number of failed attempts after which
+	// the delivery is moved to the dead letter list.
+	webhookMaxAttempts = 8
+
+	// WebhookSignatureHeader is the HTTP header carrying the HMAC-SHA256 signature of the delivery body.
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookDispatcher implements delivery of events to registered webhooks.
+// The events are received from the broker the same way subscriptions do,
+// deliveries are persisted, so failed attempts survive restarts. The events are
+// only persisted on receiving, so a slow target never holds the broker subscription;
+// the retry ticker picks the due deliveries and queues them for the workers.
+// Finished deliveries are removed once they get older than the configured retention.
+type webhookDispatcher struct {
+	service
+	client    *http.Client
+	retryTick *time.Ticker
+	purgeTick *time.Ticker
+	queue     chan *types.WebhookDelivery
+	done      chan struct{}
+
+	// registered webhooks by id and deliveries being processed
+	mu       sync.Mutex
+	hooks    map[string]*webhookSubscription
+	inFlight map[string]bool
+}
+
+// webhookSubscription represents a registered webhook subscribed to its broker topic.
+type webhookSubscription struct {
+	hook *types.Webhook
+	sub  *broker.Subscription
+
+	// dropped is the number of broker events lost by the subscription already reported
+	dropped uint64
+}
+
+// webhookPayload represents the JSON body of a webhook delivery.
+type webhookPayload struct {
+	Id      string      `json:"id"`
+	Hook    string      `json:"hook"`
+	Event   string      `json:"event"`
+	Created time.Time   `json:"created"`
+	Data    interface{} `json:"data"`
+}
+
+// name returns the name of the service used by orchestrator.
+func (whd *webhookDispatcher) name() string {
+	return "webhook dispatcher"
+}
+
+// init prepares the webhook dispatcher to perform its function.
+func (whd *webhookDispatcher) init() {
+	whd.sigStop = make(chan bool, 1)
+	whd.done = make(chan struct{})
+	whd.queue = make(chan *types.WebhookDelivery, webhookQueueLength)
+	whd.hooks = make(map[string]*webhookSubscription)
+	whd.inFlight = make(map[string]bool)
+	whd.client = newWebhookClient(cfg.Webhooks.AllowPrivate)
+}
+
+// newWebhookClient creates the HTTP client used to deliver webhook events.
+// Redirects are not followed and connections to private addresses are refused
+// on dialing, unless allowed, so a target can not be re-pointed to internal services
+// after the registration by a redirect, or by changing its DNS records.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := net.Dialer{Timeout: webhookRequestTimeout}
+	if !allowPrivate {
+		dialer.Control = webhookDialControl
+	}
+
+	return &http.Client{
+		Timeout: webhookRequestTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookRequestTimeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// webhookDialControl refuses connections to addresses webhooks are not allowed to reach.
+func webhookDialControl(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicWebhookIP(ip) {
+		return fmt.Errorf("webhook target %s is not a public address", host)
+	}
+	return nil
+}
+
+// IsPublicWebhookIP checks if the webhook target IP address is allowed without
+// the private targets being enabled; loopback, private, link-local and unspecified
+// addresses are refused.
+func IsPublicWebhookIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified())
+}
+
+// run loads the registered webhooks and starts the dispatcher threads.
+func (whd *webhookDispatcher) run() {
+	// make sure we are orchestrated
+	if whd.mgr == nil {
+		panic(fmt.Errorf("no svc manager set on %s", whd.name()))
+	}
+
+	// subscribe the registered webhooks
+	list, err := repo.Webhooks()
+	if err != nil {
+		log.Criticalf("can not load webhooks; %s", err.Error())
+	}
+	for _, wh := range list {
+		whd.add(wh)
+	}
+
+	for i := 0; i < webhookWorkers; i++ {
+		whd.mgr.started(whd)
+		go whd.work()
+	}
+
+	whd.mgr.started(whd)
+	go whd.execute()
+}
+
+// close terminates the webhook dispatcher.
+func (whd *webhookDispatcher) close() {
+	if whd.retryTick != nil {
+		whd.retryTick.Stop()
+	}
+	if whd.purgeTick != nil {
+		whd.purgeTick.Stop()
+	}
+	if whd.sigStop != nil {
+		whd.sigStop <- true
+	}
+}
+
+// execute schedules the pending deliveries to be retried.
+func (whd *webhookDispatcher) execute() {
+	defer func() {
+		close(whd.done)
+		whd.removeAll()
+
+		close(whd.sigStop)
+		whd.mgr.finished(whd)
+	}()
+
+	whd.retryTick = time.NewTicker(webhookRetryTickerInterval)
+	whd.purgeTick = time.NewTicker(webhookPurgeTickerInterval)
+	for {
+		select {
+		case <-whd.sigStop:
+			return
+		case <-whd.retryTick.C:
+			whd.reportDropped()
+			whd.retry()
+		case <-whd.purgeTick.C:
+			whd.purge()
+		}
+	}
+}
+
+// work performs the queued deliveries.
+func (whd *webhookDispatcher) work() {
+	defer whd.mgr.finished(whd)
+
+	for {
+		select {
+		case <-whd.done:
+			return
+		case wd := <-whd.queue:
+			whd.deliver(wd)
+		}
+	}
+}
+
+// add subscribes the given webhook to the broker topic of its filter.
+func (whd *webhookDispatcher) add(wh *types.Webhook) {
+	topic, err := webhookTopic(&wh.Filter)
+	if err != nil {
+		log.Errorf("webhook %s not subscribed; %s", wh.Id, err.Error())
+		return
+	}
+
+	whd.mu.Lock()
+	defer whd.mu.Unlock()
+
+	if _, ok := whd.hooks[wh.Id]; ok {
+		return
+	}
+
+	// webhooks are never disconnected, they are not bound to a client connection;
+	// events not passing the webhook filter are skipped by the broker
+	sub, err := broker.B().SubscribeFilteredWithPolicy(context.Background(), topic, broker.PolicyDrop, func(evt interface{}) bool {
+		_, ok := webhookEventData(&wh.Filter, evt)
+		return ok
+	})
+	if err != nil {
+		log.Errorf("webhook %s not subscribed; %s", wh.Id, err.Error())
+		return
+	}
+
+	ws := webhookSubscription{hook: wh, sub: sub}
+	whd.hooks[wh.Id] = &ws
+
+	go whd.forward(&ws)
+	log.Noticef("webhook %s subscribed to %s", wh.Id, topic)
+}
+
+// remove unsubscribes the webhook of the given id.
+func (whd *webhookDispatcher) remove(id string) {
+	whd.mu.Lock()
+	defer whd.mu.Unlock()
+
+	if ws, ok := whd.hooks[id]; ok {
+		ws.sub.Close()
+		delete(whd.hooks, id)
+		log.Noticef("webhook %s unsubscribed", id)
+	}
+}
+
+// removeAll unsubscribes all the registered webhooks.
+func (whd *webhookDispatcher) removeAll() {
+	whd.mu.Lock()
+	defer whd.mu.Unlock()
+
+	for id, ws := range whd.hooks {
+		ws.sub.Close()
+		delete(whd.hooks, id)
+	}
+}
+
+// hook provides the registered webhook of the given id, nil if not registered.
+func (whd *webhookDispatcher) hook(id string) *types.Webhook {
+	whd.mu.Lock()
+	defer whd.mu.Unlock()
+
+	if ws, ok := whd.hooks[id]; ok {
+		return ws.hook
+	}
+	return nil
+}
+
+// forward creates deliveries for events received by the webhook subscription.
+// The loop ends when the webhook is unsubscribed and the events channel is closed.
+func (whd *webhookDispatcher) forward(ws *webhookSubscription) {
+	for evt := range ws.sub.Events() {
+		data, ok := webhookEventData(&ws.hook.Filter, evt)
+		if !ok {
+			continue
+		}
+		whd.dispatch(ws.hook, data)
+	}
+}
+
+// dispatch creates a new pending delivery of the event data to the webhook.
+// The delivery is due immediately, so the next retry tick queues it for the workers.
+func (whd *webhookDispatcher) dispatch(wh *types.Webhook, data interface{}) {
+	id, err := webhookDeliveryId()
+	if err != nil {
+		log.Errorf("can not create delivery of webhook %s; %s", wh.Id, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	body, err := json.Marshal(webhookPayload{Id: id, Hook: wh.Id, Event: wh.Filter.Type, Created: now, Data: data})
+	if err != nil {
+		log.Errorf("can not encode delivery of webhook %s; %s", wh.Id, err.Error())
+		return
+	}
+
+	wd := types.WebhookDelivery{
+		Id:          id,
+		Hook:        wh.Id,
+		Event:       wh.Filter.Type,
+		Payload:     string(body),
+		Status:      types.WebhookDeliveryPending,
+		Created:     now,
+		NextAttempt: now,
+	}
+	if err := repo.StoreWebhookDelivery(&wd); err != nil {
+		log.Errorf("can not store delivery of webhook %s; %s", wh.Id, err.Error())
+	}
+}
+
+// enqueue sends the delivery to workers, unless the delivery is already being processed.
+// The call does not block; false is returned if the queue is full.
+func (whd *webhookDispatcher) enqueue(wd *types.WebhookDelivery) bool {
+	whd.mu.Lock()
+	defer whd.mu.Unlock()
+
+	if whd.inFlight[wd.Id] {
+		return true
+	}
+
+	select {
+	case whd.queue <- wd:
+		whd.inFlight[wd.Id] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// retry loads the pending deliveries due to be attempted and queues them.
+// Deliveries not fitting into the queue stay pending for the next tick.
+func (whd *webhookDispatcher) retry() {
+	list, err := repo.DueWebhookDeliveries(time.Now().UTC(), webhookRetryBatchSize)
+	if err != nil {
+		log.Errorf("can not load pending webhook deliveries; %s", err.Error())
+		return
+	}
+	for i, wd := range list {
+		if !whd.enqueue(wd) {
+			log.Debugf("webhook delivery queue full, %d deliveries postponed", len(list)-i)
+			return
+		}
+	}
+}
+
+// purge removes the finished deliveries last attempted before the retention period.
+// Zero retention keeps the deliveries forever.
+func (whd *webhookDispatcher) purge() {
+	if cfg.Webhooks.Retention <= 0 {
+		return
+	}
+
+	count, err := repo.PurgeWebhookDeliveries(time.Now().UTC().Add(-cfg.Webhooks.Retention))
+	if err != nil {
+		log.Errorf("can not remove finished webhook deliveries; %s", err.Error())
+		return
+	}
+	if count > 0 {
+		log.Infof("%d finished webhook deliveries removed", count)
+	}
+}
+
+// reportDropped logs broker events lost by the webhook subscriptions since the last check.
+// Events are dropped by the broker if the webhook can not persist them fast enough.
+func (whd *webhookDispatcher) reportDropped() {
+	whd.mu.Lock()
+	defer whd.mu.Unlock()
+
+	for id, ws := range whd.hooks {
+		dropped := ws.sub.Dropped()
+		if dropped > ws.dropped {
+			log.Warningf("webhook %s lost %d events, %d in total", id, dropped-ws.dropped, dropped)
+			ws.dropped = dropped
+		}
+	}
+}
+
+// deliver performs a single delivery attempt and stores the updated delivery state.
+func (whd *webhookDispatcher) deliver(wd *types.WebhookDelivery) {
+	defer func() {
+		whd.mu.Lock()
+		delete(whd.inFlight, wd.Id)
+		whd.mu.Unlock()
+	}()
+
+	whd.attempt(whd.hook(wd.Hook), wd)
+	if err := repo.StoreWebhookDelivery(wd); err != nil {
+		log.Errorf("can not update delivery %s of webhook %s; %s", wd.Id, wd.Hook, err.Error())
+	}
+}
+
+// attempt posts the delivery to the webhook and updates the delivery state by the result.
+// Failed deliveries are retried with exponential backoff until they are moved to the dead letter list.
+// The delivery is dead if the webhook is not registered anymore.
+func (whd *webhookDispatcher) attempt(wh *types.Webhook, wd *types.WebhookDelivery) {
+	wd.Attempts++
+	wd.LastAttempt = time.Now().UTC()
+
+	if wh == nil {
+		wd.Status = types.WebhookDeliveryDead
+		wd.LastError = "webhook not registered"
+	} else {
+		wd.ResponseCode, wd.LastError = whd.post(wh, wd)
+		switch {
+		case wd.LastError == "":
+			wd.Status = types.WebhookDeliveryDelivered
+		case wd.Attempts >= webhookMaxAttempts:
+			wd.Status = types.WebhookDeliveryDead
+			log.Warningf("webhook %s delivery %s moved to dead letters; %s", wd.Hook, wd.Id, wd.LastError)
+		default:
+			wd.NextAttempt = wd.LastAttempt.Add(webhookRetryDelay(wd.Attempts))
+		}
+	}
+}
+
+// post sends the delivery to the webhook target. The status code of the response
+// and the description of the failure, if any, are returned.
+func (whd *webhookDispatcher) post(wh *types.Webhook, wd *types.WebhookDelivery) (int32, string) {
+	req, err := http.NewRequest(http.MethodPost, wh.Url, bytes.NewBufferString(wd.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Id", wh.Id)
+	req.Header.Set("X-Webhook-Delivery", wd.Id)
+	req.Header.Set("X-Webhook-Event", wd.Event)
+	req.Header.Set(WebhookSignatureHeader, WebhookSignature(wh.Secret, []byte(wd.Payload)))
+
+	res, err := whd.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			log.Debugf("can not close webhook response body; %s", err.Error())
+		}
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return int32(res.StatusCode), fmt.Sprintf("unexpected response status %s", res.Status)
+	}
+	return int32(res.StatusCode), ""
+}
+
+// WebhookSignature calculates the signature of the delivery body with the webhook secret.
+// Receivers verify the delivery by comparing the value with the signature header.
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay calculates the delay of the next attempt after the given number of failed attempts.
+func webhookRetryDelay(attempts int32) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := int32(1); i < attempts && delay < webhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookRetryMaxDelay {
+		return webhookRetryMaxDelay
+	}
+	return delay
+}
+
+// webhookDeliveryId generates a new random delivery identifier.
+func webhookDeliveryId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// webhookTopic provides the broker topic of the events matching the given webhook filter.
+func webhookTopic(f *types.WebhookFilter) (string, error) {
+	switch f.Type {
+	case types.WebhookFilterAccountActivity:
+		if f.Address == nil {
+			return "", fmt.Errorf("account address missing")
+		}
+		return broker.TopicAccountActivity(f.Address), nil
+	case types.WebhookFilterTokenTransfer:
+		return broker.TopicTokenTransfer(f.Token), nil
+	case types.WebhookFilterLogs:
+		return broker.TopicLogs, nil
+	case types.WebhookFilterEpoch:
+		return broker.TopicSealedEpoch, nil
+	}
+	return "", fmt.Errorf("unknown filter type %s", f.Type)
+}
+
+// webhookEventData checks the broker event against the webhook filter
+// and provides the data to be delivered if the event matches.
+func webhookEventData(f *types.WebhookFilter, evt interface{}) (interface{}, bool) {
+	switch ev := evt.(type) {
+	case *types.AccountActivity:
+		return ev, true
+
+	case *types.TokenTransaction:
+		if f.TokenType != nil && ev.TokenType != *f.TokenType {
+			return nil, false
+		}
+		if f.Address != nil && ev.Sender != *f.Address && ev.Recipient != *f.Address {
+			return nil, false
+		}
+		return ev, true
+
+	case *types.LogRecord:
+		if f.Address != nil && ev.Address != *f.Address {
+			return nil, false
+		}
+		if len(f.Topics) > 0 && (len(ev.Topics) == 0 || !webhookTopicAccepted(f.Topics, ev.Topics[0])) {
+			return nil, false
+		}
+		return &ev.Log, true
+
+	case *types.Epoch:
+		return ev, true
+	}
+	return nil, false
+}
+
+// webhookTopicAccepted checks if the log event topic is on the list of accepted topics.
+func webhookTopicAccepted(list []common.Hash, topic common.Hash) bool {
+	for _, t := range list {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
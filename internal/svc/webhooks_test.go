@@ -0,0 +1,160 @@
+package svc
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"ncogearthchain-api-graphql/internal/config"
+	"ncogearthchain-api-graphql/internal/logger"
+	"ncogearthchain-api-graphql/internal/types"
+
+	"github.com/onsi/gomega"
+)
+
+func init() {
+	log = logger.New(&config.Config{Log: config.Log{Level: "CRITICAL", Format: "%{message}"}})
+}
+
+// testWebhookDispatcher creates a webhook dispatcher able to reach local test targets.
+func testWebhookDispatcher() *webhookDispatcher {
+	return &webhookDispatcher{client: newWebhookClient(true)}
+}
+
+// testWebhookDelivery creates a new pending delivery for the given webhook.
+func testWebhookDelivery(wh *types.Webhook) *types.WebhookDelivery {
+	return &types.WebhookDelivery{
+		Id:      "d1",
+		Hook:    wh.Id,
+		Event:   wh.Filter.Type,
+		Payload: `{"id":"d1"}`,
+		Status:  types.WebhookDeliveryPending,
+	}
+}
+
+func TestWebhookDeliverySigned(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var sig, hook, delivery string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sig = r.Header.Get(WebhookSignatureHeader)
+		hook = r.Header.Get("X-Webhook-Id")
+		delivery = r.Header.Get("X-Webhook-Delivery")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	wh := types.Webhook{Id: "h1", Url: srv.URL, Secret: "secret", Filter: types.WebhookFilter{Type: types.WebhookFilterEpoch}}
+	wd := testWebhookDelivery(&wh)
+	testWebhookDispatcher().attempt(&wh, wd)
+
+	g.Expect(wd.Status).To(gomega.Equal(types.WebhookDeliveryDelivered))
+	g.Expect(wd.Attempts).To(gomega.Equal(int32(1)))
+	g.Expect(wd.ResponseCode).To(gomega.Equal(int32(http.StatusNoContent)))
+	g.Expect(wd.LastError).To(gomega.BeEmpty())
+
+	// the receiver can verify the body by the shared secret
+	g.Expect(string(body)).To(gomega.Equal(wd.Payload))
+	g.Expect(sig).To(gomega.Equal(WebhookSignature("secret", body)))
+	g.Expect(sig).ToNot(gomega.Equal(WebhookSignature("other", body)))
+	g.Expect(hook).To(gomega.Equal("h1"))
+	g.Expect(delivery).To(gomega.Equal("d1"))
+}
+
+func TestWebhookDeliveryRetryAndDeadLetter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	wh := types.Webhook{Id: "h1", Url: srv.URL, Secret: "secret", Filter: types.WebhookFilter{Type: types.WebhookFilterEpoch}}
+	wd := testWebhookDelivery(&wh)
+	whd := testWebhookDispatcher()
+
+	// failed attempts stay pending with exponential backoff
+	for i := int32(1); i < webhookMaxAttempts; i++ {
+		whd.attempt(&wh, wd)
+		g.Expect(wd.Status).To(gomega.Equal(types.WebhookDeliveryPending))
+		g.Expect(wd.Attempts).To(gomega.Equal(i))
+		g.Expect(wd.ResponseCode).To(gomega.Equal(int32(http.StatusInternalServerError)))
+		g.Expect(wd.LastError).ToNot(gomega.BeEmpty())
+		g.Expect(wd.NextAttempt.Sub(wd.LastAttempt)).To(gomega.Equal(webhookRetryDelay(i)))
+	}
+
+	// the last failed attempt moves the delivery to the dead letters
+	whd.attempt(&wh, wd)
+	g.Expect(wd.Status).To(gomega.Equal(types.WebhookDeliveryDead))
+	g.Expect(wd.Attempts).To(gomega.Equal(int32(webhookMaxAttempts)))
+	g.Expect(calls.Load()).To(gomega.Equal(int32(webhookMaxAttempts)))
+
+	// deliveries of unregistered webhooks are dead right away
+	wd = testWebhookDelivery(&wh)
+	whd.attempt(nil, wd)
+	g.Expect(wd.Status).To(gomega.Equal(types.WebhookDeliveryDead))
+	g.Expect(calls.Load()).To(gomega.Equal(int32(webhookMaxAttempts)))
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(webhookRetryDelay(1)).To(gomega.Equal(webhookRetryBaseDelay))
+	g.Expect(webhookRetryDelay(2)).To(gomega.Equal(2 * webhookRetryBaseDelay))
+	g.Expect(webhookRetryDelay(4)).To(gomega.Equal(8 * webhookRetryBaseDelay))
+	g.Expect(webhookRetryDelay(100)).To(gomega.Equal(webhookRetryMaxDelay))
+}
+
+func TestWebhookRedirectNotFollowed(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var reached atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached.Store(true)
+	}))
+	defer target.Close()
+
+	srv := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer srv.Close()
+
+	wh := types.Webhook{Id: "h1", Url: srv.URL, Filter: types.WebhookFilter{Type: types.WebhookFilterEpoch}}
+	wd := testWebhookDelivery(&wh)
+	testWebhookDispatcher().attempt(&wh, wd)
+
+	g.Expect(reached.Load()).To(gomega.BeFalse())
+	g.Expect(wd.Status).To(gomega.Equal(types.WebhookDeliveryPending))
+	g.Expect(wd.ResponseCode).To(gomega.Equal(int32(http.StatusTemporaryRedirect)))
+}
+
+func TestWebhookPrivateTargetRefused(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var reached atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached.Store(true)
+	}))
+	defer srv.Close()
+
+	wh := types.Webhook{Id: "h1", Url: srv.URL, Filter: types.WebhookFilter{Type: types.WebhookFilterEpoch}}
+	wd := testWebhookDelivery(&wh)
+	whd := webhookDispatcher{client: newWebhookClient(false)}
+	whd.attempt(&wh, wd)
+
+	g.Expect(reached.Load()).To(gomega.BeFalse())
+	g.Expect(wd.ResponseCode).To(gomega.BeZero())
+	g.Expect(wd.LastError).To(gomega.ContainSubstring("not a public address"))
+
+	g.Expect(IsPublicWebhookIP(net.ParseIP("8.8.8.8"))).To(gomega.BeTrue())
+	g.Expect(IsPublicWebhookIP(net.ParseIP("127.0.0.1"))).To(gomega.BeFalse())
+	g.Expect(IsPublicWebhookIP(net.ParseIP("10.1.2.3"))).To(gomega.BeFalse())
+	g.Expect(IsPublicWebhookIP(net.ParseIP("169.254.169.254"))).To(gomega.BeFalse())
+	g.Expect(IsPublicWebhookIP(net.ParseIP("::1"))).To(gomega.BeFalse())
+	g.Expect(IsPublicWebhookIP(net.ParseIP("0.0.0.0"))).To(gomega.BeFalse())
+}
@@ -0,0 +1,41 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// account activity types
+const (
+	AccountActivityTransaction   = "TRANSACTION"
+	AccountActivityTokenTransfer = "TOKEN_TRANSFER"
+	AccountActivityDelegation    = "DELEGATION"
+	AccountActivityUndelegation  = "UNDELEGATION"
+	AccountActivityWithdrawal    = "WITHDRAWAL"
+	AccountActivityRewardClaim   = "REWARD_CLAIM"
+)
+
+// AccountActivity represents a single on-chain event involving an account.
+type AccountActivity struct {
+	// Address represents the address of the account involved.
+	Address common.Address `json:"address"`
+
+	// Type represents the type of the activity.
+	Type string `json:"type"`
+
+	// Transaction represents the hash of the transaction the activity comes from.
+	Transaction common.Hash `json:"trx"`
+
+	// TimeStamp represents the time of the block of the activity.
+	TimeStamp hexutil.Uint64 `json:"ts"`
+
+	// Amount represents the amount of the activity, if relevant.
+	Amount *hexutil.Big `json:"amount,omitempty"`
+
+	// ValidatorId represents the validator of a staking activity, if relevant.
+	ValidatorId *hexutil.Big `json:"validator,omitempty"`
+
+	// TokenTransaction represents the token transfer of the activity, if relevant.
+	TokenTransaction *TokenTransaction `json:"tokenTransaction,omitempty"`
+}
@@ -0,0 +1,31 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// EpochValidatorRewards represents the summary of rewards distributed
+// to a validator and its delegators in a sealed epoch.
+type EpochValidatorRewards struct {
+	// ValidatorId represents the id of the validator.
+	ValidatorId hexutil.Big
+
+	// ReceivedStake represents the total stake of the validator in the epoch.
+	ReceivedStake hexutil.Big
+
+	// RewardPerToken represents the reward distributed per staked token in the epoch.
+	// The value is scaled by 1e18.
+	RewardPerToken hexutil.Big
+
+	// DelegatorRewards represents the amount of rewards distributed to the validator
+	// delegations, i.e. the received stake times the reward per token. The validator
+	// commission is not distributed per token and is not included.
+	DelegatorRewards hexutil.Big
+
+	// OriginatedFee represents the fee of transactions originated by the validator in the epoch.
+	OriginatedFee hexutil.Big
+
+	// Uptime represents the time the validator was online in the epoch in seconds.
+	Uptime hexutil.Uint64
+}
@@ -36,6 +36,12 @@ const (
 
 	// TokenTrxTypeApprovalForAll represents universal token transfer approval.
 	TokenTrxTypeApprovalForAll = 5
+
+	// TokenTrxTypeWrap represents native tokens wrapped into the wrapper token.
+	TokenTrxTypeWrap = 6
+
+	// TokenTrxTypeUnwrap represents wrapper tokens unwrapped back into native tokens.
+	TokenTrxTypeUnwrap = 7
 )
 
 // TokenTransaction represents an operation with ERC20 token.
@@ -2,6 +2,8 @@
 package types
 
 import (
+	"math"
+	"math/big"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -48,6 +50,18 @@ type GasPrice struct {
 	Average float64 `json:"average"`
 }
 
+// NewGasPrice builds the extended gas price estimation from the given gas price in WEI.
+func NewGasPrice(wei *big.Int) *GasPrice {
+	// calculate the gas price in Gwei units
+	gWei := math.Round(float64(wei.Int64())/float64(10000000)) / 10.0
+	return &GasPrice{
+		Fast:    gWei,
+		Fastest: gWei,
+		SafeLow: gWei,
+		Average: gWei,
+	}
+}
+
 // GasPricePeriod represents a data set of interval of gas price
 // estimation provided by the Ncogearthchain node.
 type GasPricePeriod struct {
@@ -0,0 +1,83 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// portfolio line item types
+const (
+	PortfolioItemNative     = "NATIVE"
+	PortfolioItemDelegation = "DELEGATION"
+	PortfolioItemRewards    = "REWARDS"
+	PortfolioItemErc20      = "ERC20"
+	PortfolioItemFMint      = "FMINT"
+	PortfolioItemFLend      = "FLEND"
+	PortfolioItemLiquidity  = "LIQUIDITY"
+)
+
+// Portfolio represents the valuation of all the holdings of an account
+// in a target currency at the given block.
+type Portfolio struct {
+	// Address represents the address of the account.
+	Address common.Address `json:"address"`
+
+	// Currency represents the target currency symbol of the valuation.
+	Currency string `json:"currency"`
+
+	// Block represents the number of the block the valuation was calculated at.
+	Block hexutil.Uint64 `json:"block"`
+
+	// Items represents the list of the portfolio line items.
+	Items []PortfolioItem `json:"items"`
+
+	// Total represents the total value of the priced items in the target currency.
+	Total float64 `json:"total"`
+}
+
+// PortfolioItem represents a single line item of an account portfolio.
+type PortfolioItem struct {
+	// Type represents the type of the item.
+	Type string `json:"type"`
+
+	// Token represents the address of the token held; it's empty for the native token.
+	Token common.Address `json:"token"`
+
+	// Symbol represents the symbol of the token held.
+	Symbol string `json:"symbol"`
+
+	// Reference represents the identification of the holding, e.g. the validator
+	// of a delegation, or the liquidity pair of a liquidity position.
+	Reference string `json:"ref"`
+
+	// Amount represents the amount of the token held. Debt based holdings
+	// represent the net value in fUSD.
+	Amount hexutil.Big `json:"amount"`
+
+	// Decimals represents the number of decimals of the amount.
+	Decimals int32 `json:"decimals"`
+
+	// Price represents the price of a single token in the target currency.
+	Price float64 `json:"price"`
+
+	// Value represents the value of the item in the target currency.
+	Value float64 `json:"value"`
+
+	// IsPriced signals the value of the item could be calculated.
+	IsPriced bool `json:"priced"`
+}
+
+// UnmarshalPortfolio parses the JSON-encoded portfolio data.
+func UnmarshalPortfolio(data []byte) (*Portfolio, error) {
+	var pf Portfolio
+	err := json.Unmarshal(data, &pf)
+	return &pf, err
+}
+
+// Marshal returns the JSON encoding of portfolio.
+func (pf *Portfolio) Marshal() ([]byte, error) {
+	return json.Marshal(pf)
+}
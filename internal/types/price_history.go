@@ -0,0 +1,35 @@
+// Package types implements different core types of the API.
+package types
+
+import "time"
+
+const (
+	// FiPriceTickSymbol is the name of the target symbol column in the price history collections.
+	FiPriceTickSymbol = "sym"
+
+	// FiPriceTickTime is the name of the time stamp column in the price history collections.
+	FiPriceTickTime = "ts"
+)
+
+// PriceTick represents a price of the native token in a target symbol
+// over a period of time. A single price sample has all the prices equal.
+type PriceTick struct {
+	Symbol string    `json:"sym" bson:"sym"`
+	Time   time.Time `json:"ts" bson:"ts"`
+	Open   float64   `json:"open" bson:"open"`
+	High   float64   `json:"high" bson:"high"`
+	Low    float64   `json:"low" bson:"low"`
+	Close  float64   `json:"close" bson:"close"`
+}
+
+// NewPriceSample creates a price tick of a single price sample.
+func NewPriceSample(sym string, ts time.Time, price float64) *PriceTick {
+	return &PriceTick{
+		Symbol: sym,
+		Time:   ts,
+		Open:   price,
+		High:   price,
+		Low:    price,
+		Close:  price,
+	}
+}
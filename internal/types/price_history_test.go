@@ -0,0 +1,39 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPriceTickBSON(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	pt := PriceTick{
+		Symbol: "USD",
+		Time:   time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC),
+		Open:   0.125,
+		High:   0.25,
+		Low:    0.0625,
+		Close:  0.1875,
+	}
+
+	data, err := bson.Marshal(&pt)
+	g.Expect(err).To(gomega.BeNil())
+
+	var raw bson.M
+	g.Expect(bson.Unmarshal(data, &raw)).To(gomega.Succeed())
+	g.Expect(raw).To(gomega.HaveKeyWithValue(FiPriceTickSymbol, "USD"))
+	g.Expect(raw).To(gomega.HaveKey(FiPriceTickTime))
+
+	var back PriceTick
+	g.Expect(bson.Unmarshal(data, &back)).To(gomega.Succeed())
+	g.Expect(back.Symbol).To(gomega.Equal(pt.Symbol))
+	g.Expect(back.Time.Equal(pt.Time)).To(gomega.BeTrue())
+	g.Expect(back.Open).To(gomega.Equal(pt.Open))
+	g.Expect(back.High).To(gomega.Equal(pt.High))
+	g.Expect(back.Low).To(gomega.Equal(pt.Low))
+	g.Expect(back.Close).To(gomega.Equal(pt.Close))
+}
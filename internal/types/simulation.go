@@ -0,0 +1,115 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// simulated balance change asset types
+const (
+	AssetNative  = "NATIVE"
+	AssetERC20   = "ERC20"
+	AssetERC721  = "ERC721"
+	AssetERC1155 = "ERC1155"
+)
+
+// SimulationCall represents a call executed by the transaction simulation.
+type SimulationCall struct {
+	From  common.Address  `json:"from"`
+	To    *common.Address `json:"to,omitempty"`
+	Value *hexutil.Big    `json:"value,omitempty"`
+	Data  hexutil.Bytes   `json:"data,omitempty"`
+	Gas   *hexutil.Uint64 `json:"gas,omitempty"`
+}
+
+// StateOverride represents an override of an account state applied to the simulation only.
+// The State replaces the whole account storage, the StateDiff replaces the given slots only.
+type StateOverride struct {
+	Balance   *hexutil.Big                `json:"balance,omitempty"`
+	Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code      *hexutil.Bytes              `json:"code,omitempty"`
+	State     map[common.Hash]common.Hash `json:"state,omitempty"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+}
+
+// DecodedParam represents a single value decoded by the contract ABI.
+type DecodedParam struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// SimulationLog represents an event log emitted by the simulated call.
+type SimulationLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+
+	// Event represents the name of the event decoded by the contract ABI, if known.
+	Event string `json:"-"`
+
+	// Params represents the event values decoded by the contract ABI.
+	Params []DecodedParam `json:"-"`
+}
+
+// NativeTransfer represents a transfer of native tokens made by the simulated call.
+type NativeTransfer struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value hexutil.Big    `json:"value"`
+}
+
+// BalanceChange represents the net change of an asset balance of an account caused by the simulated call.
+type BalanceChange struct {
+	// Address represents the account of the balance.
+	Address common.Address
+
+	// Asset represents the type of the asset (NATIVE/ERC20/ERC721/ERC1155).
+	Asset string
+
+	// Token represents the token contract, nil for the native token.
+	Token *common.Address
+
+	// TokenId represents the identifier of the ERC721/ERC1155 token.
+	TokenId *hexutil.Big
+
+	// Amount represents the signed change of the balance.
+	Amount hexutil.Big
+}
+
+// TransactionSimulation represents the outcome of a simulated transaction.
+type TransactionSimulation struct {
+	// Failed indicates the call failed, or reverted.
+	Failed bool `json:"failed"`
+
+	// Error represents the failure of the call.
+	Error string `json:"error"`
+
+	// GasUsed represents the gas consumed by the call.
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+
+	// ReturnData represents the raw data returned by the call, or the revert data.
+	ReturnData hexutil.Bytes `json:"output"`
+
+	// Logs represents the logs emitted by the call; logs of reverted inner calls are excluded.
+	Logs []SimulationLog `json:"logs"`
+
+	// Transfers represents native tokens transfers made by the call, including the inner calls.
+	Transfers []NativeTransfer `json:"transfers"`
+
+	// Traced indicates the call was traced; if not, the gas used, the logs and the transfers are not known.
+	Traced bool `json:"-"`
+
+	// RevertReason represents the decoded revert reason of a reverted call.
+	RevertReason string `json:"-"`
+
+	// Method represents the name of the called contract function, if known.
+	Method string `json:"-"`
+
+	// ReturnValues represents the return data decoded by the contract ABI.
+	ReturnValues []DecodedParam `json:"-"`
+
+	// BalanceChanges represents the net changes of asset balances.
+	BalanceChanges []BalanceChange `json:"-"`
+}
@@ -2,6 +2,7 @@
 package types
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"math/big"
 
@@ -53,6 +54,12 @@ type Swap struct {
 	Reserve1 *big.Int `json:"reserve1" bson:"reserve1"`
 }
 
+// Id generates the identifier of the swap from the transaction hash and the pair address.
+func (swap *Swap) Id() common.Hash {
+	sum := sha256.Sum256(append(swap.Hash.Big().Bytes(), swap.Pair.Bytes()...))
+	return common.BytesToHash(sum[:])
+}
+
 // Marshal returns the JSON encoding of swap.
 func (swap *Swap) Marshal() ([]byte, error) {
 	return json.Marshal(swap)
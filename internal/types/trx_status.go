@@ -0,0 +1,52 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// transaction lifecycle states
+const (
+	TransactionStatusSubmitted = "SUBMITTED"
+	TransactionStatusPending   = "PENDING"
+	TransactionStatusMined     = "MINED"
+	TransactionStatusDropped   = "DROPPED"
+	TransactionStatusReplaced  = "REPLACED"
+)
+
+// TransactionStatus represents the lifecycle state of a transaction submitted through the API.
+type TransactionStatus struct {
+	// Hash represents the hash of the tracked transaction.
+	Hash common.Hash
+
+	// Status represents the lifecycle state of the transaction.
+	Status string
+
+	// From represents the sender of the transaction.
+	From common.Address
+
+	// Nonce represents the sender nonce of the transaction.
+	Nonce hexutil.Uint64
+
+	// BlockNumber represents the block the transaction was mined in, nil if not mined.
+	BlockNumber *hexutil.Uint64
+
+	// Confirmations represents the number of blocks since the transaction was mined, including its own block.
+	Confirmations uint64
+
+	// ReplacedBy represents the hash of the transaction mined with the same sender and nonce instead.
+	ReplacedBy *common.Hash
+
+	// Submitted represents the time the transaction was submitted.
+	Submitted time.Time
+
+	// Updated represents the time of the last change of the state.
+	Updated time.Time
+
+	// Final marks the state which will not change anymore; mined transactions
+	// are final once they have enough confirmations.
+	Final bool
+}
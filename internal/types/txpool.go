@@ -0,0 +1,13 @@
+// Package types implements different core types of the API.
+package types
+
+import "github.com/ethereum/go-ethereum/common/hexutil"
+
+// TxPoolStatus represents the number of transactions waiting in the node pool.
+type TxPoolStatus struct {
+	// Pending represents the number of transactions ready to be processed.
+	Pending hexutil.Uint64 `json:"pending"`
+
+	// Queued represents the number of transactions waiting for a nonce gap to close.
+	Queued hexutil.Uint64 `json:"queued"`
+}
@@ -0,0 +1,123 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// FiUniswapPairAddress is the name of the pair address column in the collection.
+	FiUniswapPairAddress = "_id"
+
+	// FiUniswapPairBlock is the name of the creation block column in the collection.
+	FiUniswapPairBlock = "blk"
+
+	// FiUniswapPairBackfilled is the name of the backfill state column in the collection.
+	FiUniswapPairBackfilled = "bf"
+
+	// FiUniswapPairPositions is the name of the liquidity positions state column in the collection.
+	FiUniswapPairPositions = "pos"
+)
+
+// UniswapPairInfo represents a Uniswap pair discovered from the factory PairCreated event.
+type UniswapPairInfo struct {
+	// Pair represents the address of the pair contract.
+	Pair common.Address `json:"pair"`
+
+	// Index represents the index of the pair in the factory list of all pairs.
+	Index uint64 `json:"idx"`
+
+	// Token0 represents the address of the first token of the pair.
+	Token0 common.Address `json:"token0"`
+
+	// Token1 represents the address of the second token of the pair.
+	Token1 common.Address `json:"token1"`
+
+	// Token0Symbol represents the symbol of the first token, if available.
+	Token0Symbol string `json:"token0Symbol"`
+
+	// Token1Symbol represents the symbol of the second token, if available.
+	Token1Symbol string `json:"token1Symbol"`
+
+	// Token0Decimals represents the decimals of the first token, if available.
+	Token0Decimals int32 `json:"token0Decimals"`
+
+	// Token1Decimals represents the decimals of the second token, if available.
+	Token1Decimals int32 `json:"token1Decimals"`
+
+	// Block represents the number of the block where the pair was created.
+	Block hexutil.Uint64 `json:"block"`
+
+	// TimeStamp represents the time stamp of the block where the pair was created.
+	TimeStamp hexutil.Uint64 `json:"ts"`
+
+	// Trx represents the hash of the transaction creating the pair.
+	Trx common.Hash `json:"trx"`
+
+	// Backfilled indicates the historical pair events have already been loaded.
+	Backfilled bool `json:"bf"`
+
+	// Positions indicates the liquidity positions of the pair have been built from its whole history.
+	Positions bool `json:"pos"`
+}
+
+// BsonUniswapPairInfo represents the BSON form of a discovered Uniswap pair.
+type BsonUniswapPairInfo struct {
+	ID         string `bson:"_id"`
+	Index      int64  `bson:"idx"`
+	Token0     string `bson:"tk0"`
+	Token1     string `bson:"tk1"`
+	Symbol0    string `bson:"tk0sym"`
+	Symbol1    string `bson:"tk1sym"`
+	Decimals0  int32  `bson:"tk0dec"`
+	Decimals1  int32  `bson:"tk1dec"`
+	Block      int64  `bson:"blk"`
+	TimeStamp  int64  `bson:"ts"`
+	Trx        string `bson:"trx"`
+	Backfilled bool   `bson:"bf"`
+	Positions  bool   `bson:"pos"`
+}
+
+// MarshalBSON creates a BSON representation of the Uniswap pair record.
+func (up *UniswapPairInfo) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(BsonUniswapPairInfo{
+		ID:         up.Pair.String(),
+		Index:      int64(up.Index),
+		Token0:     up.Token0.String(),
+		Token1:     up.Token1.String(),
+		Symbol0:    up.Token0Symbol,
+		Symbol1:    up.Token1Symbol,
+		Decimals0:  up.Token0Decimals,
+		Decimals1:  up.Token1Decimals,
+		Block:      int64(up.Block),
+		TimeStamp:  int64(up.TimeStamp),
+		Trx:        up.Trx.String(),
+		Backfilled: up.Backfilled,
+		Positions:  up.Positions,
+	})
+}
+
+// UnmarshalBSON updates the value from BSON source.
+func (up *UniswapPairInfo) UnmarshalBSON(data []byte) (err error) {
+	var row BsonUniswapPairInfo
+	if err = bson.Unmarshal(data, &row); err != nil {
+		return err
+	}
+
+	up.Pair = common.HexToAddress(row.ID)
+	up.Index = uint64(row.Index)
+	up.Token0 = common.HexToAddress(row.Token0)
+	up.Token1 = common.HexToAddress(row.Token1)
+	up.Token0Symbol = row.Symbol0
+	up.Token1Symbol = row.Symbol1
+	up.Token0Decimals = row.Decimals0
+	up.Token1Decimals = row.Decimals1
+	up.Block = hexutil.Uint64(row.Block)
+	up.TimeStamp = hexutil.Uint64(row.TimeStamp)
+	up.Trx = common.HexToHash(row.Trx)
+	up.Backfilled = row.Backfilled
+	up.Positions = row.Positions
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// UniswapPairAnalytics represents pre-calculated statistics of an Uniswap pair.
+// Volumes are denominated in the pair token0, values are in fUSD
+// and changes and rates are percentages.
+type UniswapPairAnalytics struct {
+	// Pair represents the address of the pair.
+	Pair common.Address `json:"pair"`
+
+	// Reserves represents the reserves of the pair tokens.
+	Reserves []hexutil.Big `json:"reserves"`
+
+	// TotalValueLocked represents the value of the pair reserves in fUSD.
+	TotalValueLocked float64 `json:"tvl"`
+
+	// IsInFUSD signals the values could be denominated in fUSD
+	// by the price oracle for at least one of the pair tokens.
+	IsInFUSD bool `json:"inFUSD"`
+
+	// Volume24h represents the swap volume of the last 24 hours.
+	Volume24h hexutil.Big `json:"vol24"`
+
+	// VolumePrevious24h represents the swap volume of the 24 hours before the last 24 hours.
+	VolumePrevious24h hexutil.Big `json:"volPrev24"`
+
+	// VolumeValue24h represents the swap volume of the last 24 hours in fUSD.
+	VolumeValue24h float64 `json:"volVal24"`
+
+	// VolumeChange24h represents the change of the last 24 hours volume against the previous 24 hours.
+	VolumeChange24h float64 `json:"volChange24"`
+
+	// Price represents the current price of the token0 in token1.
+	Price float64 `json:"price"`
+
+	// PriceChange24h represents the change of the token0 price during the last 24 hours.
+	PriceChange24h float64 `json:"priceChange24"`
+
+	// FeeApr represents the annual rate of the liquidity fees
+	// estimated from the last 24 hours volume and the current reserves.
+	FeeApr float64 `json:"feeApr"`
+
+	// TxCount24h represents the number of the pair transactions during the last 24 hours.
+	TxCount24h hexutil.Uint64 `json:"tx24"`
+
+	// TxCount represents the total number of the pair transactions.
+	TxCount hexutil.Uint64 `json:"tx"`
+
+	// Updated represents the time stamp of the statistics calculation.
+	Updated hexutil.Uint64 `json:"updated"`
+}
+
+// UnmarshalUniswapPairAnalytics parses the JSON-encoded Uniswap pair analytics data.
+func UnmarshalUniswapPairAnalytics(data []byte) (*UniswapPairAnalytics, error) {
+	var upa UniswapPairAnalytics
+	err := json.Unmarshal(data, &upa)
+	return &upa, err
+}
+
+// Marshal returns the JSON encoding of Uniswap pair analytics.
+func (upa *UniswapPairAnalytics) Marshal() ([]byte, error) {
+	return json.Marshal(upa)
+}
@@ -0,0 +1,232 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// FiUniswapPositionPk defines primary key column of the liquidity positions table.
+	FiUniswapPositionPk = "_id"
+
+	// FiUniswapPositionOrdinal defines ordinal index column of the liquidity positions table.
+	FiUniswapPositionOrdinal = "orx"
+
+	// FiUniswapPositionPair defines pair address column of the liquidity positions table.
+	FiUniswapPositionPair = "pair"
+
+	// FiUniswapPositionOwner defines owner address column of the liquidity positions table.
+	FiUniswapPositionOwner = "owner"
+
+	// FiUniswapPositionValue defines approximate liquidity value column of the liquidity positions table.
+	FiUniswapPositionValue = "val"
+
+	// FiUniswapPositionApplied defines the last applied pair event column of the liquidity positions table.
+	FiUniswapPositionApplied = "apx"
+)
+
+// UniswapPosition represents a liquidity position of an account on an Uniswap pair.
+// Deposited amounts and the RootK are the cost basis of the liquidity currently held,
+// they are reduced proportionally when the liquidity leaves the position.
+type UniswapPosition struct {
+	// Pair represents the address of the pair.
+	Pair common.Address
+
+	// Owner represents the address of the liquidity provider.
+	Owner common.Address
+
+	// Liquidity represents the amount of the pair liquidity tokens held.
+	Liquidity hexutil.Big
+
+	// Deposited represents the amounts of the pair tokens deposited for the liquidity held.
+	Deposited []hexutil.Big
+
+	// Withdrawn represents the amounts of the pair tokens withdrawn from the position so far.
+	Withdrawn []hexutil.Big
+
+	// RootK represents the square root of the deposited amounts product;
+	// it's used to estimate fees earned by the liquidity held.
+	RootK hexutil.Big
+
+	// Index represents the ordinal index of the event opening the position.
+	Index uint64
+
+	// Opened represents the time stamp of the event opening the position.
+	Opened hexutil.Uint64
+
+	// Updated represents the time stamp of the last change of the position.
+	Updated hexutil.Uint64
+
+	// LastEvent represents the ordinal of the last pair event applied to the position.
+	LastEvent uint64
+}
+
+// BsonUniswapPosition represents the BSON i/o struct for a liquidity position.
+type BsonUniswapPosition struct {
+	ID        string   `bson:"_id"`
+	Pair      string   `bson:"pair"`
+	Owner     string   `bson:"owner"`
+	Liquidity string   `bson:"lq"`
+	Value     float64  `bson:"val"`
+	Deposited []string `bson:"dep"`
+	Withdrawn []string `bson:"wdr"`
+	RootK     string   `bson:"rk"`
+	Orx       uint64   `bson:"orx"`
+	Opened    uint64   `bson:"ots"`
+	Updated   uint64   `bson:"uts"`
+	LastEvent uint64   `bson:"apx"`
+}
+
+// UniswapPositionPk builds the primary key of the liquidity position of the given owner on the given pair.
+func UniswapPositionPk(pair *common.Address, owner *common.Address) string {
+	return pair.String() + owner.String()[2:]
+}
+
+// NewUniswapPosition creates an empty liquidity position of the given owner on the given pair.
+func NewUniswapPosition(pair *common.Address, owner *common.Address) *UniswapPosition {
+	return &UniswapPosition{
+		Pair:      *pair,
+		Owner:     *owner,
+		Deposited: []hexutil.Big{{}, {}},
+		Withdrawn: []hexutil.Big{{}, {}},
+	}
+}
+
+// Pk returns the primary key of the liquidity position.
+func (up *UniswapPosition) Pk() string {
+	return UniswapPositionPk(&up.Pair, &up.Owner)
+}
+
+// UniswapPositionEventOrdinal builds the ordinal of a pair event from its block number
+// and its index within the block; events are applied to positions in this order.
+func UniswapPositionEventOrdinal(block uint64, index uint) uint64 {
+	return (block << 24) | (uint64(index) & 0xFFFFFF)
+}
+
+// Applied checks if the pair event of the given ordinal has already been applied
+// to the liquidity position.
+func (up *UniswapPosition) Applied(ord uint64) bool {
+	return ord <= up.LastEvent
+}
+
+// MarshalBSON creates a BSON representation of the liquidity position record.
+func (up *UniswapPosition) MarshalBSON() ([]byte, error) {
+	val, _ := new(big.Float).SetInt(up.Liquidity.ToInt()).Float64()
+	row := BsonUniswapPosition{
+		ID:        up.Pk(),
+		Pair:      up.Pair.String(),
+		Owner:     up.Owner.String(),
+		Liquidity: up.Liquidity.String(),
+		Value:     val,
+		Deposited: make([]string, len(up.Deposited)),
+		Withdrawn: make([]string, len(up.Withdrawn)),
+		RootK:     up.RootK.String(),
+		Orx:       up.Index,
+		Opened:    uint64(up.Opened),
+		Updated:   uint64(up.Updated),
+		LastEvent: up.LastEvent,
+	}
+
+	for i := range up.Deposited {
+		row.Deposited[i] = up.Deposited[i].String()
+	}
+	for i := range up.Withdrawn {
+		row.Withdrawn[i] = up.Withdrawn[i].String()
+	}
+	return bson.Marshal(row)
+}
+
+// UnmarshalBSON updates the value from BSON source.
+func (up *UniswapPosition) UnmarshalBSON(data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("can not decode BIG number in liquidity position unmarshal")
+		}
+	}()
+
+	// try to decode the BSON data
+	var row BsonUniswapPosition
+	if err = bson.Unmarshal(data, &row); err != nil {
+		return err
+	}
+
+	up.Pair = common.HexToAddress(row.Pair)
+	up.Owner = common.HexToAddress(row.Owner)
+	up.Liquidity = (hexutil.Big)(*hexutil.MustDecodeBig(row.Liquidity))
+	up.RootK = (hexutil.Big)(*hexutil.MustDecodeBig(row.RootK))
+	up.Index = row.Orx
+	up.Opened = hexutil.Uint64(row.Opened)
+	up.Updated = hexutil.Uint64(row.Updated)
+	up.LastEvent = row.LastEvent
+
+	up.Deposited = make([]hexutil.Big, len(row.Deposited))
+	for i, v := range row.Deposited {
+		up.Deposited[i] = (hexutil.Big)(*hexutil.MustDecodeBig(v))
+	}
+	up.Withdrawn = make([]hexutil.Big, len(row.Withdrawn))
+	for i, v := range row.Withdrawn {
+		up.Withdrawn[i] = (hexutil.Big)(*hexutil.MustDecodeBig(v))
+	}
+	return nil
+}
+
+// UniswapPositionValue represents the current value of a liquidity position.
+type UniswapPositionValue struct {
+	// Amounts represents the current amounts of the pair tokens owned by the position.
+	Amounts []hexutil.Big
+
+	// FeesEarned represents the estimated amounts of the pair tokens earned on fees.
+	FeesEarned []hexutil.Big
+
+	// ShareOfPool represents the percentage of the pool owned by the position.
+	ShareOfPool float64
+
+	// ImpermanentLoss represents the percentage of the value lost by providing
+	// the liquidity compared to holding the deposited tokens; fees are not included.
+	ImpermanentLoss float64
+}
+
+// UniswapPositionList represents a list of liquidity positions.
+type UniswapPositionList struct {
+	// List keeps the actual Collection.
+	Collection []*UniswapPosition
+
+	// Total indicates total number of liquidity positions in the whole collection.
+	Total uint64
+
+	// First is the index of the first item on the list
+	First uint64
+
+	// Last is the index of the last item on the list
+	Last uint64
+
+	// IsStart indicates there are no positions available above the list currently.
+	IsStart bool
+
+	// IsEnd indicates there are no positions available below the list currently.
+	IsEnd bool
+
+	// Filter represents the base filter used for filtering the list
+	Filter bson.D
+}
+
+// Reverse reverses the order of liquidity positions in the list.
+func (c *UniswapPositionList) Reverse() {
+	// anything to swap at all?
+	if c.Collection == nil || len(c.Collection) < 2 {
+		return
+	}
+
+	// swap elements
+	for i, j := 0, len(c.Collection)-1; i < j; i, j = i+1, j-1 {
+		c.Collection[i], c.Collection[j] = c.Collection[j], c.Collection[i]
+	}
+
+	// swap indexes
+	c.First, c.Last = c.Last, c.First
+}
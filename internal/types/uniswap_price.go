@@ -0,0 +1,26 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// UniswapPairPrice represents the state of an Uniswap pair reserves
+// after a change processed from the pair Sync event.
+type UniswapPairPrice struct {
+	// PairAddress is the address of the Uniswap pair.
+	PairAddress common.Address
+
+	// BlockNr is the number of the block of the change.
+	BlockNr hexutil.Uint64
+
+	// Time is the time stamp of the block of the change.
+	Time hexutil.Uint64
+
+	// TransactionHash is the hash of the transaction causing the change.
+	TransactionHash common.Hash
+
+	// Reserves are the new reserves of the pair tokens.
+	Reserves []hexutil.Big
+}
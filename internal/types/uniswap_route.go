@@ -0,0 +1,95 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// UniswapRouteHop represents a single swap step of a Uniswap route.
+type UniswapRouteHop struct {
+	// Pair represents the address of the pair used by the step.
+	Pair common.Address
+
+	// TokenIn represents the token sent into the pair.
+	TokenIn common.Address
+
+	// TokenOut represents the token received from the pair.
+	TokenOut common.Address
+
+	// AmountIn represents the amount of tokens sent into the pair.
+	AmountIn hexutil.Big
+
+	// AmountOut represents the amount of tokens received from the pair.
+	AmountOut hexutil.Big
+
+	// MidPrice represents the price of the input token in output tokens
+	// given by the pair reserves before the swap.
+	MidPrice float64
+
+	// PriceImpact represents the percentage of the price change caused by the step,
+	// the LP fee is not included.
+	PriceImpact float64
+
+	// LpFee represents the amount of input tokens paid to the liquidity providers of the pair.
+	LpFee hexutil.Big
+}
+
+// UniswapRoute represents a path of swaps between two tokens across Uniswap pairs.
+type UniswapRoute struct {
+	// Hops represents the swap steps of the route.
+	Hops []UniswapRouteHop
+
+	// AmountIn represents the amount of tokens sent into the route.
+	AmountIn hexutil.Big
+
+	// AmountOut represents the amount of tokens received from the route.
+	AmountOut hexutil.Big
+
+	// MidPrice represents the price of the input token in output tokens
+	// given by the reserves of all the pairs on the route.
+	MidPrice float64
+
+	// ExecutionPrice represents the price actually paid on the route.
+	ExecutionPrice float64
+
+	// PriceImpact represents the percentage of the price change caused by the route,
+	// the LP fee is not included.
+	PriceImpact float64
+
+	// LpFeePercent represents the percentage of the input paid to liquidity providers along the route.
+	LpFeePercent float64
+
+	// Slippage represents the slippage tolerance in percent used to calculate the limits below.
+	Slippage float64
+
+	// MinimumReceived represents the lowest output amount acceptable for the slippage tolerance.
+	MinimumReceived hexutil.Big
+
+	// MaximumSold represents the highest input amount acceptable for the slippage tolerance.
+	MaximumSold hexutil.Big
+}
+
+// UniswapLiquidityQuote represents a preview of adding liquidity to, or removing liquidity from a Uniswap pair.
+type UniswapLiquidityQuote struct {
+	// Pair represents the address of the pair.
+	Pair common.Address
+
+	// Amounts represents the amounts of the pair tokens added, or removed.
+	Amounts []hexutil.Big
+
+	// MinimumAmounts represents the lowest amounts of the pair tokens acceptable for the slippage tolerance.
+	MinimumAmounts []hexutil.Big
+
+	// Liquidity represents the amount of the pair liquidity tokens minted, or burned.
+	Liquidity hexutil.Big
+
+	// ShareOfPool represents the percentage of the pool owned by the liquidity tokens.
+	ShareOfPool float64
+
+	// Prices represents the price of each token of the pair in the sibling token.
+	Prices []float64
+
+	// Slippage represents the slippage tolerance in percent used to calculate the minimal amounts.
+	Slippage float64
+}
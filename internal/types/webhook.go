@@ -0,0 +1,182 @@
+// Package types implements different core types of the API.
+package types
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// webhook filter types
+const (
+	WebhookFilterAccountActivity = "ACCOUNT_ACTIVITY"
+	WebhookFilterTokenTransfer   = "TOKEN_TRANSFER"
+	WebhookFilterLogs            = "LOGS"
+	WebhookFilterEpoch           = "EPOCH"
+)
+
+// webhook delivery states
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryDead      = "DEAD"
+)
+
+const (
+	// FiWebhookDeliveryHook defines the webhook column of the webhook deliveries table.
+	FiWebhookDeliveryHook = "hook"
+
+	// FiWebhookDeliveryStatus defines the status column of the webhook deliveries table.
+	FiWebhookDeliveryStatus = "status"
+
+	// FiWebhookDeliveryCreated defines the creation time column of the webhook deliveries table.
+	FiWebhookDeliveryCreated = "created"
+
+	// FiWebhookDeliveryNext defines the next attempt time column of the webhook deliveries table.
+	FiWebhookDeliveryNext = "next"
+
+	// FiWebhookDeliveryLast defines the last attempt time column of the webhook deliveries table.
+	FiWebhookDeliveryLast = "last"
+)
+
+// WebhookFilter represents the set of events a webhook is notified about.
+type WebhookFilter struct {
+	// Type represents the type of the events.
+	Type string
+
+	// Address represents the account of the activity, or the contract emitting logs.
+	Address *common.Address
+
+	// Token represents the token contract of the transfers.
+	Token *common.Address
+
+	// TokenType represents the type of the token of the transfers (ERC20/ERC721/ERC1155).
+	TokenType *string
+
+	// Topics represents the list of accepted log event topics; any of them is accepted.
+	Topics []common.Hash
+}
+
+// Webhook represents a registered webhook receiving events matching its filter.
+type Webhook struct {
+	// Id represents the identifier of the webhook.
+	Id string
+
+	// Url represents the target URL the events are delivered to.
+	Url string
+
+	// Secret represents the key used to sign the deliveries.
+	Secret string
+
+	// Filter represents the events the webhook is notified about.
+	Filter WebhookFilter
+
+	// Created represents the time of the webhook registration.
+	Created time.Time
+}
+
+// BsonWebhook represents the BSON i/o struct for a webhook.
+type BsonWebhook struct {
+	ID        string    `bson:"_id"`
+	Url       string    `bson:"url"`
+	Secret    string    `bson:"secret"`
+	Type      string    `bson:"type"`
+	Address   string    `bson:"adr,omitempty"`
+	Token     string    `bson:"tok,omitempty"`
+	TokenType string    `bson:"tty,omitempty"`
+	Topics    []string  `bson:"topics,omitempty"`
+	Created   time.Time `bson:"created"`
+}
+
+// MarshalBSON creates a BSON representation of the webhook record.
+func (wh *Webhook) MarshalBSON() ([]byte, error) {
+	row := BsonWebhook{
+		ID:      wh.Id,
+		Url:     wh.Url,
+		Secret:  wh.Secret,
+		Type:    wh.Filter.Type,
+		Created: wh.Created,
+	}
+
+	if wh.Filter.Address != nil {
+		row.Address = wh.Filter.Address.String()
+	}
+	if wh.Filter.Token != nil {
+		row.Token = wh.Filter.Token.String()
+	}
+	if wh.Filter.TokenType != nil {
+		row.TokenType = *wh.Filter.TokenType
+	}
+	for _, t := range wh.Filter.Topics {
+		row.Topics = append(row.Topics, t.String())
+	}
+	return bson.Marshal(row)
+}
+
+// UnmarshalBSON updates the value from BSON source.
+func (wh *Webhook) UnmarshalBSON(data []byte) error {
+	var row BsonWebhook
+	if err := bson.Unmarshal(data, &row); err != nil {
+		return err
+	}
+
+	wh.Id = row.ID
+	wh.Url = row.Url
+	wh.Secret = row.Secret
+	wh.Created = row.Created
+	wh.Filter = WebhookFilter{Type: row.Type}
+
+	if row.Address != "" {
+		adr := common.HexToAddress(row.Address)
+		wh.Filter.Address = &adr
+	}
+	if row.Token != "" {
+		adr := common.HexToAddress(row.Token)
+		wh.Filter.Token = &adr
+	}
+	if row.TokenType != "" {
+		tt := row.TokenType
+		wh.Filter.TokenType = &tt
+	}
+	for _, t := range row.Topics {
+		wh.Filter.Topics = append(wh.Filter.Topics, common.HexToHash(t))
+	}
+	return nil
+}
+
+// WebhookDelivery represents a single delivery of an event to a webhook.
+type WebhookDelivery struct {
+	// Id represents the identifier of the delivery.
+	Id string `bson:"_id"`
+
+	// Hook represents the identifier of the target webhook.
+	Hook string `bson:"hook"`
+
+	// Event represents the type of the delivered event.
+	Event string `bson:"event"`
+
+	// Payload represents the JSON encoded body of the delivery.
+	Payload string `bson:"payload"`
+
+	// Status represents the state of the delivery.
+	Status string `bson:"status"`
+
+	// Attempts represents the number of delivery attempts made so far.
+	Attempts int32 `bson:"attempts"`
+
+	// ResponseCode represents the HTTP status code of the last attempt, zero if no response was received.
+	ResponseCode int32 `bson:"code"`
+
+	// LastError represents the failure of the last attempt, if any.
+	LastError string `bson:"err,omitempty"`
+
+	// Created represents the time the delivery was created.
+	Created time.Time `bson:"created"`
+
+	// LastAttempt represents the time of the last delivery attempt.
+	LastAttempt time.Time `bson:"last"`
+
+	// NextAttempt represents the time of the next delivery attempt of a pending delivery.
+	NextAttempt time.Time `bson:"next"`
+}